@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache keeps values by key for up to a ttl. Delete and Clear take effect
+// on every instance sharing the cache.
+type Cache interface {
+	// Get returns the value of key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// Clear deletes every key.
+	Clear(ctx context.Context) error
+}
+
+// Setup connects to redis at addr, without an addr the cache is kept in
+// memory and only fits a single instance.
+func Setup(ctx context.Context, addr, password string, db int) Cache {
+	if addr == "" {
+		return NewMemory()
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+	c, err := NewRedis(ctx, client, "go-admin:cache:")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return c
+}
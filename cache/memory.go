@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is a Cache in the memory of the process.
+type Memory struct {
+	mu    sync.Mutex
+	entry map[string]entry
+}
+
+type entry struct {
+	value   []byte
+	expired time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{entry: make(map[string]entry)}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entry[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !time.Now().Before(e.expired) {
+		delete(m.entry, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entry[key] = entry{value: value, expired: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *Memory) Delete(_ context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entry, key)
+	}
+	return nil
+}
+
+func (m *Memory) Clear(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entry = make(map[string]entry)
+	return nil
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Cache shared by the instances through redis. Each instance
+// keeps a copy of what it read in memory, deletes are published so that
+// every instance drops its copy as well.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	local  *Memory
+	// channel carries the deleted keys to every instance, an empty list
+	// stands for Clear
+	channel string
+}
+
+// NewRedis subscribes to the invalidations of the other instances until
+// ctx is done, keys are stored under prefix.
+func NewRedis(ctx context.Context, client *redis.Client, prefix string) (*Redis, error) {
+	r := &Redis{
+		client:  client,
+		prefix:  prefix,
+		local:   NewMemory(),
+		channel: prefix + "invalidate",
+	}
+
+	pubsub := client.Subscribe(ctx, r.channel)
+	// wait for the subscription, so that no delete is missed from here on
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go r.receive(ctx, pubsub)
+	return r, nil
+}
+
+func (r *Redis) receive(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	messageChannel := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messageChannel:
+			if !ok {
+				return
+			}
+			var keys []string
+			err := json.Unmarshal([]byte(message.Payload), &keys)
+			if err != nil {
+				slog.Error("cache invalidate", "err", err)
+				continue
+			}
+			if len(keys) == 0 {
+				_ = r.local.Clear(ctx)
+				continue
+			}
+			_ = r.local.Delete(ctx, keys...)
+		}
+	}
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, _ := r.local.Get(ctx, key)
+	if ok {
+		return value, true, nil
+	}
+
+	// the copy lives no longer than the value in redis
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, r.prefix+key)
+		pttl = pipe.PTTL(ctx, r.prefix+key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err = get.Bytes()
+	if err != nil {
+		return nil, false, err
+	}
+	if ttl := pttl.Val(); ttl > 0 {
+		_ = r.local.Set(ctx, key, value, ttl)
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+	if err != nil {
+		return err
+	}
+	return r.local.Set(ctx, key, value, ttl)
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+	err := r.client.Del(ctx, prefixed...).Err()
+	if err != nil {
+		return err
+	}
+	_ = r.local.Delete(ctx, keys...)
+	return r.publish(ctx, keys)
+}
+
+func (r *Redis) Clear(ctx context.Context) error {
+	iter := r.client.Scan(ctx, 0, r.prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		err := r.client.Del(ctx, iter.Val()).Err()
+		if err != nil {
+			return err
+		}
+	}
+	err := iter.Err()
+	if err != nil {
+		return err
+	}
+	_ = r.local.Clear(ctx)
+	return r.publish(ctx, []string{})
+}
+
+func (r *Redis) publish(ctx context.Context, keys []string) error {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	return r.client.Publish(ctx, r.channel, payload).Err()
+}
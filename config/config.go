@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/knadh/koanf/parsers/dotenv"
@@ -22,3 +23,15 @@ func Setup() {
 		log.Fatalf("error reading env: %v", err)
 	}
 }
+
+// DSN builds the postgres connection string including the pool settings.
+func DSN() string {
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s "+
+		"pool_max_conns=%s pool_min_conns=%s pool_max_conn_lifetime=%s pool_max_conn_idle_time=%s "+
+		"pool_health_check_period=%s",
+		Raw.String("HOST"), Raw.String("POSTGRES_USER"), Raw.String("POSTGRES_PASSWORD"),
+		Raw.String("POSTGRES_DB"), Raw.String("PORT"), Raw.String("SSL_MODE"),
+		Raw.String("TIMEZONE"), Raw.String("POOL_MAX_CONNS"), Raw.String("POOL_MIN_CONNS"),
+		Raw.String("POOL_MAX_CONN_LIFETIME"), Raw.String("POOL_MAX_CONN_IDLE_TIME"),
+		Raw.String("POOL_HEALTH_CHECK_PERIOD"))
+}
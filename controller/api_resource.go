@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+func (a *API) GetApiV1ApiResources(w http.ResponseWriter, r *http.Request, params GetApiV1ApiResourcesParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	apiResourceList, err := query.ListApiResource(ctx, params.Tag)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var respList []ApiResource
+	for _, apiResource := range apiResourceList {
+		respList = append(respList, apiResourceResp(apiResource))
+	}
+
+	encode(w, respList)
+}
+
+func (a *API) PostApiV1ApiResourcesSync(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	resp, err := a.syncApiResource(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, resp)
+}
+
+// syncApiResource writes the routes of the spec to the catalog and drops the
+// ones that are gone, then reports the routes no resource of a menu matches
+// and the resources no route matches.
+func (a *API) syncApiResource(ctx context.Context) (ApiResourceSync, error) {
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		return ApiResourceSync{}, err
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	var resp ApiResourceSync
+	resp.Ungranted = []ApiResource{}
+	resp.Stale = []Resource{}
+
+	resourceList, err := query.ListResource(ctx)
+	if err != nil {
+		return ApiResourceSync{}, err
+	}
+
+	for _, route := range routeList {
+		var params model.UpsertApiResourceParams
+		params.Method = route.Method
+		params.Path = route.Pattern
+		params.OperationID = route.OperationID
+		params.Tag = route.Tag
+		params.Description = route.Description
+		params.Created = now
+		apiResource, err := query.UpsertApiResource(ctx, params)
+		if err != nil {
+			return ApiResourceSync{}, err
+		}
+		// created and updated only differ for a route synced before
+		if apiResource.Created.Time.Equal(apiResource.Updated.Time) {
+			resp.Added++
+		}
+
+		if !granted(resourceList, route) {
+			resp.Ungranted = append(resp.Ungranted, apiResourceResp(apiResource))
+		}
+	}
+
+	removed, err := query.DeleteApiResourceBefore(ctx, now)
+	if err != nil {
+		return ApiResourceSync{}, err
+	}
+	resp.Removed = int32(removed)
+
+	for _, resource := range resourceList {
+		methodList, anyMethod, methodErr := parseMethods(resource.Method)
+		segments, pathErr := parsePattern(resource.Path)
+		if methodErr != nil || pathErr != nil || !routed(methodList, anyMethod, segments) {
+			resp.Stale = append(resp.Stale, resourceResp(resource))
+		}
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		return ApiResourceSync{}, err
+	}
+	return resp, nil
+}
+
+// granted reports whether a resource of a menu matches the route.
+func granted(resourceList []model.Resource, route route) bool {
+	routeSegments, err := parsePattern(route.Pattern)
+	if err != nil {
+		return false
+	}
+	for _, resource := range resourceList {
+		methodList, anyMethod, err := parseMethods(resource.Method)
+		if err != nil || (!anyMethod && !slices.Contains(methodList, route.Method)) {
+			continue
+		}
+		segments, err := parsePattern(resource.Path)
+		if err == nil && overlap(segments, routeSegments) {
+			return true
+		}
+	}
+	return false
+}
+
+func apiResourceResp(m model.ApiResource) ApiResource {
+	var resp ApiResource
+	resp.Id = m.ID
+	resp.Method = m.Method
+	resp.Path = m.Path
+	resp.OperationId = m.OperationID
+	resp.Tag = m.Tag
+	resp.Description = m.Description
+	resp.Created = m.Created.Time.Format(pgTimestampFormat)
+	resp.Updated = m.Updated.Time.Format(pgTimestampFormat)
+	return resp
+}
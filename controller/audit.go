@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+const (
+	auditUser = "user"
+	auditRole = "role"
+	auditMenu = "menu"
+)
+
+func (a *API) GetApiV1AuditLogs(w http.ResponseWriter, r *http.Request, params GetApiV1AuditLogsParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	err := validate.Struct(params)
+	if err != nil {
+		validateErr(w, err)
+		return
+	}
+
+	var modelParams model.ListAuditLogParams
+	modelParams.ActorID = pgtype.Int4{Int32: params.ActorId, Valid: params.ActorId != 0}
+	modelParams.Entity = params.Entity
+	modelParams.EntityID = pgtype.Int4{Int32: params.EntityId, Valid: params.EntityId != 0}
+	if params.Start != "" {
+		err = modelParams.Start.Scan(params.Start)
+		if err != nil {
+			Err(w, errcode.Convert)
+			return
+		}
+	}
+	if params.End != "" {
+		err = modelParams.End.Scan(params.End)
+		if err != nil {
+			Err(w, errcode.Convert)
+			return
+		}
+	}
+	modelParams.Offset, modelParams.Limit = paging(params.Current, params.PageSize)
+
+	query := model.New(a.DB)
+
+	auditLogList, err := query.ListAuditLog(ctx, modelParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var respList []AuditLog
+	for _, auditLog := range auditLogList {
+		respList = append(respList, auditLogResp(auditLog))
+	}
+
+	encode(w, respList)
+}
+
+// writeAudit records who changed the entity in the transaction of the change,
+// before and after are its responses and only the fields that differ are kept.
+func writeAudit(r *http.Request, query *model.Queries, action AuditLogAction, entity string, entityID int32, before, after any) error {
+	params, err := auditParams(r, action, entity, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	_, err = query.CreateAuditLog(r.Context(), params)
+	return err
+}
+
+func auditParams(r *http.Request, action AuditLogAction, entity string, entityID int32, before, after any) (model.CreateAuditLogParams, error) {
+	ctx := r.Context()
+
+	beforeJSON, afterJSON, err := auditDiff(before, after)
+	if err != nil {
+		return model.CreateAuditLogParams{}, err
+	}
+
+	var params model.CreateAuditLogParams
+	actorID, ok := currentUserID(ctx)
+	params.ActorID = pgtype.Int4{Int32: actorID, Valid: ok}
+	params.Action = string(action)
+	params.Entity = entity
+	params.EntityID = entityID
+	params.Before = beforeJSON
+	params.After = afterJSON
+	params.Ip = clientIP(r)
+	params.RequestID, _ = ctx.Value(requestIDKey).(string)
+	params.Created = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	return params, nil
+}
+
+func auditDiff(before, after any) ([]byte, []byte, error) {
+	beforeMap, err := auditMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterMap, err := auditMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeMap != nil && afterMap != nil {
+		for field, value := range beforeMap {
+			if reflect.DeepEqual(value, afterMap[field]) {
+				delete(beforeMap, field)
+				delete(afterMap, field)
+			}
+		}
+	}
+
+	var beforeJSON, afterJSON []byte
+	if beforeMap != nil {
+		beforeJSON, err = json.Marshal(beforeMap)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if afterMap != nil {
+		afterJSON, err = json.Marshal(afterMap)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func auditMap(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	m, err := toMap(v)
+	if err != nil {
+		return nil, err
+	}
+	// the password is hashed and never part of a response
+	delete(m, "password")
+	return m, nil
+}
+
+const defaultTrustProxyHops = 1
+
+// trustProxyHops is the number of proxies in front of the API, each of them
+// appends the address it got the request from to X-Forwarded-For.
+func trustProxyHops() int {
+	hops := config.Raw.Int("TRUST_PROXY_HOPS")
+	if hops <= 0 {
+		return defaultTrustProxyHops
+	}
+	return hops
+}
+
+// clientIP takes the X-Forwarded-For address the outermost trusted proxy
+// appended when TRUST_PROXY is set. The entries left of it come from the
+// client, which can write anything there.
+func clientIP(r *http.Request) string {
+	if config.Raw.Bool("TRUST_PROXY") {
+		var forwardedList []string
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			forwardedList = append(forwardedList, strings.Split(value, ",")...)
+		}
+		hops := trustProxyHops()
+		if len(forwardedList) >= hops {
+			forwarded := strings.TrimSpace(forwardedList[len(forwardedList)-hops])
+			if forwarded != "" {
+				return forwarded
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func auditLogResp(m model.AuditLog) AuditLog {
+	var resp AuditLog
+	resp.Id = m.ID
+	resp.ActorId = m.ActorID.Int32
+	resp.Action = AuditLogAction(m.Action)
+	resp.Entity = m.Entity
+	resp.EntityId = m.EntityID
+	if m.Before != nil {
+		_ = json.Unmarshal(m.Before, &resp.Before)
+	}
+	if m.After != nil {
+		_ = json.Unmarshal(m.After, &resp.After)
+	}
+	resp.Ip = m.Ip
+	resp.RequestId = m.RequestID
+	resp.Created = m.Created.Time.Format(pgTimestampFormat)
+	return resp
+}
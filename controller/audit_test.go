@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:4321"
+	req.Header.Add("X-Forwarded-For", "6.6.6.6, 1.2.3.4")
+	req.Header.Add("X-Forwarded-For", "10.0.0.2")
+
+	_ = config.Raw.Set("TRUST_PROXY", false)
+	assert.Equal(t, "10.0.0.1", clientIP(req))
+
+	// the client wrote 6.6.6.6, the proxies appended the rest
+	_ = config.Raw.Set("TRUST_PROXY", true)
+	_ = config.Raw.Set("TRUST_PROXY_HOPS", 1)
+	assert.Equal(t, "10.0.0.2", clientIP(req))
+	_ = config.Raw.Set("TRUST_PROXY_HOPS", 2)
+	assert.Equal(t, "1.2.3.4", clientIP(req))
+
+	// fewer entries than proxies, the header did not pass through all of them
+	_ = config.Raw.Set("TRUST_PROXY_HOPS", 4)
+	assert.Equal(t, "10.0.0.1", clientIP(req))
+
+	_ = config.Raw.Set("TRUST_PROXY", false)
+	_ = config.Raw.Set("TRUST_PROXY_HOPS", 0)
+}
@@ -0,0 +1,339 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func (a *API) PostApiV1AuthLogin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req Login
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	// the attempts are recorded as they happen, not in one transaction,
+	// so that a failure is kept along with the error it answers with
+	query := model.New(a.DB)
+
+	ipUntil, err := lockedUntil(ctx, query, attemptIP, clientIP(r), now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !ipUntil.IsZero() {
+		err = writeLoginLog(r, query, pgtype.Int4{}, req.Username, LoginFailure, reasonIPLocked, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		loginLocked(w, ipUntil, now)
+		return
+	}
+
+	user, err := query.GetUserByUsername(ctx, req.Username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = failLogin(r, query, pgtype.Int4{}, req.Username, reasonUserNotExist, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		Err(w, errcode.UsernameOrPasswordIncorrect)
+		return
+	}
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+
+	// a locked out user is refused before the password is compared,
+	// so that guessing on gets no answer
+	userUntil, err := lockedUntil(ctx, query, attemptUser, strconv.Itoa(int(user.ID)), now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !userUntil.IsZero() {
+		err = writeLoginLog(r, query, userID, req.Username, LoginFailure, reasonUserLocked, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		loginLocked(w, userUntil, now)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	if err != nil {
+		err = failLogin(r, query, userID, req.Username, reasonPasswordIncorrect, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		Err(w, errcode.UsernameOrPasswordIncorrect)
+		return
+	}
+
+	if UserStatus(user.Status) == Frozen {
+		err = writeLoginLog(r, query, userID, req.Username, LoginFailure, reasonUserFrozen, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		Err(w, errcode.UserFrozen)
+		return
+	}
+
+	// the hash follows BCRYPT_COST once the password is known again
+	if needsRehash(user.Password) {
+		password, err := hash(req.Password)
+		if err != nil {
+			Err(w, errcode.Convert)
+			return
+		}
+		err = query.RehashUserPassword(ctx, model.RehashUserPasswordParams{ID: user.ID, Password: password})
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+	}
+
+	if user.TotpEnabled {
+		challenge, err := newLoginChallenge(ctx, query, user.ID, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		encode(w, Token{Challenge: challenge})
+		return
+	}
+
+	completeLogin(w, r, query, user, now)
+}
+
+// completeLogin clears the failed logins of the user, logs the login and
+// answers with the tokens.
+func completeLogin(w http.ResponseWriter, r *http.Request, query *model.Queries, user model.AppUser, now time.Time) {
+	ctx := r.Context()
+
+	deleteParams := model.DeleteLoginAttemptParams{
+		Scope: attemptUser,
+		Key:   strconv.Itoa(int(user.ID)),
+	}
+	_, err := query.DeleteLoginAttempt(ctx, deleteParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	resp, err := startSession(r, query, user.ID, now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	resp.MustChangePassword = user.MustChangePassword
+
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+	err = writeLoginLog(r, query, userID, user.Username, LoginSuccess, "", now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1AuthRefresh(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req RefreshToken
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	refreshToken, err := query.GetRefreshTokenByToken(ctx, hashToken(req.RefreshToken))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RefreshTokenInvalid)
+		return
+	}
+
+	now := time.Now()
+
+	// a rotated token being replayed means it has leaked,
+	// so every session of the user is ended
+	if refreshToken.Revoked {
+		err = query.DeleteSessionByUserID(ctx, refreshToken.UserID)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
+		err = transaction.Commit(ctx)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
+		Err(w, errcode.RefreshTokenInvalid)
+		return
+	}
+
+	if now.After(refreshToken.Expired.Time) {
+		Err(w, errcode.RefreshTokenInvalid)
+		return
+	}
+
+	revokeParams := model.RevokeRefreshTokenParams{
+		ID:      refreshToken.ID,
+		Updated: pgtype.Timestamp{Time: now, Valid: true},
+	}
+	rows, err := query.RevokeRefreshToken(ctx, revokeParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	// rotated by a concurrent request
+	if rows == 0 {
+		Err(w, errcode.RefreshTokenInvalid)
+		return
+	}
+
+	user, err := query.GetUser(ctx, refreshToken.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+	if UserStatus(user.Status) == Frozen {
+		Err(w, errcode.UserFrozen)
+		return
+	}
+
+	resp, err := issueToken(ctx, query, user.ID, refreshToken.SessionID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	resp.MustChangePassword = user.MustChangePassword
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1AuthLogout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req RefreshToken
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	query := model.New(a.DB)
+
+	refreshToken, err := query.GetRefreshTokenByToken(ctx, hashToken(req.RefreshToken))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RefreshTokenInvalid)
+		return
+	}
+
+	// its refresh tokens go with the session
+	_, err = query.DeleteSession(ctx, refreshToken.SessionID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+}
+
+// issueToken signs an access token and stores a new refresh token for the
+// session of the user, which lasts as long as the refresh token then.
+func issueToken(ctx context.Context, query *model.Queries, userID, sessionID int32) (Token, error) {
+	now := time.Now()
+
+	accessToken, err := newAccessToken(userID, sessionID, now)
+	if err != nil {
+		return Token{}, err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return Token{}, err
+	}
+
+	var params model.CreateRefreshTokenParams
+	params.UserID = userID
+	params.SessionID = sessionID
+	params.Token = hashToken(refreshToken)
+	params.Expired = pgtype.Timestamp{Time: now.Add(refreshTokenExpire()), Valid: true}
+	params.Created = pgtype.Timestamp{Time: now, Valid: true}
+	params.Updated = pgtype.Timestamp{Time: now, Valid: true}
+	_, err = query.CreateRefreshToken(ctx, params)
+	if err != nil {
+		return Token{}, err
+	}
+
+	renewParams := model.RenewSessionParams{
+		ID:       sessionID,
+		LastSeen: params.Created,
+		Expired:  params.Expired,
+	}
+	err = query.RenewSession(ctx, renewParams)
+	if err != nil {
+		return Token{}, err
+	}
+
+	var resp Token
+	resp.AccessToken = accessToken
+	resp.RefreshToken = refreshToken
+	resp.TokenType = tokenType
+	resp.ExpiresIn = int32(accessTokenExpire().Seconds())
+	return resp, nil
+}
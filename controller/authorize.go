@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+type contextKey int
+
+const (
+	userIDKey contextKey = iota
+	sessionIDKey
+	requestIDKey
+)
+
+// publicOperation lists the routes reachable without an access token.
+var publicOperation = map[string]bool{
+	"POST /api/v1/auth/login":   true,
+	"POST /api/v1/auth/refresh": true,
+	"POST /api/v1/auth/logout":  true,
+	"POST /api/v1/auth/2fa":     true,
+}
+
+// selfOperation lists the routes every authenticated user may call.
+var selfOperation = map[string]bool{
+	"GET /api/v1/me/menus":        true,
+	"POST /api/v1/me/password":    true,
+	"POST /api/v1/me/2fa/enroll":  true,
+	"POST /api/v1/me/2fa/verify":  true,
+	"POST /api/v1/me/2fa/disable": true,
+}
+
+// passwordChangeOperation lists the routes left open to a user who has to
+// change the password first.
+var passwordChangeOperation = map[string]bool{
+	"POST /api/v1/me/password": true,
+}
+
+// twoFactorSetupOperation lists the routes left open to a user whose role
+// requires 2fa before it is enabled.
+var twoFactorSetupOperation = map[string]bool{
+	"POST /api/v1/me/2fa/enroll": true,
+	"POST /api/v1/me/2fa/verify": true,
+}
+
+// Authorize authenticates the bearer token and allows the request only when
+// an enabled role of the user has an enabled menu whose resource matches the
+// request method and path. The root user is allowed everything.
+func (a *API) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicOperation[r.Method+" "+r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+
+		accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), tokenType+" ")
+		if !ok {
+			Err(w, errcode.TokenInvalid)
+			return
+		}
+
+		userID, sessionID, err := parseAccessToken(accessToken)
+		if err != nil {
+			Err(w, errcode.TokenInvalid)
+			return
+		}
+
+		query := model.New(a.DB)
+
+		// the token is refused as soon as its session is logged out
+		ok, err = checkSession(ctx, query, userID, sessionID)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		if !ok {
+			Err(w, errcode.TokenInvalid)
+			return
+		}
+
+		user, err := query.GetUser(ctx, userID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.Database)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.TokenInvalid)
+			return
+		}
+		if UserStatus(user.Status) == Frozen {
+			Err(w, errcode.UserFrozen)
+			return
+		}
+		if user.MustChangePassword && !passwordChangeOperation[r.Method+" "+r.URL.Path] {
+			Err(w, errcode.PasswordChangeRequired)
+			return
+		}
+		// a user held to both changes the password first, then sets up 2fa
+		if !user.TotpEnabled && !twoFactorSetupOperation[r.Method+" "+r.URL.Path] && !passwordChangeOperation[r.Method+" "+r.URL.Path] {
+			required, err := query.CheckUserRequire2FA(ctx, user.ID)
+			if err != nil {
+				Err(w, errcode.Database)
+				return
+			}
+			if required {
+				Err(w, errcode.TwoFactorRequired)
+				return
+			}
+		}
+
+		if !isRoot(user) && !selfOperation[r.Method+" "+r.URL.Path] {
+			m, err := a.permissionMatcher(ctx, query, user.ID)
+			if err != nil {
+				Err(w, errcode.Database)
+				return
+			}
+			if !m.match(r.Method, r.URL.Path) {
+				Err(w, errcode.PermissionDenied)
+				return
+			}
+		}
+
+		ctx = context.WithValue(ctx, userIDKey, user.ID)
+		ctx = context.WithValue(ctx, sessionIDKey, sessionID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func currentUserID(ctx context.Context) (int32, bool) {
+	userID, ok := ctx.Value(userIDKey).(int32)
+	return userID, ok
+}
+
+func currentSessionID(ctx context.Context) (int32, bool) {
+	sessionID, ok := ctx.Value(sessionIDKey).(int32)
+	return sessionID, ok
+}
+
+// setupRoot creates the root user on first start so that the API can be
+// bootstrapped. Once there is one it is left untouched, it cannot be deleted
+// or renamed, so ROOT_USERNAME is only read until then.
+func (a *API) setupRoot(ctx context.Context) {
+	query := model.New(a.DB)
+
+	_, err := query.GetRootUser(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Fatal(err)
+	}
+	if err == nil {
+		return
+	}
+
+	username := config.Raw.String("ROOT_USERNAME")
+	if username == "" {
+		return
+	}
+
+	// a root user created before it was flagged
+	user, err := query.GetUserByUsername(ctx, username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Fatal(err)
+	}
+	if err == nil {
+		err = query.MarkRootUser(ctx, user.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var root User
+	root.Username = username
+	root.Password = config.Raw.String("ROOT_PASSWORD")
+	root.Name = username
+	params, err := createUserParams(root)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// ROOT_PASSWORD is a bootstrap secret, not one to keep
+	params.MustChangePassword = true
+
+	user, err = query.CreateUser(ctx, params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = query.MarkRootUser(ctx, user.ID)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func isRoot(user model.AppUser) bool {
+	return user.IsRoot
+}
+
+// rootProtected writes RootUserProtected and reports true when the user is
+// root, which is never deleted or renamed.
+func rootProtected(ctx context.Context, w http.ResponseWriter, query *model.Queries, id int32) bool {
+	root, err := query.CheckRootUserByID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return true
+	}
+	if root {
+		Err(w, errcode.RootUserProtected)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,311 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+const defaultMaxBatchSize = 1000
+
+// batchEntity holds what a batch runs its operations with. Single operations
+// go through the handlers, so they behave exactly like their own requests.
+type batchEntity struct {
+	create func(a *API, w http.ResponseWriter, r *http.Request)
+	update func(a *API, w http.ResponseWriter, r *http.Request, id int32)
+	patch  func(a *API, w http.ResponseWriter, r *http.Request, id int32)
+	delete func(a *API, w http.ResponseWriter, r *http.Request, id int32)
+	// createList inserts a run of creates in a few round trips. It fails as
+	// a whole, the run is then created one by one to find the failing item.
+	createList func(r *http.Request, query *model.Queries, bodyList [][]byte) ([]BatchResult, error)
+}
+
+func maxBatchSize() int {
+	size := config.Raw.Int("MAX_BATCH_SIZE")
+	if size <= 0 {
+		return defaultMaxBatchSize
+	}
+	return size
+}
+
+// batch runs the operations in order in one transaction. In atomic mode the
+// first failure rolls back everything, in best effort mode a failed
+// operation is rolled back on its own and the rest are committed.
+func (a *API) batch(w http.ResponseWriter, r *http.Request, entity batchEntity) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req BatchRequest
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+	if len(req.Operations) > maxBatchSize() {
+		Err(w, errcode.BatchTooLarge)
+		return
+	}
+
+	m, err := a.batchMatcher(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	collection := strings.TrimSuffix(r.URL.Path, ":batch")
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	operationList := req.Operations
+	resultList := make([]BatchResult, len(operationList))
+	for i := 0; i < len(operationList); {
+		end := i + 1
+		if operationList[i].Op == BatchCreate && batchPermit(m, collection, operationList[i]) {
+			for end < len(operationList) && operationList[end].Op == BatchCreate {
+				end++
+			}
+			if batchCreateList(r, transaction, entity, operationList[i:end], resultList[i:end]) {
+				i = end
+				continue
+			}
+		}
+
+		for ; i < end; i++ {
+			if batchPermit(m, collection, operationList[i]) {
+				resultList[i] = a.batchOperation(w, r, transaction, entity, operationList[i])
+			} else {
+				resultList[i] = BatchResult{
+					Status:  int32(errcode.Status(errcode.PermissionDenied)),
+					Id:      operationList[i].Id,
+					Code:    errcode.PermissionDenied,
+					Message: errcode.Msg(errcode.PermissionDenied),
+				}
+			}
+			if resultList[i].Code != 0 && req.Mode != BestEffort {
+				encode(w, BatchResponse{Committed: false, Results: abortBatch(resultList, i)})
+				return
+			}
+		}
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	// the operations invalidated before the batch was committed, a request
+	// in between may have cached what was there before
+	a.invalidatePermission(ctx)
+
+	encode(w, BatchResponse{Committed: true, Results: resultList})
+}
+
+// batchMatcher returns the permissions of the caller, as Authorize only
+// checked the batch route and not the operations in it. It is nil when
+// every operation is allowed, for root and when the batch is served
+// without Authorize in front, so that there is no caller.
+func (a *API) batchMatcher(ctx context.Context) (*matcher, error) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	query := model.New(a.DB)
+
+	user, err := query.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isRoot(user) {
+		return nil, nil
+	}
+	return a.permissionMatcher(ctx, query, userID)
+}
+
+// batchPermit reports whether the operation is allowed as the request of its
+// own, such as DELETE /api/v1/users/{id}, would be.
+func batchPermit(m *matcher, collection string, operation BatchOperation) bool {
+	if m == nil {
+		return true
+	}
+	path := collection + "/" + strconv.Itoa(int(operation.Id))
+	switch operation.Op {
+	case BatchCreate:
+		return m.match(http.MethodPost, collection)
+	case BatchUpdate:
+		return m.match(http.MethodPut, path)
+	case BatchPatch:
+		return m.match(http.MethodPatch, path)
+	}
+	return m.match(http.MethodDelete, path)
+}
+
+// batchCreateList tries the fast path of a run of creates in a savepoint,
+// so that a failure leaves nothing behind, and reports whether it worked.
+func batchCreateList(r *http.Request, transaction pgx.Tx, entity batchEntity, operationList []BatchOperation, resultList []BatchResult) bool {
+	ctx := r.Context()
+
+	var bodyList [][]byte
+	for _, operation := range operationList {
+		body, err := json.Marshal(operation.Body)
+		if err != nil {
+			return false
+		}
+		bodyList = append(bodyList, body)
+	}
+
+	savepoint, err := transaction.Begin(ctx)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		err := savepoint.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	createdList, err := entity.createList(r, model.New(savepoint), bodyList)
+	if err != nil {
+		return false
+	}
+	err = savepoint.Commit(ctx)
+	if err != nil {
+		return false
+	}
+
+	copy(resultList, createdList)
+	return true
+}
+
+// batchOperation runs one operation through its handler, the handler begins
+// a savepoint on the batch transaction instead of a transaction of its own.
+func (a *API) batchOperation(w http.ResponseWriter, r *http.Request, transaction pgx.Tx, entity batchEntity, operation BatchOperation) BatchResult {
+	body, err := json.Marshal(operation.Body)
+	if err != nil {
+		return BatchResult{Status: int32(errcode.Status(errcode.Parse)), Code: errcode.Parse, Message: errcode.Msg(errcode.Parse)}
+	}
+
+	sub := r.Clone(r.Context())
+	sub.Body = io.NopCloser(bytes.NewReader(body))
+	sub.ContentLength = int64(len(body))
+	sub.Header.Set("Content-Type", "application/json")
+	sub.Header.Del("If-Match")
+	if operation.IfMatch != "" {
+		sub.Header.Set("If-Match", operation.IfMatch)
+	}
+
+	api := *a
+	api.DB = transaction
+	rec := &recorder{header: http.Header{}}
+	rec.header.Set(requestIDHeader, w.Header().Get(requestIDHeader))
+
+	switch operation.Op {
+	case BatchCreate:
+		sub.Method = http.MethodPost
+		entity.create(&api, rec, sub)
+	case BatchUpdate:
+		sub.Method = http.MethodPut
+		entity.update(&api, rec, sub, operation.Id)
+	case BatchPatch:
+		sub.Method = http.MethodPatch
+		sub.Header.Set("Content-Type", "application/merge-patch+json")
+		entity.patch(&api, rec, sub, operation.Id)
+	case BatchDelete:
+		sub.Method = http.MethodDelete
+		entity.delete(&api, rec, sub, operation.Id)
+	}
+	return rec.result(operation.Id)
+}
+
+// abortBatch marks every operation but the failed one as aborted, as the
+// rollback undoes the ones that went through as well.
+func abortBatch(resultList []BatchResult, failed int) []BatchResult {
+	for i := range resultList {
+		if i != failed {
+			resultList[i] = BatchResult{
+				Status:  int32(errcode.Status(errcode.BatchAborted)),
+				Code:    errcode.BatchAborted,
+				Message: errcode.Msg(errcode.BatchAborted),
+			}
+		}
+	}
+	return resultList
+}
+
+// decodeItem decodes and validates one entity of a batch as bind does.
+func decodeItem(body []byte, req any) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if config.Raw.Bool("DISALLOW_UNKNOWN_FIELDS") {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(req)
+	if err != nil {
+		return err
+	}
+	return validate.Struct(req)
+}
+
+// recorder keeps what a handler writes for one operation of a batch.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+func (rec *recorder) result(id int32) BatchResult {
+	rec.WriteHeader(http.StatusOK)
+	result := BatchResult{Status: int32(rec.status), Id: id, Etag: rec.header.Get("ETag")}
+	if rec.status >= http.StatusBadRequest {
+		var errResp Error
+		_ = json.Unmarshal(rec.body.Bytes(), &errResp)
+		result.Code = errResp.Code
+		result.Message = errResp.Message
+		result.Details = errResp.Details
+		return result
+	}
+
+	// a create answers with the new entity
+	var resp struct {
+		Id *int32 `json:"id"`
+	}
+	_ = json.Unmarshal(rec.body.Bytes(), &resp)
+	if resp.Id != nil {
+		result.Id = *resp.Id
+	}
+	return result
+}
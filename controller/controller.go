@@ -3,41 +3,136 @@ package controller
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"log"
 	"log/slog"
+	"mime"
 	"net/http"
+	"reflect"
+	"strings"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/linehk/go-admin/cache"
 	"github.com/linehk/go-admin/config"
 	"github.com/linehk/go-admin/errcode"
 	"github.com/linehk/go-admin/model"
 )
 
 type API struct {
-	DB *pgx.Conn
+	DB model.DB
+	// Cache holds the permissions of users, they are read from DB every
+	// time without it
+	Cache cache.Cache
 }
 
-func Setup() *http.ServeMux {
+func Setup() http.Handler {
+	_, err := jwtSecret()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	mux := http.NewServeMux()
 	ctx := context.Background()
-	DSN := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
-		config.Raw.String("HOST"), config.Raw.String("POSTGRES_USER"), config.Raw.String("POSTGRES_PASSWORD"),
-		config.Raw.String("POSTGRES_DB"), config.Raw.String("PORT"), config.Raw.String("SSL_MODE"),
-		config.Raw.String("TIMEZONE"))
-	api := &API{DB: model.Setup(ctx, DSN)}
+	pool := model.Setup(ctx, config.DSN())
+	err = model.MigrateUp(ctx, pool)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := cache.Setup(ctx, config.Raw.String("REDIS_ADDR"), config.Raw.String("REDIS_PASSWORD"), config.Raw.Int("REDIS_DB"))
+	api := &API{DB: pool, Cache: c}
 	options := StdHTTPServerOptions{
 		BaseRouter: mux,
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			Err(w, errcode.Parse)
+		},
 	}
 	HandlerWithOptions(api, options)
-	return mux
+	err = checkRouteList(mux)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api.setupRoot(ctx)
+	_, err = api.syncApiResource(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go api.purgeLoop(ctx)
+	return RequestID(api.Authorize(mux))
 }
 
-func decode(w http.ResponseWriter, r *http.Request, req any) {
-	err := json.NewDecoder(r.Body).Decode(&req)
+var validate = func() *validator.Validate {
+	v := validator.New()
+	// report fields by their json names
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	registerPasswordValidation(v)
+	registerResourceValidation(v)
+	return v
+}()
+
+const defaultMaxBodyBytes = 1 << 20
+
+var errBind = errors.New("bind error")
+
+// bind decodes the JSON body into req and validates it. It writes the error
+// response itself, so the handler only has to return when bind fails.
+func bind(w http.ResponseWriter, r *http.Request, req any) error {
+	err := decodeBody(w, r, req)
+	if err != nil {
+		return err
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		validateErr(w, err)
+		return err
+	}
+	return nil
+}
+
+// decodeBody decodes the JSON body into v and writes the error response
+// when it fails. MAX_BODY_BYTES limits the body and DISALLOW_UNKNOWN_FIELDS
+// rejects fields v does not have.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+		Err(w, errcode.UnsupportedMediaType)
+		return errBind
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes()))
+	if config.Raw.Bool("DISALLOW_UNKNOWN_FIELDS") {
+		decoder.DisallowUnknownFields()
+	}
+
+	err = decoder.Decode(v)
+	if err == nil && decoder.More() {
+		err = errors.New("body must hold a single JSON value")
+	}
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		Err(w, errcode.BodyTooLarge)
+		return err
+	}
 	if err != nil {
 		Err(w, errcode.Parse)
-		return
+		return err
+	}
+	return nil
+}
+
+func maxBodyBytes() int64 {
+	size := config.Raw.Int64("MAX_BODY_BYTES")
+	if size <= 0 {
+		return defaultMaxBodyBytes
 	}
+	return size
 }
 
 func encode(w http.ResponseWriter, resp any) {
@@ -48,23 +143,88 @@ func encode(w http.ResponseWriter, resp any) {
 	}
 }
 
+// paging returns the offset and limit of a page, the page size is capped
+// at MAX_PAGE_SIZE like the cursor paged lists.
 func paging(current, pageSize int32) (int32, int32) {
+	pageSize = pageLimit(pageSize)
 	if current > 0 && pageSize > 0 {
 		return (current - 1) * pageSize, pageSize
 	}
 	return current, pageSize
 }
 
+// constraintErrcode maps the constraints a write may violate onto errcodes.
+var constraintErrcode = map[string]int32{
+	"app_user_username_key":         errcode.UsernameOccupy,
+	"role_code_key":                 errcode.RoleCodeOccupy,
+	"menu_parent_id_code_key":       errcode.MenuCodeOccupy,
+	"user_role_role_id_fkey":        errcode.RoleNotExist,
+	"role_menu_menu_id_fkey":        errcode.MenuNotExist,
+	"menu_parent_id_fkey":           errcode.MenuNotExist,
+	"user_role_user_id_role_id_key": errcode.Validate,
+	"role_menu_role_id_menu_id_key": errcode.Validate,
+}
+
+// dbErr writes the errcode of the violated constraint, or Database.
+func dbErr(w http.ResponseWriter, err error) {
+	Err(w, dbErrcode(err))
+}
+
+func dbErrcode(err error) int32 {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		e, ok := constraintErrcode[pgErr.ConstraintName]
+		if ok {
+			return e
+		}
+	}
+	return errcode.Database
+}
+
+// validateErr writes Validate with a detail for every field that failed.
+func validateErr(w http.ResponseWriter, err error) {
+	writeErr(w, errcode.Validate, validateDetails(err))
+}
+
+func validateDetails(err error) []ErrorDetail {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	var details []ErrorDetail
+	for _, fieldError := range validationErrors {
+		// drop the struct name, keep nested paths like role[0].role_id
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		details = append(details, ErrorDetail{
+			Field: field,
+			Tag:   fieldError.Tag(),
+			Param: fieldError.Param(),
+		})
+	}
+	return details
+}
+
+// Err writes the error envelope with the HTTP status of e.
 func Err(w http.ResponseWriter, e int32) {
+	writeErr(w, e, nil)
+}
+
+func writeErr(w http.ResponseWriter, e int32, details []ErrorDetail) {
+	requestID := w.Header().Get(requestIDHeader)
 	errResp := Error{
-		Code:    e,
-		Message: errcode.Msg(e),
+		Code:      e,
+		Message:   errcode.Msg(e),
+		RequestId: requestID,
+		Details:   details,
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errcode.Status(e))
 	err := json.NewEncoder(w).Encode(errResp)
 	if err != nil {
 		panic(err)
 	}
-	slog.Error("err: ", errcode.Msg(e), e)
+	slog.Error(errcode.Msg(e), "code", e, "request_id", requestID)
 }
 
 const pgTimestampFormat = "2006-01-02 15:04:05.999999999"
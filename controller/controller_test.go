@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaging(t *testing.T) {
+	_ = config.Raw.Set("MAX_PAGE_SIZE", 0)
+	offset, limit := paging(3, 20)
+	assert.Equal(t, int32(40), offset)
+	assert.Equal(t, int32(20), limit)
+
+	// the page size is capped as the cursor paged lists do
+	offset, limit = paging(2, 100000)
+	assert.Equal(t, int32(defaultMaxPageSize), offset)
+	assert.Equal(t, int32(defaultMaxPageSize), limit)
+
+	_ = config.Raw.Set("MAX_PAGE_SIZE", 10)
+	offset, limit = paging(2, 100)
+	assert.Equal(t, int32(10), offset)
+	assert.Equal(t, int32(10), limit)
+	_ = config.Raw.Set("MAX_PAGE_SIZE", 0)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/linehk/go-admin/config"
+)
+
+const defaultMaxPageSize = 100
+
+// cursor holds the sort key of the last item of a page, it is handed to
+// the client base64 encoded and opaque. Sort is the sort parameter the
+// page was listed by, a cursor does not fit another order.
+type cursor struct {
+	Sort  string   `json:"s"`
+	After []string `json:"a"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, err
+	}
+	var c cursor
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return cursor{}, err
+	}
+	return c, nil
+}
+
+// pageLimit caps the page size at MAX_PAGE_SIZE.
+func pageLimit(pageSize int32) int32 {
+	maxPageSize := int32(config.Raw.Int("MAX_PAGE_SIZE"))
+	if maxPageSize <= 0 {
+		maxPageSize = defaultMaxPageSize
+	}
+	return min(pageSize, maxPageSize)
+}
@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/linehk/go-admin/errcode"
+)
+
+// etag is the strong entity tag of a row at version.
+func etag(version int32) string {
+	return `"` + strconv.Itoa(int(version)) + `"`
+}
+
+// ifMatch reports whether a write may go on. It writes PreconditionRequired
+// without an If-Match and PreconditionFailed when none of its tags is the
+// current one, so the handler only has to return when it is false.
+func ifMatch(w http.ResponseWriter, r *http.Request, version int32) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		Err(w, errcode.PreconditionRequired)
+		return false
+	}
+	// If-Match compares strongly, a weak tag never matches
+	if !matchTag(header, etag(version), false) {
+		Err(w, errcode.PreconditionFailed)
+		return false
+	}
+	return true
+}
+
+// ifNoneMatch writes 304 Not Modified and reports true when the client
+// already has the current version.
+func ifNoneMatch(w http.ResponseWriter, r *http.Request, version int32) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || !matchTag(header, etag(version), true) {
+		return false
+	}
+	w.Header().Set("ETag", etag(version))
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matchTag reports whether the comma separated tags in header hold tag or *.
+func matchTag(header, tag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if weak {
+			t = strings.TrimPrefix(t, "W/")
+		}
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
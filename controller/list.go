@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+var (
+	userSort = []model.Sort{
+		{Column: model.UserColumn["created"], Desc: true},
+	}
+	roleSort = []model.Sort{
+		{Column: model.RoleColumn["sequence"]},
+		{Column: model.RoleColumn["created"], Desc: true},
+	}
+	menuSort = []model.Sort{
+		{Column: model.MenuColumn["sequence"]},
+		{Column: model.MenuColumn["created"], Desc: true},
+	}
+)
+
+var errList = errors.New("list error")
+
+// listOption compiles the filter, sort and cursor parameters, the grammar
+// is declared by the Filter and Sort parameters in openapi.yaml. Like
+// bind, it writes the error response itself.
+func listOption(w http.ResponseWriter, columns map[string]model.Column, filter Filter, sort Sort, cursorText string, defaultSort []model.Sort) (model.ListOption, error) {
+	var option model.ListOption
+
+	for _, f := range filter {
+		field, rest, _ := strings.Cut(f, ":")
+		op, value, ok := strings.Cut(rest, ":")
+		column, exist := columns[field]
+		if !ok || !exist || !slices.Contains(model.Op[column.Type], op) {
+			Err(w, errcode.FilterInvalid)
+			return model.ListOption{}, errList
+		}
+
+		valueList := []string{value}
+		if op == model.In {
+			valueList = strings.Split(value, ",")
+		}
+		for _, v := range valueList {
+			if !validValue(column.Type, v) {
+				Err(w, errcode.FilterInvalid)
+				return model.ListOption{}, errList
+			}
+		}
+
+		option.Filter = append(option.Filter, model.Filter{Column: column, Op: op, Value: valueList})
+	}
+
+	option.Sort = defaultSort
+	if sort != "" {
+		option.Sort = nil
+		for _, field := range strings.Split(sort, ",") {
+			field, desc := strings.CutPrefix(field, "-")
+			column, exist := columns[field]
+			if !exist || slices.ContainsFunc(option.Sort, func(s model.Sort) bool { return s.Column == column }) {
+				Err(w, errcode.SortInvalid)
+				return model.ListOption{}, errList
+			}
+			option.Sort = append(option.Sort, model.Sort{Column: column, Desc: desc})
+		}
+	}
+
+	if cursorText != "" {
+		c, err := decodeCursor(cursorText)
+		if err != nil || c.Sort != sort || len(c.After) != len(option.Sort)+1 {
+			Err(w, errcode.CursorInvalid)
+			return model.ListOption{}, errList
+		}
+		for i, s := range append(option.Sort[:len(option.Sort):len(option.Sort)], model.Sort{Column: model.Column{Type: model.Int}}) {
+			if !validValue(s.Column.Type, c.After[i]) {
+				Err(w, errcode.CursorInvalid)
+				return model.ListOption{}, errList
+			}
+		}
+		option.After = c.After
+	}
+
+	return option, nil
+}
+
+// validValue reports whether the value casts to the column type,
+// so that a bad value is a bad request rather than a database error.
+func validValue(t model.ColumnType, value string) bool {
+	switch t {
+	case model.Int:
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err == nil
+	case model.Timestamp:
+		var timestamp pgtype.Timestamp
+		return timestamp.Scan(value) == nil
+	}
+	return true
+}
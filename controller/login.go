@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+const (
+	defaultLoginMaxFailure      = 5
+	defaultLoginMaxIPFailure    = 20
+	defaultLoginLockDuration    = time.Minute
+	defaultLoginLockMaxDuration = time.Hour
+	defaultLoginFailureWindow   = 15 * time.Minute
+)
+
+// the scopes failed logins are counted in
+const (
+	attemptUser = "user"
+	attemptIP   = "ip"
+)
+
+// the reasons a login is logged with
+const (
+	reasonUserNotExist      = "user_not_exist"
+	reasonPasswordIncorrect = "password_incorrect"
+	reasonUserFrozen        = "user_frozen"
+	reasonUserLocked        = "user_locked"
+	reasonIPLocked          = "ip_locked"
+)
+
+// loginMaxFailure is how many failed logins in a row lock the user out.
+func loginMaxFailure() int {
+	failure := config.Raw.Int("LOGIN_MAX_FAILURE")
+	if failure <= 0 {
+		return defaultLoginMaxFailure
+	}
+	return failure
+}
+
+// loginMaxIPFailure is the same for an ip, higher as users share addresses.
+func loginMaxIPFailure() int {
+	failure := config.Raw.Int("LOGIN_MAX_IP_FAILURE")
+	if failure <= 0 {
+		return defaultLoginMaxIPFailure
+	}
+	return failure
+}
+
+func loginLockDuration() time.Duration {
+	duration := config.Raw.Int("LOGIN_LOCK_DURATION")
+	if duration <= 0 {
+		return defaultLoginLockDuration
+	}
+	return time.Duration(duration) * time.Second
+}
+
+func loginLockMaxDuration() time.Duration {
+	duration := config.Raw.Int("LOGIN_LOCK_MAX_DURATION")
+	if duration <= 0 {
+		return defaultLoginLockMaxDuration
+	}
+	return time.Duration(duration) * time.Second
+}
+
+// loginFailureWindow is how long a failed login counts, the count starts
+// over after a quiet window.
+func loginFailureWindow() time.Duration {
+	window := config.Raw.Int("LOGIN_FAILURE_WINDOW")
+	if window <= 0 {
+		return defaultLoginFailureWindow
+	}
+	return time.Duration(window) * time.Second
+}
+
+// lockDuration doubles with every failure from the maxFailure-th on, up to
+// LOGIN_LOCK_MAX_DURATION, and is 0 below it.
+func lockDuration(failed, maxFailure int) time.Duration {
+	if failed < maxFailure {
+		return 0
+	}
+	duration := float64(loginLockDuration()) * math.Pow(2, float64(failed-maxFailure))
+	return time.Duration(min(duration, float64(loginLockMaxDuration())))
+}
+
+func (a *API) GetApiV1LoginLogs(w http.ResponseWriter, r *http.Request, params GetApiV1LoginLogsParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	err := validate.Struct(params)
+	if err != nil {
+		validateErr(w, err)
+		return
+	}
+
+	var modelParams model.ListLoginLogParams
+	modelParams.UserID = pgtype.Int4{Int32: params.UserId, Valid: params.UserId != 0}
+	modelParams.Ip = params.Ip
+	modelParams.Result = params.Result
+	if params.Start != "" {
+		err = modelParams.Start.Scan(params.Start)
+		if err != nil {
+			Err(w, errcode.Convert)
+			return
+		}
+	}
+	if params.End != "" {
+		err = modelParams.End.Scan(params.End)
+		if err != nil {
+			Err(w, errcode.Convert)
+			return
+		}
+	}
+	modelParams.Offset, modelParams.Limit = paging(params.Current, params.PageSize)
+
+	query := model.New(a.DB)
+
+	loginLogList, err := query.ListLoginLog(ctx, modelParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var respList []LoginLog
+	for _, loginLog := range loginLogList {
+		respList = append(respList, loginLogResp(loginLog))
+	}
+
+	encode(w, respList)
+}
+
+func (a *API) PostApiV1UsersIdUnlock(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	_, err := query.GetUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	params := model.DeleteLoginAttemptParams{
+		Scope: attemptUser,
+		Key:   strconv.Itoa(int(id)),
+	}
+	_, err = query.DeleteLoginAttempt(ctx, params)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+}
+
+func (a *API) PostApiV1IpsIpUnlock(w http.ResponseWriter, r *http.Request, ip string) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	err := validate.Var(ip, "ip")
+	if err != nil {
+		writeErr(w, errcode.Validate, []ErrorDetail{{Field: "ip", Tag: "ip"}})
+		return
+	}
+
+	query := model.New(a.DB)
+
+	params := model.DeleteLoginAttemptParams{
+		Scope: attemptIP,
+		Key:   ip,
+	}
+	_, err = query.DeleteLoginAttempt(ctx, params)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+}
+
+// lockedUntil returns when the lockout of key ends, or zero when it is not
+// locked out.
+func lockedUntil(ctx context.Context, query *model.Queries, scope, key string, now time.Time) (time.Time, error) {
+	params := model.GetLoginAttemptParams{
+		Scope: scope,
+		Key:   key,
+	}
+	attempt, err := query.GetLoginAttempt(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !attempt.LockedUntil.Valid || !attempt.LockedUntil.Time.After(now) {
+		return time.Time{}, nil
+	}
+	return attempt.LockedUntil.Time, nil
+}
+
+// failLogin logs the failed login and counts it against the ip and, when
+// the username exists, the user.
+func failLogin(r *http.Request, query *model.Queries, userID pgtype.Int4, username, reason string, now time.Time) error {
+	err := writeLoginLog(r, query, userID, username, LoginFailure, reason, now)
+	if err != nil {
+		return err
+	}
+
+	err = countFailure(r.Context(), query, attemptIP, clientIP(r), loginMaxIPFailure(), now)
+	if err != nil {
+		return err
+	}
+	if !userID.Valid {
+		return nil
+	}
+	return countFailure(r.Context(), query, attemptUser, strconv.Itoa(int(userID.Int32)), loginMaxFailure(), now)
+}
+
+func countFailure(ctx context.Context, query *model.Queries, scope, key string, maxFailure int, now time.Time) error {
+	failParams := model.FailLoginAttemptParams{
+		Scope:       scope,
+		Key:         key,
+		Updated:     pgtype.Timestamp{Time: now, Valid: true},
+		WindowStart: pgtype.Timestamp{Time: now.Add(-loginFailureWindow()), Valid: true},
+	}
+	attempt, err := query.FailLoginAttempt(ctx, failParams)
+	if err != nil {
+		return err
+	}
+
+	duration := lockDuration(int(attempt.Failed), maxFailure)
+	if duration == 0 {
+		return nil
+	}
+	lockParams := model.LockLoginAttemptParams{
+		ID:          attempt.ID,
+		LockedUntil: pgtype.Timestamp{Time: now.Add(duration), Valid: true},
+	}
+	return query.LockLoginAttempt(ctx, lockParams)
+}
+
+func writeLoginLog(r *http.Request, query *model.Queries, userID pgtype.Int4, username string, result LoginLogResult, reason string, now time.Time) error {
+	var params model.CreateLoginLogParams
+	params.UserID = userID
+	params.Username = username
+	params.Ip = clientIP(r)
+	params.UserAgent = r.UserAgent()
+	params.Result = string(result)
+	params.Reason = reason
+	params.Created = pgtype.Timestamp{Time: now, Valid: true}
+	return query.CreateLoginLog(r.Context(), params)
+}
+
+// loginLocked writes LoginLocked with the seconds left in Retry-After.
+func loginLocked(w http.ResponseWriter, until, now time.Time) {
+	retryAfter := int(math.Ceil(until.Sub(now).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	Err(w, errcode.LoginLocked)
+}
+
+func loginLogResp(m model.LoginLog) LoginLog {
+	var resp LoginLog
+	resp.Id = m.ID
+	resp.UserId = m.UserID.Int32
+	resp.Username = m.Username
+	resp.Ip = m.Ip
+	resp.UserAgent = m.UserAgent
+	resp.Result = LoginLogResult(m.Result)
+	resp.Reason = m.Reason
+	resp.Created = m.Created.Time.Format(pgTimestampFormat)
+	return resp
+}
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// anyMethod grants a resource path to every method.
+const anyMethod = "ANY"
+
+var errPattern = errors.New("pattern invalid")
+
+var knownMethod = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+	http.MethodConnect: true,
+	http.MethodTrace:   true,
+}
+
+type segmentKind int
+
+const (
+	literalSegment segmentKind = iota
+	// {name} or *, one segment
+	paramSegment
+	// {name...} or **, any number of segments
+	restSegment
+)
+
+type segment struct {
+	kind  segmentKind
+	value string
+}
+
+// parsePattern splits a resource path in ServeMux style, such as
+// /api/v1/users/{id}, into segments. {name} and * match one segment,
+// {name...} matches the remaining ones and ** any number of them.
+func parsePattern(pattern string) ([]segment, error) {
+	path, ok := strings.CutPrefix(pattern, "/")
+	if !ok {
+		return nil, errPattern
+	}
+
+	var segments []segment
+	for _, value := range strings.Split(path, "/") {
+		switch {
+		case value == "*":
+			segments = append(segments, segment{kind: paramSegment})
+		case value == "**":
+			segments = append(segments, segment{kind: restSegment})
+		case strings.HasPrefix(value, "{") && strings.HasSuffix(value, "...}"):
+			if len(value) == len("{...}") || len(segments) != strings.Count(path, "/") {
+				return nil, errPattern
+			}
+			segments = append(segments, segment{kind: restSegment})
+		case strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}"):
+			if len(value) == len("{}") {
+				return nil, errPattern
+			}
+			segments = append(segments, segment{kind: paramSegment})
+		case strings.ContainsAny(value, "{}*"):
+			// a wildcard is a whole segment
+			return nil, errPattern
+		default:
+			segments = append(segments, segment{kind: literalSegment, value: value})
+		}
+	}
+	return segments, nil
+}
+
+// parseMethods reads a comma separated method list, ANY grants all methods.
+func parseMethods(methods string) ([]string, bool, error) {
+	var methodList []string
+	for _, method := range strings.Split(methods, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == anyMethod {
+			return nil, true, nil
+		}
+		if !knownMethod[method] {
+			return nil, false, errPattern
+		}
+		methodList = append(methodList, method)
+	}
+	return methodList, false, nil
+}
+
+// matcher is a trie of the permissions of a user keyed by path segment,
+// it is compiled once the permissions are loaded, so that a lookup walks
+// the request path once without allocating.
+type matcher struct {
+	root node
+}
+
+type node struct {
+	literal map[string]*node
+	param   *node
+	rest    *node
+	// the methods of the patterns ending here
+	method    map[string]bool
+	anyMethod bool
+}
+
+// newMatcher compiles the permissions, a pattern that does not parse, as
+// stored before resources were validated, matches nothing.
+func newMatcher(permissionList []permission) *matcher {
+	m := &matcher{}
+	for _, permission := range permissionList {
+		segments, err := parsePattern(permission.Path)
+		if err != nil {
+			continue
+		}
+		methodList, anyMethod, err := parseMethods(permission.Method)
+		if err != nil {
+			continue
+		}
+
+		n := &m.root
+		for _, segment := range segments {
+			n = n.child(segment)
+		}
+		n.anyMethod = n.anyMethod || anyMethod
+		for _, method := range methodList {
+			if n.method == nil {
+				n.method = make(map[string]bool)
+			}
+			n.method[method] = true
+		}
+	}
+	return m
+}
+
+func (n *node) child(s segment) *node {
+	switch s.kind {
+	case paramSegment:
+		if n.param == nil {
+			n.param = &node{}
+		}
+		return n.param
+	case restSegment:
+		if n.rest == nil {
+			n.rest = &node{}
+		}
+		return n.rest
+	}
+	if n.literal == nil {
+		n.literal = make(map[string]*node)
+	}
+	child, ok := n.literal[s.value]
+	if !ok {
+		child = &node{}
+		n.literal[s.value] = child
+	}
+	return child
+}
+
+func (m *matcher) match(method, path string) bool {
+	path, ok := strings.CutPrefix(path, "/")
+	if !ok {
+		return false
+	}
+	return m.root.match(strings.ToUpper(method), path, false)
+}
+
+// match reports whether the segments left in path, none when end, reach a
+// pattern allowing method. Literal segments are tried before wildcards.
+func (n *node) match(method, path string, end bool) bool {
+	if end {
+		if n.anyMethod || n.method[method] {
+			return true
+		}
+		// ** matches no segment as well
+		return n.rest != nil && n.rest.match(method, "", true)
+	}
+
+	value, remain, last := cutSegment(path)
+	if child, ok := n.literal[value]; ok && child.match(method, remain, last) {
+		return true
+	}
+	if n.param != nil && n.param.match(method, remain, last) {
+		return true
+	}
+	if n.rest != nil {
+		if n.rest.match(method, path, false) {
+			return true
+		}
+		for remain, last := path, false; !last; {
+			_, remain, last = cutSegment(remain)
+			if n.rest.match(method, remain, last) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cutSegment returns the first segment of path, the rest and whether it was
+// the last one.
+func cutSegment(path string) (string, string, bool) {
+	value, remain, found := strings.Cut(path, "/")
+	return value, remain, !found
+}
+
+// overlap reports whether some path matches both segment lists.
+func overlap(a, b []segment) bool {
+	if len(a) > 0 && a[0].kind == restSegment {
+		// the rest takes no segment of b, or one more
+		return overlap(a[1:], b) || (len(b) > 0 && overlap(a, b[1:]))
+	}
+	if len(b) > 0 && b[0].kind == restSegment {
+		return overlap(b, a)
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	if a[0].kind == literalSegment && b[0].kind == literalSegment && a[0].value != b[0].value {
+		return false
+	}
+	return overlap(a[1:], b[1:])
+}
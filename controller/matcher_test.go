@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	m := newMatcher([]permission{
+		{Method: "GET", Path: "/api/v1/**"},
+		{Method: "GET", Path: "/api/v2/users/me"},
+		{Method: "DELETE", Path: "/api/v2/users/{id}"},
+		{Method: "GET", Path: "/files/{path...}"},
+		{Method: "GET, POST", Path: "/api/v2/roles"},
+		{Method: "ANY", Path: "/api/v2/menus/*"},
+	})
+
+	for _, test := range []struct {
+		method string
+		path   string
+		match  bool
+	}{
+		// ** matches no segment as well
+		{"GET", "/api/v1", true},
+		{"GET", "/api/v1/users", true},
+		{"GET", "/api/v1/users/1/sessions", true},
+		{"POST", "/api/v1/users", false},
+		// the literal does not allow DELETE, the param behind it does
+		{"GET", "/api/v2/users/me", true},
+		{"DELETE", "/api/v2/users/me", true},
+		{"GET", "/api/v2/users/1", false},
+		{"DELETE", "/api/v2/users/1/sessions", false},
+		{"GET", "/files/a", true},
+		{"GET", "/files/a/b/c", true},
+		{"GET", "/other/a", false},
+		{"GET", "/api/v2/roles", true},
+		{"post", "/api/v2/roles", true},
+		{"PUT", "/api/v2/roles", false},
+		{"PATCH", "/api/v2/menus/1", true},
+		{"PATCH", "/api/v2/menus", false},
+		{"GET", "api/v1/users", false},
+	} {
+		assert.Equal(t, test.match, m.match(test.method, test.path), test.method+" "+test.path)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.match("DELETE", "/api/v2/users/me")
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestNewMatcherSkipInvalid(t *testing.T) {
+	m := newMatcher([]permission{
+		{Method: "FETCH", Path: "/api/v1/users"},
+		{Method: "GET", Path: "api/v1/users"},
+		{Method: "GET", Path: "/api/{rest...}/users"},
+	})
+	assert.False(t, m.match("GET", "/api/v1/users"))
+	assert.False(t, m.match("FETCH", "/api/v1/users"))
+}
+
+func TestOverlap(t *testing.T) {
+	for _, test := range []struct {
+		a, b    string
+		overlap bool
+	}{
+		{"/api/v1/**", "/api/v1", true},
+		{"/api/v1/**", "/api/v1/users/{id}", true},
+		{"/api/v1/users/{id}", "/api/v1/users/me", true},
+		{"/api/v1/users/{id}", "/api/v1/roles/{id}", false},
+		{"/api/v1/users/*", "/api/v1/users/{id}/sessions", false},
+		{"/files/{path...}", "/files/a/b", true},
+		{"/files/**", "/api/**", false},
+		{"/**/sessions", "/api/v1/users/{id}/sessions", true},
+	} {
+		a, err := parsePattern(test.a)
+		assert.NoError(t, err)
+		b, err := parsePattern(test.b)
+		assert.NoError(t, err)
+		assert.Equal(t, test.overlap, overlap(a, b), test.a+" "+test.b)
+		assert.Equal(t, test.overlap, overlap(b, a), test.b+" "+test.a)
+	}
+}
+
+// BenchmarkMatch looks up a request in a permission set of the size a role
+// with a few menus grants.
+func BenchmarkMatch(b *testing.B) {
+	var permissionList []permission
+	for _, entity := range []string{"users", "roles", "menus", "sessions", "login-logs", "audit-logs", "api-resources"} {
+		permissionList = append(permissionList,
+			permission{Method: "GET, POST", Path: "/api/v1/" + entity},
+			permission{Method: "GET, PUT, PATCH, DELETE", Path: "/api/v1/" + entity + "/{id}"},
+			permission{Method: "POST", Path: fmt.Sprintf("/api/v1/%s/{id}/restore", entity)},
+		)
+	}
+	m := newMatcher(permissionList)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !m.match("PATCH", "/api/v1/api-resources/42") {
+			b.Fatal("no match")
+		}
+	}
+}
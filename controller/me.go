@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+func (a *API) GetApiV1MeMenus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		Err(w, errcode.TokenInvalid)
+		return
+	}
+
+	query := model.New(a.DB)
+
+	user, err := query.GetUser(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	var menuList []model.Menu
+	if isRoot(user) {
+		menuList, err = query.ListEnabledMenu(ctx)
+	} else {
+		menuList, err = query.ListMenuByUserID(ctx, user.ID)
+	}
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var menuIDList []int32
+	for _, menu := range menuList {
+		menuIDList = append(menuIDList, menu.ID)
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, menuIDList)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, menuTree(menuList, resourceList))
+}
+
+// menuTree nests the menus under their nearest ancestor in menuList,
+// siblings keep the order of menuList.
+func menuTree(menuList []model.Menu, resourceList []model.Resource) []Menu {
+	menuIDToResourceList := make(map[int32][]Resource)
+	for _, resource := range resourceList {
+		menuIDToResourceList[resource.MenuID] = append(menuIDToResourceList[resource.MenuID], resourceResp(resource))
+	}
+
+	exist := make(map[int32]bool)
+	for _, menu := range menuList {
+		exist[menu.ID] = true
+	}
+
+	parentIDToMenuList := make(map[int32][]model.Menu)
+	for _, menu := range menuList {
+		parentID := nearestAncestor(menu.ParentPath, exist)
+		parentIDToMenuList[parentID] = append(parentIDToMenuList[parentID], menu)
+	}
+
+	var build func(parentID int32) []Menu
+	build = func(parentID int32) []Menu {
+		var respList []Menu
+		for _, menu := range parentIDToMenuList[parentID] {
+			resp := menuResp(menu)
+			resp.Resource = menuIDToResourceList[menu.ID]
+			resp.Children = build(menu.ID)
+			respList = append(respList, resp)
+		}
+		return respList
+	}
+	return build(0)
+}
+
+// nearestAncestor walks parentPath such as "1.3." from the closest ancestor
+// and returns the first id found in exist, or 0 for the top level.
+func nearestAncestor(parentPath string, exist map[int32]bool) int32 {
+	idList := strings.Split(strings.TrimSuffix(parentPath, "."), ".")
+	for i := len(idList) - 1; i >= 0; i-- {
+		id, err := strconv.Atoi(idList[i])
+		if err != nil {
+			continue
+		}
+		if exist[int32(id)] {
+			return int32(id)
+		}
+	}
+	return 0
+}
@@ -1,13 +1,14 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/linehk/go-admin/errcode"
 	"github.com/linehk/go-admin/model"
 )
@@ -17,10 +18,8 @@ func (a *API) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req Menu
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
 		return
 	}
 
@@ -37,7 +36,7 @@ func (a *API) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
@@ -46,6 +45,13 @@ func (a *API) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
 
 	// have parent
 	if req.ParentId != 0 {
+		// a move cannot rewrite the parent_path of the parent meanwhile
+		err = query.ShareMenuTree(ctx)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
 		parentMenu, err := query.GetMenu(ctx, req.ParentId)
 		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 			Err(w, errcode.Database)
@@ -60,23 +66,9 @@ func (a *API) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
 		params.ParentPath = parentMenu.ParentPath + strconv.Itoa(int(parentMenu.ID)) + "."
 	}
 
-	checkParams := model.CheckMenuByCodeAndParentIDParams{
-		Code:     params.Code,
-		ParentID: params.ParentID,
-	}
-	exist, err := query.CheckMenuByCodeAndParentID(ctx, checkParams)
-	if err != nil {
-		Err(w, errcode.Database)
-		return
-	}
-	if exist {
-		Err(w, errcode.MenuCodeOccupy)
-		return
-	}
-
 	menu, err := query.CreateMenu(ctx, params)
 	if err != nil {
-		Err(w, errcode.Database)
+		dbErr(w, err)
 		return
 	}
 
@@ -97,14 +89,22 @@ func (a *API) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
 		resourceList = append(resourceList, resourceResp(resource))
 	}
 
+	resp := menuResp(menu)
+	resp.Resource = resourceList
+
+	err = writeAudit(r, query, Create, auditMenu, menu.ID, nil, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
 	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	resp := menuResp(menu)
-	resp.Resource = resourceList
+	w.Header().Set("ETag", etag(menu.Version))
 	encode(w, resp)
 }
 
@@ -112,49 +112,72 @@ func (a *API) GetApiV1Menus(w http.ResponseWriter, r *http.Request, params GetAp
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 
-	err := validator.New().Struct(params)
+	err := validate.Struct(params)
 	if err != nil {
-		Err(w, errcode.Validate)
+		validateErr(w, err)
 		return
 	}
 
-	var modelParams model.ListMenuParams
-	modelParams.Column1 = params.Menuname
-	modelParams.Column2 = params.Name
-	modelParams.Column3 = params.Status
-	modelParams.ID, modelParams.Limit = paging(params.Current, params.PageSize)
+	option, err := listOption(w, model.MenuColumn, params.Filter, params.Sort, params.Cursor, menuSort)
+	if err != nil {
+		return
+	}
+	if params.CodePath != "" {
+		prefix := model.Filter{Column: model.MenuColumn["parent_path"], Op: model.Prefix, Value: []string{params.CodePath}}
+		option.Filter = append(option.Filter, prefix)
+	}
+	option.IncludeDeleted = params.IncludeDeleted
+	pageSize := pageLimit(params.PageSize)
+	// one more row tells whether there is a next page
+	option.Limit = pageSize + 1
 
 	query := model.New(a.DB)
 
-	menuList, err := query.ListMenu(ctx, modelParams)
+	menuList, err := query.ListMenuBy(ctx, option)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
+	var resp MenuPage
+	if len(menuList) > int(pageSize) {
+		menuList = menuList[:pageSize]
+		resp.NextCursor = encodeCursor(cursor{Sort: params.Sort, After: model.After(option.Sort, menuList[len(menuList)-1])})
+	}
+
+	if params.Total {
+		total, err := query.CountMenuBy(ctx, option)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		resp.Total = &total
+	}
+
 	var menuIDList []int32
-	var respList []Menu
+	respList := make([]Menu, 0, len(menuList))
 	for _, menu := range menuList {
 		menuIDList = append(menuIDList, menu.ID)
 		respList = append(respList, menuResp(menu))
 	}
 
-	menuRoleList, err := query.ListMenuRoleByMenuIDList(ctx, menuIDList)
+	resourceList, err := query.ListResourceByMenuIDList(ctx, menuIDList)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	menuIDToMenuRoleList := make(map[int32][]MenuRole)
-	for _, menuRole := range menuRoleList {
-		menuIDToMenuRoleList[menuRole.MenuID] = append(menuIDToMenuRoleList[menuRole.MenuID], resourceResp(menuRole))
+	menuIDToResourceList := make(map[int32][]Resource)
+	for _, resource := range resourceList {
+		menuIDToResourceList[resource.MenuID] = append(menuIDToResourceList[resource.MenuID], resourceResp(resource))
 	}
 
 	for i := range respList {
-		respList[i].Role = menuIDToMenuRoleList[*respList[i].Id]
+		respList[i].Resource = menuIDToResourceList[*respList[i].Id]
 	}
 
-	encode(w, respList)
+	resp.Items = respList
+	encode(w, resp)
 }
 
 func (a *API) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -168,14 +191,35 @@ func (a *API) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request, id int3
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	menu, err := query.GetMenu(ctx, id)
+	// a move cannot bring a child in or take one out of the subtree meanwhile
+	err = query.LockMenuTree(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockMenuVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	menuByGet, err := menuWithResource(ctx, query, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -185,19 +229,49 @@ func (a *API) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request, id int3
 		return
 	}
 
-	childIdList, err := query.ListChildID(ctx, menu.ParentPath+strconv.Itoa(int(menu.ID))+".")
+	// children are deleted along, so they are audited as well
+	childIDList, err := query.ListChildID(ctx, menuByGet.ParentPath+strconv.Itoa(int(id))+".")
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
+	for _, childID := range childIDList {
+		childByGet, err := menuWithResource(ctx, query, childID)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
 
-	err = query.DeleteMenu(ctx, id)
+		err = writeAudit(r, query, Delete, auditMenu, childID, childByGet, nil)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+	}
+
+	// the rows are kept until purged, so that the menu can be restored
+	deletedAt := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	menuIDList := append([]int32{id}, childIDList...)
+
+	err = query.SoftDeleteMenuByIDList(ctx, model.SoftDeleteMenuByIDListParams{Column1: menuIDList, DeletedAt: deletedAt})
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	err = query.DeleteMenuRoleByMenuID(ctx, id)
+	err = query.SoftDeleteResourceByMenuIDList(ctx, model.SoftDeleteResourceByMenuIDListParams{Column1: menuIDList, DeletedAt: deletedAt})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = query.SoftDeleteRoleMenuByMenuIDList(ctx, model.SoftDeleteRoleMenuByMenuIDListParams{Column1: menuIDList, DeletedAt: deletedAt})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Delete, auditMenu, id, menuByGet, nil)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
@@ -208,6 +282,8 @@ func (a *API) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request, id int3
 		Err(w, errcode.Database)
 		return
 	}
+
+	a.invalidatePermission(ctx)
 }
 
 func (a *API) GetApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -216,7 +292,8 @@ func (a *API) GetApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 
 	query := model.New(a.DB)
 
-	menu, err := query.GetMenu(ctx, id)
+	// read before the menu, so the ETag can only be older than the body
+	version, err := query.GetMenuVersion(ctx, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -225,20 +302,21 @@ func (a *API) GetApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.MenuNotExist)
 		return
 	}
+	if ifNoneMatch(w, r, version) {
+		return
+	}
 
-	menuRoleList, err := query.ListMenuRoleByMenuIDList(ctx, []int32{menu.ID})
-	if err != nil {
+	resp, err := menuWithResource(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
 	}
-
-	var menuRoleListResp []MenuRole
-	for _, menuRole := range menuRoleList {
-		menuRoleListResp = append(menuRoleListResp, resourceResp(menuRole))
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
 	}
 
-	resp := menuResp(menu)
-	resp.Role = menuRoleListResp
+	w.Header().Set("ETag", etag(version))
 	encode(w, resp)
 }
 
@@ -247,10 +325,8 @@ func (a *API) PutApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 	ctx := r.Context()
 
 	var req Menu
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
 		return
 	}
 
@@ -267,14 +343,15 @@ func (a *API) PutApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	menuByGet, err := query.GetMenu(ctx, id)
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockMenuVersion(ctx, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -283,49 +360,300 @@ func (a *API) PutApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.MenuNotExist)
 		return
 	}
+	if !ifMatch(w, r, version) {
+		return
+	}
 
-	// update menuname
-	if req.Menuname != menuByGet.Menuname {
-		exist, err := query.CheckMenuByMenuname(ctx, req.Menuname)
+	menuByGet, err := menuWithResource(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+
+	// moving a menu would leave the parent_path of its children stale
+	if req.ParentId != menuByGet.ParentId {
+		menuMoveRequired(w)
+		return
+	}
+
+	params.ID = id
+	params.ParentID = pgtype.Int4{Int32: menuByGet.ParentId, Valid: menuByGet.ParentId != 0}
+	params.ParentPath = menuByGet.ParentPath
+
+	menuByUpdate, err := query.UpdateMenu(ctx, params)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	err = query.DeleteResourceByMenuID(ctx, menuByUpdate.ID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var resourceList []Resource
+	for _, req := range req.Resource {
+		params, err := createResourceParams(req, menuByUpdate.ID)
 		if err != nil {
-			Err(w, errcode.Database)
+			Err(w, errcode.Convert)
 			return
 		}
-		if exist {
-			Err(w, errcode.MenunameOccupy)
+
+		resource, err := query.CreateResource(ctx, params)
+		if err != nil {
+			Err(w, errcode.Database)
 			return
 		}
+
+		resourceList = append(resourceList, resourceResp(resource))
+	}
+
+	resp := menuResp(menuByUpdate)
+	resp.Resource = resourceList
+
+	err = writeAudit(r, query, Update, auditMenu, id, menuByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
 	}
 
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(menuByUpdate.Version))
+	encode(w, resp)
+}
+
+func (a *API) PatchApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockMenuVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	menuByGet, err := menuWithResource(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+
+	var req Menu
+	p, err := bindPatch(w, r, menuByGet, &req)
+	if err != nil {
+		return
+	}
+	if p.has("parent_id") && req.ParentId != menuByGet.ParentId {
+		menuMoveRequired(w)
+		return
+	}
+
+	params, err := patchMenuParams(p, req)
+	if err != nil {
+		Err(w, errcode.Convert)
+		return
+	}
 	params.ID = id
 
-	menuByUpdate, err := query.UpdateMenu(ctx, params)
+	menuByPatch, err := query.PatchMenu(ctx, params)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	resourceList := menuByGet.Resource
+	if p.has("resource") {
+		err = query.DeleteResourceByMenuID(ctx, id)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
+		resourceList = nil
+		for _, req := range req.Resource {
+			params, err := createResourceParams(req, id)
+			if err != nil {
+				Err(w, errcode.Convert)
+				return
+			}
+
+			resource, err := query.CreateResource(ctx, params)
+			if err != nil {
+				Err(w, errcode.Database)
+				return
+			}
+
+			resourceList = append(resourceList, resourceResp(resource))
+		}
+	}
+
+	resp := menuResp(menuByPatch)
+	resp.Resource = resourceList
+
+	err = writeAudit(r, query, Update, auditMenu, id, menuByGet, resp)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	err = query.DeleteMenuRoleByMenuID(ctx, menuByUpdate.ID)
+	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	var menuRoleList []MenuRole
-	for _, req := range req.Role {
-		params, err := createResourceParams(req, menuByUpdate.ID)
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(menuByPatch.Version))
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1MenusIdRestore(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	menuByGet, err := query.GetDeletedMenu(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+
+	// a deleted parent has to be restored first
+	if menuByGet.ParentID.Valid {
+		exist, err := query.CheckMenuByID(ctx, menuByGet.ParentID.Int32)
 		if err != nil {
-			Err(w, errcode.Convert)
+			Err(w, errcode.Database)
+			return
+		}
+		if !exist {
+			Err(w, errcode.MenuNotExist)
+			return
+		}
+	}
+
+	// only the children deleted along with the menu come back
+	listParams := model.ListDeletedChildIDParams{
+		Column1:   menuByGet.ParentPath + strconv.Itoa(int(id)) + ".",
+		DeletedAt: menuByGet.DeletedAt,
+	}
+	childIDList, err := query.ListDeletedChildID(ctx, listParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	menuIDList := append([]int32{id}, childIDList...)
+
+	restoreParams := model.RestoreMenuByIDListParams{
+		Column1:   menuIDList,
+		DeletedAt: menuByGet.DeletedAt,
+		Updated:   pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	err = query.RestoreMenuByIDList(ctx, restoreParams)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	restoreResourceParams := model.RestoreResourceByMenuIDListParams{
+		Column1:   menuIDList,
+		DeletedAt: menuByGet.DeletedAt,
+	}
+	err = query.RestoreResourceByMenuIDList(ctx, restoreResourceParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	restoreRoleMenuParams := model.RestoreRoleMenuByMenuIDListParams{
+		Column1:   menuIDList,
+		DeletedAt: menuByGet.DeletedAt,
+	}
+	err = query.RestoreRoleMenuByMenuIDList(ctx, restoreRoleMenuParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	for _, childID := range childIDList {
+		childByGet, err := menuWithResource(ctx, query, childID)
+		if err != nil {
+			Err(w, errcode.Database)
 			return
 		}
 
-		menuRole, err := query.CreateMenuRole(ctx, params)
+		err = writeAudit(r, query, Restore, auditMenu, childID, nil, childByGet)
 		if err != nil {
 			Err(w, errcode.Database)
 			return
 		}
+	}
+
+	resp, err := menuWithResource(ctx, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
 
-		menuRoleList = append(menuRoleList, resourceResp(menuRole))
+	err = writeAudit(r, query, Restore, auditMenu, id, menuResp(menuByGet), resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
 	}
 
 	err = transaction.Commit(ctx)
@@ -334,11 +662,154 @@ func (a *API) PutApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 		return
 	}
 
-	resp := menuResp(menuByUpdate)
-	resp.Role = menuRoleList
+	a.invalidatePermission(ctx)
+
+	// RestoreMenuByIDList bumped the version
+	w.Header().Set("ETag", etag(menuByGet.Version+1))
 	encode(w, resp)
 }
 
+var menuBatch = batchEntity{
+	create:     (*API).PostApiV1Menus,
+	update:     (*API).PutApiV1MenusId,
+	patch:      (*API).PatchApiV1MenusId,
+	delete:     (*API).DeleteApiV1MenusId,
+	createList: createMenuList,
+}
+
+func (a *API) PostApiV1MenusBatch(w http.ResponseWriter, r *http.Request) {
+	a.batch(w, r, menuBatch)
+}
+
+// createMenuList inserts the menus in one batch, their resources and audit
+// entries with one copy each. The parents have to exist before the batch.
+func createMenuList(r *http.Request, query *model.Queries, bodyList [][]byte) ([]BatchResult, error) {
+	ctx := r.Context()
+
+	reqList := make([]Menu, len(bodyList))
+	var parentIDList []int32
+	for i, body := range bodyList {
+		err := decodeItem(body, &reqList[i])
+		if err != nil {
+			return nil, err
+		}
+		if reqList[i].ParentId != 0 {
+			parentIDList = append(parentIDList, reqList[i].ParentId)
+		}
+	}
+
+	err := query.ShareMenuTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parentList, err := query.ListMenuByIDList(ctx, parentIDList)
+	if err != nil {
+		return nil, err
+	}
+	parentMap := make(map[int32]model.Menu)
+	for _, parent := range parentList {
+		parentMap[parent.ID] = parent
+	}
+
+	paramsList := make([]model.CreateMenuBatchParams, len(reqList))
+	for i, req := range reqList {
+		params, err := createMenuParams(req)
+		if err != nil {
+			return nil, err
+		}
+		if req.ParentId != 0 {
+			parent, ok := parentMap[req.ParentId]
+			if !ok {
+				return nil, pgx.ErrNoRows
+			}
+			params.ParentPath = parent.ParentPath + strconv.Itoa(int(parent.ID)) + "."
+		}
+		paramsList[i] = model.CreateMenuBatchParams(params)
+	}
+
+	menuList := make([]model.Menu, len(paramsList))
+	var batchErr error
+	query.CreateMenuBatch(ctx, paramsList).QueryRow(func(i int, menu model.Menu, err error) {
+		if err != nil && batchErr == nil {
+			batchErr = err
+		}
+		menuList[i] = menu
+	})
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	var menuIDList []int32
+	var resourceParamsList []model.CopyResourceParams
+	for i, req := range reqList {
+		menuIDList = append(menuIDList, menuList[i].ID)
+		for _, req := range req.Resource {
+			params, err := createResourceParams(req, menuList[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			resourceParamsList = append(resourceParamsList, model.CopyResourceParams(params))
+		}
+	}
+	_, err = query.CopyResource(ctx, resourceParamsList)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, menuIDList)
+	if err != nil {
+		return nil, err
+	}
+	resourceMap := make(map[int32][]Resource)
+	for _, resource := range resourceList {
+		resourceMap[resource.MenuID] = append(resourceMap[resource.MenuID], resourceResp(resource))
+	}
+
+	resultList := make([]BatchResult, len(menuList))
+	var auditParamsList []model.CopyAuditLogParams
+	for i, menu := range menuList {
+		resp := menuResp(menu)
+		resp.Resource = resourceMap[menu.ID]
+		params, err := auditParams(r, Create, auditMenu, menu.ID, nil, resp)
+		if err != nil {
+			return nil, err
+		}
+		auditParamsList = append(auditParamsList, model.CopyAuditLogParams(params))
+		resultList[i] = BatchResult{Status: http.StatusOK, Id: menu.ID, Etag: etag(menu.Version)}
+	}
+	_, err = query.CopyAuditLog(ctx, auditParamsList)
+	if err != nil {
+		return nil, err
+	}
+	return resultList, nil
+}
+
+// menuWithResource reads the menu with its resources, pgx.ErrNoRows if it does not exist.
+func menuWithResource(ctx context.Context, query *model.Queries, id int32) (Menu, error) {
+	menu, err := query.GetMenu(ctx, id)
+	if err != nil {
+		return Menu{}, err
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, []int32{menu.ID})
+	if err != nil {
+		return Menu{}, err
+	}
+
+	resp := menuResp(menu)
+	for _, resource := range resourceList {
+		resp.Resource = append(resp.Resource, resourceResp(resource))
+	}
+	return resp, nil
+}
+
+// menuMoveRequired writes Validate for a write that changes parent_id, a
+// menu is moved with its subtree by POST /api/v1/menus/{id}/move instead.
+func menuMoveRequired(w http.ResponseWriter) {
+	writeErr(w, errcode.Validate, []ErrorDetail{{Field: "parent_id", Tag: "move", Param: "/api/v1/menus/{id}/move"}})
+}
+
 func createMenuParams(req Menu) (model.CreateMenuParams, error) {
 	var params model.CreateMenuParams
 	params.Code = req.Code
@@ -351,7 +822,7 @@ func createMenuParams(req Menu) (model.CreateMenuParams, error) {
 	params.Type = string(req.Type)
 	params.Path = req.Path
 	params.Property = req.Property
-	params.ParentID = req.ParentId
+	params.ParentID = pgtype.Int4{Int32: req.ParentId, Valid: req.ParentId != 0}
 	params.ParentPath = req.ParentPath
 	if req.Status == "" {
 		req.Status = MenuStatusEnabled
@@ -376,18 +847,17 @@ func createMenuParams(req Menu) (model.CreateMenuParams, error) {
 
 func updateMenuParams(req Menu) (model.UpdateMenuParams, error) {
 	var params model.UpdateMenuParams
-	params.Menuname = req.Menuname
-	password, err := hash(req.Password)
-	if err != nil {
-		return model.UpdateMenuParams{}, err
-	}
-	params.Password = password
+	params.Code = req.Code
 	params.Name = req.Name
-	params.Email = req.Email
-	params.Phone = req.Phone
-	params.Remark = req.Remark
+	params.Description = req.Description
+	params.Sequence = req.Sequence
+	params.Type = string(req.Type)
+	params.Path = req.Path
+	params.Property = req.Property
+	params.ParentID = pgtype.Int4{Int32: req.ParentId, Valid: req.ParentId != 0}
+	params.ParentPath = req.ParentPath
 	params.Status = string(req.Status)
-	err = params.Created.Scan(req.Created)
+	err := params.Created.Scan(req.Created)
 	if err != nil {
 		return model.UpdateMenuParams{}, err
 	}
@@ -401,6 +871,34 @@ func updateMenuParams(req Menu) (model.UpdateMenuParams, error) {
 	return params, nil
 }
 
+// patchMenuParams sets only the fields p has, the parent stays as PUT
+// leaves it, so the parent_path of the children never goes stale.
+func patchMenuParams(p patch, req Menu) (model.PatchMenuParams, error) {
+	var params model.PatchMenuParams
+	params.Code = pgtype.Text{String: req.Code, Valid: p.has("code")}
+	params.Name = pgtype.Text{String: req.Name, Valid: p.has("name")}
+	params.Description = pgtype.Text{String: req.Description, Valid: p.has("description")}
+	params.Sequence = pgtype.Int2{Int16: req.Sequence, Valid: p.has("sequence")}
+	params.Type = pgtype.Text{String: string(req.Type), Valid: p.has("type")}
+	params.Path = pgtype.Text{String: req.Path, Valid: p.has("path")}
+	params.Property = pgtype.Text{String: req.Property, Valid: p.has("property")}
+	params.Status = pgtype.Text{String: string(req.Status), Valid: p.has("status")}
+	if p.has("created") {
+		err := params.Created.Scan(req.Created)
+		if err != nil {
+			return model.PatchMenuParams{}, err
+		}
+	}
+	if !p.has("updated") {
+		req.Updated = time.Now().Format(pgTimestampFormat)
+	}
+	err := params.Updated.Scan(req.Updated)
+	if err != nil {
+		return model.PatchMenuParams{}, err
+	}
+	return params, nil
+}
+
 func menuResp(m model.Menu) Menu {
 	var resp Menu
 	resp.Id = &m.ID
@@ -411,11 +909,14 @@ func menuResp(m model.Menu) Menu {
 	resp.Type = MenuType(m.Type)
 	resp.Path = m.Path
 	resp.Property = m.Property
-	resp.ParentId = m.ParentID
+	resp.ParentId = m.ParentID.Int32
 	resp.ParentPath = m.ParentPath
 	resp.Status = MenuStatus(m.Status)
 	resp.Created = m.Created.Time.Format(pgTimestampFormat)
 	resp.Updated = m.Updated.Time.Format(pgTimestampFormat)
+	if m.DeletedAt.Valid {
+		resp.DeletedAt = m.DeletedAt.Time.Format(pgTimestampFormat)
+	}
 	return resp
 }
 
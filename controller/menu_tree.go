@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"cmp"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+func (a *API) GetApiV1MenusTree(w http.ResponseWriter, r *http.Request, params GetApiV1MenusTreeParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	var menuList []model.Menu
+	parentPath := ""
+	if params.Root != 0 {
+		root, err := query.GetMenu(ctx, params.Root)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.Database)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.MenuNotExist)
+			return
+		}
+		menuList = append(menuList, root)
+		parentPath = root.ParentPath + strconv.Itoa(int(root.ID)) + "."
+	}
+
+	childList, err := query.ListMenuByParentPath(ctx, parentPath)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	menuList = append(menuList, childList...)
+
+	var menuIDList []int32
+	for _, menu := range menuList {
+		menuIDList = append(menuIDList, menu.ID)
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, menuIDList)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, menuTree(menuList, resourceList))
+}
+
+func (a *API) PostApiV1MenusIdMove(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req MenuMove
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// moves run one at a time, two of them crossing each other would
+	// otherwise both pass the cycle check
+	err = query.LockMenuTree(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	version, err := query.LockMenuVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	menuByGet, err := menuWithResource(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.MenuNotExist)
+		return
+	}
+
+	parentPath := ""
+	if req.ParentId != 0 {
+		parentMenu, err := query.GetMenu(ctx, req.ParentId)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.Database)
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			Err(w, errcode.MenuNotExist)
+			return
+		}
+		parentPath = parentMenu.ParentPath + strconv.Itoa(int(parentMenu.ID)) + "."
+	}
+
+	// the parent is the menu itself or one of its descendants
+	if strings.HasPrefix(parentPath, menuByGet.ParentPath+strconv.Itoa(int(id))+".") {
+		Err(w, errcode.MenuCycle)
+		return
+	}
+
+	moveParams := model.MoveMenuParams{
+		ID:            id,
+		ParentID:      pgtype.Int4{Int32: req.ParentId, Valid: req.ParentId != 0},
+		ParentPath:    parentPath,
+		OldParentPath: menuByGet.ParentPath,
+		Updated:       pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	movedList, err := query.MoveMenu(ctx, moveParams)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+	i := slices.IndexFunc(movedList, func(m model.Menu) bool {
+		return m.ID == id
+	})
+	menuByMove := movedList[i]
+
+	resp := menuResp(menuByMove)
+	resp.Resource = menuByGet.Resource
+
+	err = writeAudit(r, query, Update, auditMenu, id, menuByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	// a disabled ancestor withholds the resources of its subtree
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(menuByMove.Version))
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1MenusReorder(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req MenuReorder
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// a move cannot take a child away or bring one in meanwhile
+	err = query.ShareMenuTree(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	if req.ParentId != 0 {
+		exist, err := query.CheckMenuByID(ctx, req.ParentId)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		if !exist {
+			Err(w, errcode.MenuNotExist)
+			return
+		}
+	}
+
+	// the children stay locked, so none joins or leaves before the write
+	parentID := pgtype.Int4{Int32: req.ParentId, Valid: req.ParentId != 0}
+	childList, err := query.ListMenuByParentID(ctx, parentID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	idToChild := make(map[int32]model.Menu)
+	for _, child := range childList {
+		idToChild[child.ID] = child
+	}
+	if len(req.Id) != len(childList) {
+		Err(w, errcode.MenuNotSibling)
+		return
+	}
+	seen := make(map[int32]bool)
+	for _, id := range req.Id {
+		_, ok := idToChild[id]
+		if !ok || seen[id] {
+			Err(w, errcode.MenuNotSibling)
+			return
+		}
+		seen[id] = true
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, req.Id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	menuIDToResourceList := make(map[int32][]Resource)
+	for _, resource := range resourceList {
+		menuIDToResourceList[resource.MenuID] = append(menuIDToResourceList[resource.MenuID], resourceResp(resource))
+	}
+
+	reorderParams := model.ReorderMenuParams{
+		Updated:  pgtype.Timestamp{Time: time.Now(), Valid: true},
+		ParentID: parentID,
+		IDList:   req.Id,
+	}
+	menuList, err := query.ReorderMenu(ctx, reorderParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	slices.SortFunc(menuList, func(a, b model.Menu) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	respList := make([]Menu, 0, len(menuList))
+	for _, menu := range menuList {
+		before := menuResp(idToChild[menu.ID])
+		before.Resource = menuIDToResourceList[menu.ID]
+		resp := menuResp(menu)
+		resp.Resource = menuIDToResourceList[menu.ID]
+
+		err = writeAudit(r, query, Update, auditMenu, menu.ID, before, resp)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		respList = append(respList, resp)
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, respList)
+}
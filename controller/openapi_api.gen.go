@@ -15,21 +15,81 @@ import (
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (GET /api/v1/api-resources)
+	GetApiV1ApiResources(w http.ResponseWriter, r *http.Request, params GetApiV1ApiResourcesParams)
+
+	// (POST /api/v1/api-resources/sync)
+	PostApiV1ApiResourcesSync(w http.ResponseWriter, r *http.Request)
+
+	// (GET /api/v1/audit-logs)
+	GetApiV1AuditLogs(w http.ResponseWriter, r *http.Request, params GetApiV1AuditLogsParams)
+
+	// (POST /api/v1/auth/2fa)
+	PostApiV1Auth2fa(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/auth/login)
+	PostApiV1AuthLogin(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/auth/logout)
+	PostApiV1AuthLogout(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/auth/refresh)
+	PostApiV1AuthRefresh(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/ips/{ip}/unlock)
+	PostApiV1IpsIpUnlock(w http.ResponseWriter, r *http.Request, ip string)
+
+	// (GET /api/v1/login-logs)
+	GetApiV1LoginLogs(w http.ResponseWriter, r *http.Request, params GetApiV1LoginLogsParams)
+
+	// (POST /api/v1/me/2fa/disable)
+	PostApiV1Me2faDisable(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/me/2fa/enroll)
+	PostApiV1Me2faEnroll(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/me/2fa/verify)
+	PostApiV1Me2faVerify(w http.ResponseWriter, r *http.Request)
+
+	// (GET /api/v1/me/menus)
+	GetApiV1MeMenus(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/me/password)
+	PostApiV1MePassword(w http.ResponseWriter, r *http.Request)
+
 	// (GET /api/v1/menus)
 	GetApiV1Menus(w http.ResponseWriter, r *http.Request, params GetApiV1MenusParams)
 
 	// (POST /api/v1/menus)
 	PostApiV1Menus(w http.ResponseWriter, r *http.Request)
 
+	// (GET /api/v1/menus/tree)
+	GetApiV1MenusTree(w http.ResponseWriter, r *http.Request, params GetApiV1MenusTreeParams)
+
 	// (DELETE /api/v1/menus/{id})
 	DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 
 	// (GET /api/v1/menus/{id})
 	GetApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 
+	// (PATCH /api/v1/menus/{id})
+	PatchApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
+
 	// (PUT /api/v1/menus/{id})
 	PutApiV1MenusId(w http.ResponseWriter, r *http.Request, id int32)
 
+	// (POST /api/v1/menus/{id}/move)
+	PostApiV1MenusIdMove(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/menus/{id}/restore)
+	PostApiV1MenusIdRestore(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/menus:batch)
+	PostApiV1MenusBatch(w http.ResponseWriter, r *http.Request)
+
+	// (POST /api/v1/menus:reorder)
+	PostApiV1MenusReorder(w http.ResponseWriter, r *http.Request)
+
 	// (GET /api/v1/roles)
 	GetApiV1Roles(w http.ResponseWriter, r *http.Request, params GetApiV1RolesParams)
 
@@ -42,23 +102,71 @@ type ServerInterface interface {
 	// (GET /api/v1/roles/{id})
 	GetApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 
+	// (PATCH /api/v1/roles/{id})
+	PatchApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
+
 	// (PUT /api/v1/roles/{id})
 	PutApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 
+	// (GET /api/v1/roles/{id}/parents)
+	GetApiV1RolesIdParents(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (PUT /api/v1/roles/{id}/parents)
+	PutApiV1RolesIdParents(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (GET /api/v1/roles/{id}/permissions)
+	GetApiV1RolesIdPermissions(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/roles/{id}/restore)
+	PostApiV1RolesIdRestore(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/roles:batch)
+	PostApiV1RolesBatch(w http.ResponseWriter, r *http.Request)
+
+	// (GET /api/v1/sessions)
+	GetApiV1Sessions(w http.ResponseWriter, r *http.Request, params GetApiV1SessionsParams)
+
+	// (DELETE /api/v1/sessions/{id})
+	DeleteApiV1SessionsId(w http.ResponseWriter, r *http.Request, id int32)
+
 	// (GET /api/v1/users)
 	GetApiV1Users(w http.ResponseWriter, r *http.Request, params GetApiV1UsersParams)
 
 	// (POST /api/v1/users)
 	PostApiV1Users(w http.ResponseWriter, r *http.Request)
 
+	// (GET /api/v1/users/export)
+	GetApiV1UsersExport(w http.ResponseWriter, r *http.Request, params GetApiV1UsersExportParams)
+
+	// (POST /api/v1/users/import)
+	PostApiV1UsersImport(w http.ResponseWriter, r *http.Request, params PostApiV1UsersImportParams)
+
 	// (DELETE /api/v1/users/{id})
 	DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 
 	// (GET /api/v1/users/{id})
 	GetApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 
+	// (PATCH /api/v1/users/{id})
+	PatchApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
+
 	// (PUT /api/v1/users/{id})
 	PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/users/{id}/password-reset)
+	PostApiV1UsersIdPasswordReset(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/users/{id}/restore)
+	PostApiV1UsersIdRestore(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (DELETE /api/v1/users/{id}/sessions)
+	DeleteApiV1UsersIdSessions(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/users/{id}/unlock)
+	PostApiV1UsersIdUnlock(w http.ResponseWriter, r *http.Request, id int32)
+
+	// (POST /api/v1/users:batch)
+	PostApiV1UsersBatch(w http.ResponseWriter, r *http.Request)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -70,47 +178,32 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
-// GetApiV1Menus operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1Menus(w http.ResponseWriter, r *http.Request) {
+// GetApiV1ApiResources operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1ApiResources(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetApiV1MenusParams
-
-	// ------------- Required query parameter "codePath" -------------
-
-	if paramValue := r.URL.Query().Get("codePath"); paramValue != "" {
-
-	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "codePath"})
-		return
-	}
+	var params GetApiV1ApiResourcesParams
 
-	err = runtime.BindQueryParameter("form", true, true, "codePath", r.URL.Query(), &params.CodePath)
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "codePath", Err: err})
-		return
-	}
-
-	// ------------- Required query parameter "name" -------------
+	// ------------- Required query parameter "tag" -------------
 
-	if paramValue := r.URL.Query().Get("name"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("tag"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "name"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "tag"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "name", r.URL.Query(), &params.Name)
+	err = runtime.BindQueryParameter("form", true, true, "tag", r.URL.Query(), &params.Tag)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "tag", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1Menus(w, r, params)
+		siw.Handler.GetApiV1ApiResources(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -120,12 +213,12 @@ func (siw *ServerInterfaceWrapper) GetApiV1Menus(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// PostApiV1Menus operation middleware
-func (siw *ServerInterfaceWrapper) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
+// PostApiV1ApiResourcesSync operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1ApiResourcesSync(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostApiV1Menus(w, r)
+		siw.Handler.PostApiV1ApiResourcesSync(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -135,120 +228,87 @@ func (siw *ServerInterfaceWrapper) PostApiV1Menus(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// DeleteApiV1MenusId operation middleware
-func (siw *ServerInterfaceWrapper) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request) {
+// GetApiV1AuditLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1AuditLogs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id int32
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1AuditLogsParams
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
-	}
+	// ------------- Required query parameter "actor_id" -------------
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteApiV1MenusId(w, r, id)
-	}))
+	if paramValue := r.URL.Query().Get("actor_id"); paramValue != "" {
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "actor_id"})
+		return
 	}
 
-	handler.ServeHTTP(w, r.WithContext(ctx))
-}
-
-// GetApiV1MenusId operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1MenusId(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	var err error
-
-	// ------------- Path parameter "id" -------------
-	var id int32
-
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindQueryParameter("form", true, true, "actor_id", r.URL.Query(), &params.ActorId)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "actor_id", Err: err})
 		return
 	}
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1MenusId(w, r, id)
-	}))
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
-	}
-
-	handler.ServeHTTP(w, r.WithContext(ctx))
-}
-
-// PutApiV1MenusId operation middleware
-func (siw *ServerInterfaceWrapper) PutApiV1MenusId(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	var err error
+	// ------------- Required query parameter "entity" -------------
 
-	// ------------- Path parameter "id" -------------
-	var id int32
+	if paramValue := r.URL.Query().Get("entity"); paramValue != "" {
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "entity"})
 		return
 	}
 
-	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PutApiV1MenusId(w, r, id)
-	}))
-
-	for _, middleware := range siw.HandlerMiddlewares {
-		handler = middleware(handler)
+	err = runtime.BindQueryParameter("form", true, true, "entity", r.URL.Query(), &params.Entity)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "entity", Err: err})
+		return
 	}
 
-	handler.ServeHTTP(w, r.WithContext(ctx))
-}
+	// ------------- Required query parameter "entity_id" -------------
 
-// GetApiV1Roles operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1Roles(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if paramValue := r.URL.Query().Get("entity_id"); paramValue != "" {
 
-	var err error
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "entity_id"})
+		return
+	}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetApiV1RolesParams
+	err = runtime.BindQueryParameter("form", true, true, "entity_id", r.URL.Query(), &params.EntityId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "entity_id", Err: err})
+		return
+	}
 
-	// ------------- Required query parameter "name" -------------
+	// ------------- Required query parameter "start" -------------
 
-	if paramValue := r.URL.Query().Get("name"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("start"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "name"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "start"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "name", r.URL.Query(), &params.Name)
+	err = runtime.BindQueryParameter("form", true, true, "start", r.URL.Query(), &params.Start)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start", Err: err})
 		return
 	}
 
-	// ------------- Required query parameter "status" -------------
+	// ------------- Required query parameter "end" -------------
 
-	if paramValue := r.URL.Query().Get("status"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("end"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "status"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "end"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "status", r.URL.Query(), &params.Status)
+	err = runtime.BindQueryParameter("form", true, true, "end", r.URL.Query(), &params.End)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "end", Err: err})
 		return
 	}
 
@@ -283,7 +343,7 @@ func (siw *ServerInterfaceWrapper) GetApiV1Roles(w http.ResponseWriter, r *http.
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1Roles(w, r, params)
+		siw.Handler.GetApiV1AuditLogs(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -293,12 +353,12 @@ func (siw *ServerInterfaceWrapper) GetApiV1Roles(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// PostApiV1Roles operation middleware
-func (siw *ServerInterfaceWrapper) PostApiV1Roles(w http.ResponseWriter, r *http.Request) {
+// PostApiV1Auth2fa operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Auth2fa(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostApiV1Roles(w, r)
+		siw.Handler.PostApiV1Auth2fa(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -308,23 +368,12 @@ func (siw *ServerInterfaceWrapper) PostApiV1Roles(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// DeleteApiV1RolesId operation middleware
-func (siw *ServerInterfaceWrapper) DeleteApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+// PostApiV1AuthLogin operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1AuthLogin(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var err error
-
-	// ------------- Path parameter "id" -------------
-	var id int32
-
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
-	}
-
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteApiV1RolesId(w, r, id)
+		siw.Handler.PostApiV1AuthLogin(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -334,23 +383,27 @@ func (siw *ServerInterfaceWrapper) DeleteApiV1RolesId(w http.ResponseWriter, r *
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// GetApiV1RolesId operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+// PostApiV1AuthLogout operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1AuthLogout(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var err error
-
-	// ------------- Path parameter "id" -------------
-	var id int32
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1AuthLogout(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1AuthRefresh operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1AuthRefresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1RolesId(w, r, id)
+		siw.Handler.PostApiV1AuthRefresh(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -360,23 +413,23 @@ func (siw *ServerInterfaceWrapper) GetApiV1RolesId(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// PutApiV1RolesId operation middleware
-func (siw *ServerInterfaceWrapper) PutApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+// PostApiV1IpsIpUnlock operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1IpsIpUnlock(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id int32
+	// ------------- Path parameter "ip" -------------
+	var ip string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "ip", r.PathValue("ip"), &ip, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "ip", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PutApiV1RolesId(w, r, id)
+		siw.Handler.PostApiV1IpsIpUnlock(w, r, ip)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -386,57 +439,87 @@ func (siw *ServerInterfaceWrapper) PutApiV1RolesId(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// GetApiV1Users operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1Users(w http.ResponseWriter, r *http.Request) {
+// GetApiV1LoginLogs operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1LoginLogs(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params GetApiV1UsersParams
+	var params GetApiV1LoginLogsParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "ip" -------------
 
-	// ------------- Required query parameter "username" -------------
+	if paramValue := r.URL.Query().Get("ip"); paramValue != "" {
 
-	if paramValue := r.URL.Query().Get("username"); paramValue != "" {
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "ip"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "ip", r.URL.Query(), &params.Ip)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "ip", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "result" -------------
+
+	if paramValue := r.URL.Query().Get("result"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "username"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "result"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "username", r.URL.Query(), &params.Username)
+	err = runtime.BindQueryParameter("form", true, true, "result", r.URL.Query(), &params.Result)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "username", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "result", Err: err})
 		return
 	}
 
-	// ------------- Required query parameter "name" -------------
+	// ------------- Required query parameter "start" -------------
 
-	if paramValue := r.URL.Query().Get("name"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("start"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "name"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "start"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "name", r.URL.Query(), &params.Name)
+	err = runtime.BindQueryParameter("form", true, true, "start", r.URL.Query(), &params.Start)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "start", Err: err})
 		return
 	}
 
-	// ------------- Required query parameter "status" -------------
+	// ------------- Required query parameter "end" -------------
 
-	if paramValue := r.URL.Query().Get("status"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("end"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "status"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "end"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "status", r.URL.Query(), &params.Status)
+	err = runtime.BindQueryParameter("form", true, true, "end", r.URL.Query(), &params.End)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "end", Err: err})
 		return
 	}
 
@@ -471,7 +554,7 @@ func (siw *ServerInterfaceWrapper) GetApiV1Users(w http.ResponseWriter, r *http.
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1Users(w, r, params)
+		siw.Handler.GetApiV1LoginLogs(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -481,12 +564,12 @@ func (siw *ServerInterfaceWrapper) GetApiV1Users(w http.ResponseWriter, r *http.
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// PostApiV1Users operation middleware
-func (siw *ServerInterfaceWrapper) PostApiV1Users(w http.ResponseWriter, r *http.Request) {
+// PostApiV1Me2faDisable operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Me2faDisable(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostApiV1Users(w, r)
+		siw.Handler.PostApiV1Me2faDisable(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -496,23 +579,27 @@ func (siw *ServerInterfaceWrapper) PostApiV1Users(w http.ResponseWriter, r *http
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// DeleteApiV1UsersId operation middleware
-func (siw *ServerInterfaceWrapper) DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+// PostApiV1Me2faEnroll operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Me2faEnroll(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var err error
-
-	// ------------- Path parameter "id" -------------
-	var id int32
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1Me2faEnroll(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1Me2faVerify operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Me2faVerify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.DeleteApiV1UsersId(w, r, id)
+		siw.Handler.PostApiV1Me2faVerify(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -522,23 +609,27 @@ func (siw *ServerInterfaceWrapper) DeleteApiV1UsersId(w http.ResponseWriter, r *
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// GetApiV1UsersId operation middleware
-func (siw *ServerInterfaceWrapper) GetApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+// GetApiV1MeMenus operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1MeMenus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var err error
-
-	// ------------- Path parameter "id" -------------
-	var id int32
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1MeMenus(w, r)
+	}))
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
-		return
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
 
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1MePassword operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1MePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetApiV1UsersId(w, r, id)
+		siw.Handler.PostApiV1MePassword(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -548,18 +639,989 @@ func (siw *ServerInterfaceWrapper) GetApiV1UsersId(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
-// PutApiV1UsersId operation middleware
-func (siw *ServerInterfaceWrapper) PutApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+// GetApiV1Menus operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1Menus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var err error
 
-	// ------------- Path parameter "id" -------------
-	var id int32
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1MenusParams
 
-	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", r.URL.Query(), &params.Filter)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "filter", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "codePath" -------------
+
+	if paramValue := r.URL.Query().Get("codePath"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "codePath"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "codePath", r.URL.Query(), &params.CodePath)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "codePath", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", r.URL.Query(), &params.IncludeDeleted)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_deleted", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "pageSize" -------------
+
+	if paramValue := r.URL.Query().Get("pageSize"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pageSize"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pageSize", r.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pageSize", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "total" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "total", r.URL.Query(), &params.Total)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "total", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1Menus(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1Menus operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Menus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1Menus(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1MenusTree operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1MenusTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1MenusTreeParams
+
+	// ------------- Optional query parameter "root" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "root", r.URL.Query(), &params.Root)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "root", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1MenusTree(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteApiV1MenusId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteApiV1MenusId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteApiV1MenusId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1MenusId operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1MenusId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1MenusId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PatchApiV1MenusId operation middleware
+func (siw *ServerInterfaceWrapper) PatchApiV1MenusId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchApiV1MenusId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PutApiV1MenusId operation middleware
+func (siw *ServerInterfaceWrapper) PutApiV1MenusId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutApiV1MenusId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1MenusIdMove operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1MenusIdMove(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1MenusIdMove(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1MenusIdRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1MenusIdRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1MenusIdRestore(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1MenusBatch operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1MenusBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1MenusBatch(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1MenusReorder operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1MenusReorder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1MenusReorder(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1Roles operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1Roles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1RolesParams
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", r.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "filter", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "pageSize" -------------
+
+	if paramValue := r.URL.Query().Get("pageSize"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pageSize"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pageSize", r.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pageSize", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", r.URL.Query(), &params.IncludeDeleted)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_deleted", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "total" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "total", r.URL.Query(), &params.Total)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "total", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1Roles(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1Roles operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Roles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1Roles(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteApiV1RolesId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteApiV1RolesId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1RolesId operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1RolesId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PatchApiV1RolesId operation middleware
+func (siw *ServerInterfaceWrapper) PatchApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchApiV1RolesId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PutApiV1RolesId operation middleware
+func (siw *ServerInterfaceWrapper) PutApiV1RolesId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutApiV1RolesId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1RolesIdParents operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1RolesIdParents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1RolesIdParents(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PutApiV1RolesIdParents operation middleware
+func (siw *ServerInterfaceWrapper) PutApiV1RolesIdParents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PutApiV1RolesIdParents(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1RolesIdPermissions operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1RolesIdPermissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1RolesIdPermissions(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1RolesIdRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1RolesIdRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1RolesIdRestore(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1RolesBatch operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1RolesBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1RolesBatch(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1Sessions operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1Sessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1SessionsParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "current" -------------
+
+	if paramValue := r.URL.Query().Get("current"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "current"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "current", r.URL.Query(), &params.Current)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "current", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "pageSize" -------------
+
+	if paramValue := r.URL.Query().Get("pageSize"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pageSize"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pageSize", r.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pageSize", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1Sessions(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteApiV1SessionsId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteApiV1SessionsId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteApiV1SessionsId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1Users operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1Users(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1UsersParams
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", r.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "filter", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "pageSize" -------------
+
+	if paramValue := r.URL.Query().Get("pageSize"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pageSize"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pageSize", r.URL.Query(), &params.PageSize)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pageSize", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", r.URL.Query(), &params.IncludeDeleted)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_deleted", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "total" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "total", r.URL.Query(), &params.Total)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "total", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1Users(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1Users operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1Users(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1Users(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1UsersExport operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1UsersExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetApiV1UsersExportParams
+
+	// ------------- Optional query parameter "format" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "format", r.URL.Query(), &params.Format)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "format", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", r.URL.Query(), &params.Filter)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "filter", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", r.URL.Query(), &params.IncludeDeleted)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_deleted", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1UsersExport(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1UsersImport operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1UsersImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostApiV1UsersImportParams
+
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", r.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "dry_run", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1UsersImport(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteApiV1UsersId operation middleware
+func (siw *ServerInterfaceWrapper) DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteApiV1UsersId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// GetApiV1UsersId operation middleware
+func (siw *ServerInterfaceWrapper) GetApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetApiV1UsersId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PatchApiV1UsersId operation middleware
+func (siw *ServerInterfaceWrapper) PatchApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchApiV1UsersId(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PutApiV1UsersId operation middleware
+func (siw *ServerInterfaceWrapper) PutApiV1UsersId(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
 		return
 	}
 
@@ -574,6 +1636,125 @@ func (siw *ServerInterfaceWrapper) PutApiV1UsersId(w http.ResponseWriter, r *htt
 	handler.ServeHTTP(w, r.WithContext(ctx))
 }
 
+// PostApiV1UsersIdPasswordReset operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1UsersIdPasswordReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1UsersIdPasswordReset(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1UsersIdRestore operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1UsersIdRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1UsersIdRestore(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// DeleteApiV1UsersIdSessions operation middleware
+func (siw *ServerInterfaceWrapper) DeleteApiV1UsersIdSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteApiV1UsersIdSessions(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1UsersIdUnlock operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1UsersIdUnlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id int32
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1UsersIdUnlock(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// PostApiV1UsersBatch operation middleware
+func (siw *ServerInterfaceWrapper) PostApiV1UsersBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostApiV1UsersBatch(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -688,21 +1869,57 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 		ErrorHandlerFunc:   options.ErrorHandlerFunc,
 	}
 
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/api-resources", wrapper.GetApiV1ApiResources)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/api-resources/sync", wrapper.PostApiV1ApiResourcesSync)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/audit-logs", wrapper.GetApiV1AuditLogs)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/auth/2fa", wrapper.PostApiV1Auth2fa)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/auth/login", wrapper.PostApiV1AuthLogin)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/auth/logout", wrapper.PostApiV1AuthLogout)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/auth/refresh", wrapper.PostApiV1AuthRefresh)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/ips/{ip}/unlock", wrapper.PostApiV1IpsIpUnlock)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/login-logs", wrapper.GetApiV1LoginLogs)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/me/2fa/disable", wrapper.PostApiV1Me2faDisable)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/me/2fa/enroll", wrapper.PostApiV1Me2faEnroll)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/me/2fa/verify", wrapper.PostApiV1Me2faVerify)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/me/menus", wrapper.GetApiV1MeMenus)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/me/password", wrapper.PostApiV1MePassword)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/menus", wrapper.GetApiV1Menus)
 	m.HandleFunc("POST "+options.BaseURL+"/api/v1/menus", wrapper.PostApiV1Menus)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/menus/tree", wrapper.GetApiV1MenusTree)
 	m.HandleFunc("DELETE "+options.BaseURL+"/api/v1/menus/{id}", wrapper.DeleteApiV1MenusId)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/menus/{id}", wrapper.GetApiV1MenusId)
+	m.HandleFunc("PATCH "+options.BaseURL+"/api/v1/menus/{id}", wrapper.PatchApiV1MenusId)
 	m.HandleFunc("PUT "+options.BaseURL+"/api/v1/menus/{id}", wrapper.PutApiV1MenusId)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/menus/{id}/move", wrapper.PostApiV1MenusIdMove)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/menus/{id}/restore", wrapper.PostApiV1MenusIdRestore)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/menus:batch", wrapper.PostApiV1MenusBatch)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/menus:reorder", wrapper.PostApiV1MenusReorder)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/roles", wrapper.GetApiV1Roles)
 	m.HandleFunc("POST "+options.BaseURL+"/api/v1/roles", wrapper.PostApiV1Roles)
 	m.HandleFunc("DELETE "+options.BaseURL+"/api/v1/roles/{id}", wrapper.DeleteApiV1RolesId)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/roles/{id}", wrapper.GetApiV1RolesId)
+	m.HandleFunc("PATCH "+options.BaseURL+"/api/v1/roles/{id}", wrapper.PatchApiV1RolesId)
 	m.HandleFunc("PUT "+options.BaseURL+"/api/v1/roles/{id}", wrapper.PutApiV1RolesId)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/roles/{id}/parents", wrapper.GetApiV1RolesIdParents)
+	m.HandleFunc("PUT "+options.BaseURL+"/api/v1/roles/{id}/parents", wrapper.PutApiV1RolesIdParents)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/roles/{id}/permissions", wrapper.GetApiV1RolesIdPermissions)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/roles/{id}/restore", wrapper.PostApiV1RolesIdRestore)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/roles:batch", wrapper.PostApiV1RolesBatch)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/sessions", wrapper.GetApiV1Sessions)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/v1/sessions/{id}", wrapper.DeleteApiV1SessionsId)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/users", wrapper.GetApiV1Users)
 	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users", wrapper.PostApiV1Users)
+	m.HandleFunc("GET "+options.BaseURL+"/api/v1/users/export", wrapper.GetApiV1UsersExport)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users/import", wrapper.PostApiV1UsersImport)
 	m.HandleFunc("DELETE "+options.BaseURL+"/api/v1/users/{id}", wrapper.DeleteApiV1UsersId)
 	m.HandleFunc("GET "+options.BaseURL+"/api/v1/users/{id}", wrapper.GetApiV1UsersId)
+	m.HandleFunc("PATCH "+options.BaseURL+"/api/v1/users/{id}", wrapper.PatchApiV1UsersId)
 	m.HandleFunc("PUT "+options.BaseURL+"/api/v1/users/{id}", wrapper.PutApiV1UsersId)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users/{id}/password-reset", wrapper.PostApiV1UsersIdPasswordReset)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users/{id}/restore", wrapper.PostApiV1UsersIdRestore)
+	m.HandleFunc("DELETE "+options.BaseURL+"/api/v1/users/{id}/sessions", wrapper.DeleteApiV1UsersIdSessions)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users/{id}/unlock", wrapper.PostApiV1UsersIdUnlock)
+	m.HandleFunc("POST "+options.BaseURL+"/api/v1/users:batch", wrapper.PostApiV1UsersBatch)
 
 	return m
 }
@@ -3,6 +3,34 @@
 // Code generated by github.com/deepmap/oapi-codegen/v2 version v2.1.1-0.20240331212514-80f0b978ef16 DO NOT EDIT.
 package controller
 
+// Defines values for AuditLogAction.
+const (
+	Create  AuditLogAction = "create"
+	Delete  AuditLogAction = "delete"
+	Restore AuditLogAction = "restore"
+	Update  AuditLogAction = "update"
+)
+
+// Defines values for BatchOperationOp.
+const (
+	BatchCreate BatchOperationOp = "create"
+	BatchDelete BatchOperationOp = "delete"
+	BatchPatch  BatchOperationOp = "patch"
+	BatchUpdate BatchOperationOp = "update"
+)
+
+// Defines values for BatchRequestMode.
+const (
+	Atomic     BatchRequestMode = "atomic"
+	BestEffort BatchRequestMode = "best_effort"
+)
+
+// Defines values for LoginLogResult.
+const (
+	LoginFailure LoginLogResult = "failure"
+	LoginSuccess LoginLogResult = "success"
+)
+
 // Defines values for MenuStatus.
 const (
 	MenuStatusDisabled MenuStatus = "disabled"
@@ -27,24 +55,167 @@ const (
 	Frozen    UserStatus = "frozen"
 )
 
+// Defines values for GetApiV1UsersExportParamsFormat.
+const (
+	Csv  GetApiV1UsersExportParamsFormat = "csv"
+	Xlsx GetApiV1UsersExportParamsFormat = "xlsx"
+)
+
+// ApiResource defines model for ApiResource.
+type ApiResource struct {
+	Created     string `json:"created"`
+	Description string `json:"description"`
+	Id          int32  `json:"id"`
+	Method      string `json:"method"`
+	OperationId string `json:"operation_id"`
+	Path        string `json:"path"`
+	Tag         string `json:"tag"`
+	Updated     string `json:"updated"`
+}
+
+// ApiResourceSync defines model for ApiResourceSync.
+type ApiResourceSync struct {
+	Added   int32 `json:"added"`
+	Removed int32 `json:"removed"`
+
+	// Stale the resources of menus no route matches
+	Stale []Resource `json:"stale"`
+
+	// Ungranted the routes no resource of a menu matches
+	Ungranted []ApiResource `json:"ungranted"`
+}
+
+// AuditLog defines model for AuditLog.
+type AuditLog struct {
+	Action    AuditLogAction         `json:"action"`
+	ActorId   int32                  `json:"actor_id"`
+	After     map[string]interface{} `json:"after"`
+	Before    map[string]interface{} `json:"before"`
+	Created   string                 `json:"created"`
+	Entity    string                 `json:"entity"`
+	EntityId  int32                  `json:"entity_id"`
+	Id        int32                  `json:"id"`
+	Ip        string                 `json:"ip"`
+	RequestId string                 `json:"request_id"`
+}
+
+// AuditLogAction defines model for AuditLog.Action.
+type AuditLogAction string
+
+// BatchOperation defines model for BatchOperation.
+type BatchOperation struct {
+	// Body the entity to create or update, or the merge patch
+	Body map[string]interface{} `json:"body,omitempty"`
+
+	// Id the entity to update, patch or delete
+	Id int32 `json:"id,omitempty" validate:"min=0"`
+
+	// IfMatch the ETag the entity must have, as the If-Match header
+	IfMatch string           `json:"if_match,omitempty"`
+	Op      BatchOperationOp `json:"op" validate:"oneof=create update patch delete"`
+}
+
+// BatchOperationOp defines model for BatchOperation.Op.
+type BatchOperationOp string
+
+// BatchRequest defines model for BatchRequest.
+type BatchRequest struct {
+	// Mode atomic by default
+	Mode       BatchRequestMode `json:"mode,omitempty" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" validate:"min=1,dive"`
+}
+
+// BatchRequestMode atomic by default
+type BatchRequestMode string
+
+// BatchResponse defines model for BatchResponse.
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult defines model for BatchResult.
+type BatchResult struct {
+	// Code the errcode, 0 on success
+	Code    int32         `json:"code"`
+	Details []ErrorDetail `json:"details,omitempty"`
+	Etag    string        `json:"etag,omitempty"`
+	Id      int32         `json:"id,omitempty"`
+	Message string        `json:"message,omitempty"`
+
+	// Status the HTTP status the single request would have got
+	Status int32 `json:"status"`
+}
+
 // Error defines model for Error.
 type Error struct {
-	Code    int32  `json:"code"`
-	Message string `json:"message"`
+	Code      int32         `json:"code"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	Message   string        `json:"message"`
+	RequestId string        `json:"request_id,omitempty"`
+}
+
+// ErrorDetail defines model for ErrorDetail.
+type ErrorDetail struct {
+	Field string `json:"field"`
+	Param string `json:"param"`
+	Tag   string `json:"tag"`
 }
 
+// ImportError defines model for ImportError.
+type ImportError struct {
+	Code    int32         `json:"code"`
+	Details []ErrorDetail `json:"details,omitempty"`
+	Message string        `json:"message"`
+
+	// Row the row of the sheet, the header is row 1
+	Row int32 `json:"row"`
+}
+
+// ImportResult defines model for ImportResult.
+type ImportResult struct {
+	// Committed false on a dry run or when a row failed
+	Committed bool          `json:"committed"`
+	Created   int32         `json:"created"`
+	Errors    []ImportError `json:"errors,omitempty"`
+	Updated   int32         `json:"updated"`
+}
+
+// Login defines model for Login.
+type Login struct {
+	Password string `json:"password" validate:"required,max=64"`
+	Username string `json:"username" validate:"required,max=64"`
+}
+
+// LoginLog defines model for LoginLog.
+type LoginLog struct {
+	Created   string         `json:"created"`
+	Id        int32          `json:"id"`
+	Ip        string         `json:"ip"`
+	Reason    string         `json:"reason"`
+	Result    LoginLogResult `json:"result"`
+	UserAgent string         `json:"user_agent"`
+	UserId    int32          `json:"user_id"`
+	Username  string         `json:"username"`
+}
+
+// LoginLogResult defines model for LoginLog.Result.
+type LoginLogResult string
+
 // Menu defines model for Menu.
 type Menu struct {
+	Children    []Menu     `json:"children,omitempty"`
 	Code        string     `json:"code" validate:"max=64"`
 	Created     string     `json:"created"`
+	DeletedAt   string     `json:"deleted_at,omitempty"`
 	Description string     `json:"description" validate:"max=1024"`
 	Id          *int32     `json:"id,omitempty"`
 	Name        string     `json:"name" validate:"max=64"`
-	ParentId    int32      `json:"parent_id" validate:"min=1"`
+	ParentId    int32      `json:"parent_id" validate:"min=0"`
 	ParentPath  string     `json:"parent_path" validate:"max=1024"`
 	Path        string     `json:"path" validate:"max=1024"`
 	Property    string     `json:"property" validate:"max=64"`
-	Resource    []Resource `json:"resource"`
+	Resource    []Resource `json:"resource" validate:"dive"`
 	Sequence    int16      `json:"sequence" validate:"min=1"`
 	Status      MenuStatus `json:"status" validate:"oneof=enabled disabled"`
 	Type        MenuType   `json:"type" validate:"oneof=page button"`
@@ -57,12 +228,60 @@ type MenuStatus string
 // MenuType defines model for Menu.Type.
 type MenuType string
 
+// MenuMove defines model for MenuMove.
+type MenuMove struct {
+	ParentId int32 `json:"parent_id" validate:"min=0"`
+}
+
+// MenuPage defines model for MenuPage.
+type MenuPage struct {
+	Items []Menu `json:"items"`
+
+	// NextCursor empty on the last page
+	NextCursor string `json:"next_cursor"`
+
+	// Total only present when requested
+	Total *int64 `json:"total,omitempty"`
+}
+
+// MenuReorder defines model for MenuReorder.
+type MenuReorder struct {
+	// Id the children in their new order
+	Id       []int32 `json:"id" validate:"max=32767,dive,min=1"`
+	ParentId int32   `json:"parent_id" validate:"min=0"`
+}
+
+// PasswordChange defines model for PasswordChange.
+type PasswordChange struct {
+	NewPassword string `json:"new_password" validate:"required,max=64,password_length,password_class,password_banned"`
+	OldPassword string `json:"old_password" validate:"required,max=64"`
+}
+
+// PasswordReset defines model for PasswordReset.
+type PasswordReset struct {
+	Password string `json:"password" validate:"required,max=64,password_length,password_class,password_banned"`
+}
+
+// RecoveryCodes defines model for RecoveryCodes.
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshToken defines model for RefreshToken.
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 // Resource defines model for Resource.
 type Resource struct {
 	Created string `json:"created"`
 	Id      *int32 `json:"id,omitempty"`
 	MenuId  int32  `json:"menu_id"`
-	Method  string `json:"method" validate:"max=64"`
+
+	// Method comma separated methods such as GET,POST, or ANY for all of them
+	Method string `json:"method" validate:"max=64"`
+
+	// Path ServeMux style pattern such as /api/v1/users/{id}, {name} and * match one segment, a trailing {name...} the remaining ones and ** any number of them; it has to match a registered route
 	Path    string `json:"path" validate:"max=1024"`
 	Updated string `json:"updated"`
 }
@@ -71,13 +290,17 @@ type Resource struct {
 type Role struct {
 	Code        string     `json:"code" validate:"max=64"`
 	Created     string     `json:"created"`
+	DeletedAt   string     `json:"deleted_at,omitempty"`
 	Description string     `json:"description" validate:"max=1024"`
 	Id          *int32     `json:"id,omitempty"`
 	Menu        []RoleMenu `json:"menu"`
 	Name        string     `json:"name" validate:"max=64"`
-	Sequence    int16      `json:"sequence" validate:"min=1"`
-	Status      RoleStatus `json:"status" validate:"oneof=enabled disabled"`
-	Updated     string     `json:"updated"`
+
+	// Require2fa members have to enable two factor authentication
+	Require2fa bool       `json:"require_2fa,omitempty"`
+	Sequence   int16      `json:"sequence" validate:"min=1"`
+	Status     RoleStatus `json:"status" validate:"oneof=enabled disabled"`
+	Updated    string     `json:"updated"`
 }
 
 // RoleStatus defines model for Role.Status.
@@ -92,24 +315,119 @@ type RoleMenu struct {
 	Updated string `json:"updated"`
 }
 
+// RolePage defines model for RolePage.
+type RolePage struct {
+	Items []Role `json:"items"`
+
+	// NextCursor empty on the last page
+	NextCursor string `json:"next_cursor"`
+
+	// Total only present when requested
+	Total *int64 `json:"total,omitempty"`
+}
+
+// RoleParentList defines model for RoleParentList.
+type RoleParentList struct {
+	ParentId []int32 `json:"parent_id" validate:"max=64,dive,min=1"`
+}
+
+// RolePermission defines model for RolePermission.
+type RolePermission struct {
+	// AncestorId the enabled ancestors the role inherits from
+	AncestorId []int32 `json:"ancestor_id"`
+
+	// Menu the granted menus nested under their parent, with their resources
+	Menu []Menu `json:"menu"`
+}
+
+// Session defines model for Session.
+type Session struct {
+	Created string `json:"created"`
+
+	// Current whether it is the session of the request
+	Current   bool   `json:"current"`
+	Expired   string `json:"expired"`
+	Id        int32  `json:"id"`
+	Ip        string `json:"ip"`
+	LastSeen  string `json:"last_seen"`
+	UserAgent string `json:"user_agent"`
+	UserId    int32  `json:"user_id"`
+}
+
+// TOTPCode defines model for TOTPCode.
+type TOTPCode struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// TOTPDisable defines model for TOTPDisable.
+type TOTPDisable struct {
+	// Code a totp code or an unused recovery code
+	Code     string `json:"code" validate:"required,max=64"`
+	Password string `json:"password" validate:"required,max=64"`
+}
+
+// TOTPEnrollment defines model for TOTPEnrollment.
+type TOTPEnrollment struct {
+	OtpauthUri string `json:"otpauth_uri"`
+	Secret     string `json:"secret"`
+}
+
+// Token defines model for Token.
+type Token struct {
+	AccessToken string `json:"access_token"`
+
+	// Challenge set instead of the tokens when the user has two factor authentication, post it with a code to /api/v1/auth/2fa
+	Challenge string `json:"challenge,omitempty"`
+	ExpiresIn int32  `json:"expires_in"`
+
+	// MustChangePassword only the password change is allowed until it is done
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	RefreshToken       string `json:"refresh_token"`
+	TokenType          string `json:"token_type"`
+}
+
+// TwoFactorLogin defines model for TwoFactorLogin.
+type TwoFactorLogin struct {
+	Challenge string `json:"challenge" validate:"required"`
+
+	// Code a totp code or an unused recovery code
+	Code string `json:"code" validate:"required,max=64"`
+}
+
 // User defines model for User.
 type User struct {
-	Created  string     `json:"created"`
-	Email    string     `json:"email" validate:"email"`
-	Id       *int32     `json:"id,omitempty"`
-	Name     string     `json:"name" validate:"max=64"`
-	Password string     `json:"password" validate:"max=64"`
-	Phone    string     `json:"phone" validate:"e164"`
-	Remark   string     `json:"remark" validate:"max=1024"`
-	Role     []UserRole `json:"role"`
-	Status   UserStatus `json:"status" validate:"oneof=activated frozen"`
-	Updated  string     `json:"updated"`
-	Username string     `json:"username" validate:"max=64"`
+	Created            string `json:"created"`
+	DeletedAt          string `json:"deleted_at,omitempty"`
+	Email              string `json:"email" validate:"email"`
+	Id                 *int32 `json:"id,omitempty"`
+	MustChangePassword bool   `json:"must_change_password,omitempty"`
+	Name               string `json:"name" validate:"max=64"`
+
+	// Password write only, left unchanged when empty on update
+	Password    string     `json:"password" validate:"omitempty,max=64,password_length,password_class,password_banned"`
+	Phone       string     `json:"phone" validate:"e164"`
+	Remark      string     `json:"remark" validate:"max=1024"`
+	Role        []UserRole `json:"role"`
+	Status      UserStatus `json:"status" validate:"oneof=activated frozen"`
+	TotpEnabled bool       `json:"totp_enabled,omitempty"`
+	Updated     string     `json:"updated"`
+	Username    string     `json:"username" validate:"max=64"`
 }
 
 // UserStatus defines model for User.Status.
 type UserStatus string
 
+// UserPage defines model for UserPage.
+type UserPage struct {
+	Items []User `json:"items"`
+
+	// NextCursor empty on the last page
+	NextCursor string `json:"next_cursor"`
+
+	// Total only present when requested
+	Total *int64 `json:"total,omitempty"`
+}
+
 // UserRole defines model for UserRole.
 type UserRole struct {
 	Created string `json:"created"`
@@ -119,43 +437,212 @@ type UserRole struct {
 	UserId  *int32 `json:"user_id,omitempty"`
 }
 
+// Filter defines model for Filter.
+type Filter = []string
+
+// Sort defines model for Sort.
+type Sort = string
+
+// BadRequest defines model for BadRequest.
+type BadRequest = Error
+
+// Conflict defines model for Conflict.
+type Conflict = Error
+
+// Forbidden defines model for Forbidden.
+type Forbidden = Error
+
+// InternalServerError defines model for InternalServerError.
+type InternalServerError = Error
+
+// NotFound defines model for NotFound.
+type NotFound = Error
+
+// PreconditionFailed defines model for PreconditionFailed.
+type PreconditionFailed = Error
+
+// PreconditionRequired defines model for PreconditionRequired.
+type PreconditionRequired = Error
+
+// TooManyRequests defines model for TooManyRequests.
+type TooManyRequests = Error
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = Error
+
+// GetApiV1ApiResourcesParams defines parameters for GetApiV1ApiResources.
+type GetApiV1ApiResourcesParams struct {
+	Tag string `form:"tag" json:"tag"`
+}
+
+// GetApiV1AuditLogsParams defines parameters for GetApiV1AuditLogs.
+type GetApiV1AuditLogsParams struct {
+	ActorId  int32  `form:"actor_id" json:"actor_id"`
+	Entity   string `form:"entity" json:"entity"`
+	EntityId int32  `form:"entity_id" json:"entity_id"`
+	Start    string `form:"start" json:"start"`
+	End      string `form:"end" json:"end"`
+	Current  int32  `form:"current" json:"current"`
+	PageSize int32  `form:"pageSize" json:"pageSize"`
+}
+
+// GetApiV1LoginLogsParams defines parameters for GetApiV1LoginLogs.
+type GetApiV1LoginLogsParams struct {
+	UserId   int32  `form:"user_id" json:"user_id"`
+	Ip       string `form:"ip" json:"ip"`
+	Result   string `form:"result" json:"result"`
+	Start    string `form:"start" json:"start"`
+	End      string `form:"end" json:"end"`
+	Current  int32  `form:"current" json:"current"`
+	PageSize int32  `form:"pageSize" json:"pageSize"`
+}
+
 // GetApiV1MenusParams defines parameters for GetApiV1Menus.
 type GetApiV1MenusParams struct {
-	CodePath string `form:"codePath" json:"codePath"`
-	Name     string `form:"name" json:"name"`
+	// Filter repeated field:op:value conditions that all have to hold, op is eq, ne, in or like on text fields, eq, ne or in on sequence and gte or lte on created and updated, in takes comma separated values and like matches a substring, case insensitive
+	Filter Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort comma separated fields, a leading - sorts descending, for example -created,username
+	Sort           Sort   `form:"sort,omitempty" json:"sort,omitempty"`
+	CodePath       string `form:"codePath" json:"codePath"`
+	IncludeDeleted bool   `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
+	Cursor         string `form:"cursor,omitempty" json:"cursor,omitempty"`
+	PageSize       int32  `form:"pageSize" json:"pageSize"`
+	Total          bool   `form:"total,omitempty" json:"total,omitempty"`
+}
+
+// GetApiV1MenusTreeParams defines parameters for GetApiV1MenusTree.
+type GetApiV1MenusTreeParams struct {
+	Root int32 `form:"root,omitempty" json:"root,omitempty"`
 }
 
+// PatchApiV1MenusIdApplicationMergePatchPlusJSONBody defines parameters for PatchApiV1MenusId.
+type PatchApiV1MenusIdApplicationMergePatchPlusJSONBody = map[string]interface{}
+
 // GetApiV1RolesParams defines parameters for GetApiV1Roles.
 type GetApiV1RolesParams struct {
-	Name     string `form:"name" json:"name"`
-	Status   string `form:"status" json:"status"`
-	Current  int32  `form:"current" json:"current"`
-	PageSize int32  `form:"pageSize" json:"pageSize"`
+	// Filter repeated field:op:value conditions that all have to hold, op is eq, ne, in or like on text fields, eq, ne or in on sequence and gte or lte on created and updated, in takes comma separated values and like matches a substring, case insensitive
+	Filter Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort comma separated fields, a leading - sorts descending, for example -created,username
+	Sort           Sort   `form:"sort,omitempty" json:"sort,omitempty"`
+	Cursor         string `form:"cursor,omitempty" json:"cursor,omitempty"`
+	PageSize       int32  `form:"pageSize" json:"pageSize"`
+	IncludeDeleted bool   `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
+	Total          bool   `form:"total,omitempty" json:"total,omitempty"`
+}
+
+// PatchApiV1RolesIdApplicationMergePatchPlusJSONBody defines parameters for PatchApiV1RolesId.
+type PatchApiV1RolesIdApplicationMergePatchPlusJSONBody = map[string]interface{}
+
+// GetApiV1SessionsParams defines parameters for GetApiV1Sessions.
+type GetApiV1SessionsParams struct {
+	UserId   int32 `form:"user_id" json:"user_id"`
+	Current  int32 `form:"current" json:"current"`
+	PageSize int32 `form:"pageSize" json:"pageSize"`
 }
 
 // GetApiV1UsersParams defines parameters for GetApiV1Users.
 type GetApiV1UsersParams struct {
-	Username string `form:"username" json:"username"`
-	Name     string `form:"name" json:"name"`
-	Status   string `form:"status" json:"status"`
-	Current  int32  `form:"current" json:"current"`
-	PageSize int32  `form:"pageSize" json:"pageSize"`
+	// Filter repeated field:op:value conditions that all have to hold, op is eq, ne, in or like on text fields, eq, ne or in on sequence and gte or lte on created and updated, in takes comma separated values and like matches a substring, case insensitive
+	Filter Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort comma separated fields, a leading - sorts descending, for example -created,username
+	Sort           Sort   `form:"sort,omitempty" json:"sort,omitempty"`
+	Cursor         string `form:"cursor,omitempty" json:"cursor,omitempty"`
+	PageSize       int32  `form:"pageSize" json:"pageSize"`
+	IncludeDeleted bool   `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
+	Total          bool   `form:"total,omitempty" json:"total,omitempty"`
+}
+
+// GetApiV1UsersExportParams defines parameters for GetApiV1UsersExport.
+type GetApiV1UsersExportParams struct {
+	Format GetApiV1UsersExportParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Filter repeated field:op:value conditions that all have to hold, op is eq, ne, in or like on text fields, eq, ne or in on sequence and gte or lte on created and updated, in takes comma separated values and like matches a substring, case insensitive
+	Filter Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Sort comma separated fields, a leading - sorts descending, for example -created,username
+	Sort           Sort `form:"sort,omitempty" json:"sort,omitempty"`
+	IncludeDeleted bool `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
+}
+
+// GetApiV1UsersExportParamsFormat defines parameters for GetApiV1UsersExport.
+type GetApiV1UsersExportParamsFormat string
+
+// PostApiV1UsersImportParams defines parameters for PostApiV1UsersImport.
+type PostApiV1UsersImportParams struct {
+	DryRun bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
 }
 
+// PatchApiV1UsersIdApplicationMergePatchPlusJSONBody defines parameters for PatchApiV1UsersId.
+type PatchApiV1UsersIdApplicationMergePatchPlusJSONBody = map[string]interface{}
+
+// PostApiV1Auth2faJSONRequestBody defines body for PostApiV1Auth2fa for application/json ContentType.
+type PostApiV1Auth2faJSONRequestBody = TwoFactorLogin
+
+// PostApiV1AuthLoginJSONRequestBody defines body for PostApiV1AuthLogin for application/json ContentType.
+type PostApiV1AuthLoginJSONRequestBody = Login
+
+// PostApiV1AuthLogoutJSONRequestBody defines body for PostApiV1AuthLogout for application/json ContentType.
+type PostApiV1AuthLogoutJSONRequestBody = RefreshToken
+
+// PostApiV1AuthRefreshJSONRequestBody defines body for PostApiV1AuthRefresh for application/json ContentType.
+type PostApiV1AuthRefreshJSONRequestBody = RefreshToken
+
+// PostApiV1Me2faDisableJSONRequestBody defines body for PostApiV1Me2faDisable for application/json ContentType.
+type PostApiV1Me2faDisableJSONRequestBody = TOTPDisable
+
+// PostApiV1Me2faVerifyJSONRequestBody defines body for PostApiV1Me2faVerify for application/json ContentType.
+type PostApiV1Me2faVerifyJSONRequestBody = TOTPCode
+
+// PostApiV1MePasswordJSONRequestBody defines body for PostApiV1MePassword for application/json ContentType.
+type PostApiV1MePasswordJSONRequestBody = PasswordChange
+
 // PostApiV1MenusJSONRequestBody defines body for PostApiV1Menus for application/json ContentType.
 type PostApiV1MenusJSONRequestBody = Menu
 
+// PatchApiV1MenusIdApplicationMergePatchPlusJSONRequestBody defines body for PatchApiV1MenusId for application/merge-patch+json ContentType.
+type PatchApiV1MenusIdApplicationMergePatchPlusJSONRequestBody = PatchApiV1MenusIdApplicationMergePatchPlusJSONBody
+
 // PutApiV1MenusIdJSONRequestBody defines body for PutApiV1MenusId for application/json ContentType.
 type PutApiV1MenusIdJSONRequestBody = Menu
 
+// PostApiV1MenusIdMoveJSONRequestBody defines body for PostApiV1MenusIdMove for application/json ContentType.
+type PostApiV1MenusIdMoveJSONRequestBody = MenuMove
+
+// PostApiV1MenusBatchJSONRequestBody defines body for PostApiV1MenusBatch for application/json ContentType.
+type PostApiV1MenusBatchJSONRequestBody = BatchRequest
+
+// PostApiV1MenusReorderJSONRequestBody defines body for PostApiV1MenusReorder for application/json ContentType.
+type PostApiV1MenusReorderJSONRequestBody = MenuReorder
+
 // PostApiV1RolesJSONRequestBody defines body for PostApiV1Roles for application/json ContentType.
 type PostApiV1RolesJSONRequestBody = Role
 
+// PatchApiV1RolesIdApplicationMergePatchPlusJSONRequestBody defines body for PatchApiV1RolesId for application/merge-patch+json ContentType.
+type PatchApiV1RolesIdApplicationMergePatchPlusJSONRequestBody = PatchApiV1RolesIdApplicationMergePatchPlusJSONBody
+
 // PutApiV1RolesIdJSONRequestBody defines body for PutApiV1RolesId for application/json ContentType.
 type PutApiV1RolesIdJSONRequestBody = Role
 
+// PutApiV1RolesIdParentsJSONRequestBody defines body for PutApiV1RolesIdParents for application/json ContentType.
+type PutApiV1RolesIdParentsJSONRequestBody = RoleParentList
+
+// PostApiV1RolesBatchJSONRequestBody defines body for PostApiV1RolesBatch for application/json ContentType.
+type PostApiV1RolesBatchJSONRequestBody = BatchRequest
+
 // PostApiV1UsersJSONRequestBody defines body for PostApiV1Users for application/json ContentType.
 type PostApiV1UsersJSONRequestBody = User
 
+// PatchApiV1UsersIdApplicationMergePatchPlusJSONRequestBody defines body for PatchApiV1UsersId for application/merge-patch+json ContentType.
+type PatchApiV1UsersIdApplicationMergePatchPlusJSONRequestBody = PatchApiV1UsersIdApplicationMergePatchPlusJSONBody
+
 // PutApiV1UsersIdJSONRequestBody defines body for PutApiV1UsersId for application/json ContentType.
 type PutApiV1UsersIdJSONRequestBody = User
+
+// PostApiV1UsersIdPasswordResetJSONRequestBody defines body for PostApiV1UsersIdPasswordReset for application/json ContentType.
+type PostApiV1UsersIdPasswordResetJSONRequestBody = PasswordReset
+
+// PostApiV1UsersBatchJSONRequestBody defines body for PostApiV1UsersBatch for application/json ContentType.
+type PostApiV1UsersBatchJSONRequestBody = BatchRequest
@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultPasswordMinLength   = 8
+	defaultPasswordCharClasses = 3
+	defaultPasswordHistory     = 5
+)
+
+//go:embed banned_password.txt
+var bannedPasswordList string
+
+// bannedPassword holds the common passwords that are refused, lower cased.
+var bannedPassword = func() map[string]bool {
+	banned := make(map[string]bool)
+	for _, password := range strings.Fields(bannedPasswordList) {
+		banned[strings.ToLower(password)] = true
+	}
+	return banned
+}()
+
+// a new user needs a password, updates keep the current one without
+var (
+	errPasswordRequired = errors.New("password required")
+	passwordRequired    = []ErrorDetail{{Field: "password", Tag: "required"}}
+)
+
+func passwordMinLength() int {
+	length := config.Raw.Int("PASSWORD_MIN_LENGTH")
+	if length <= 0 {
+		return defaultPasswordMinLength
+	}
+	return length
+}
+
+// passwordCharClasses is how many of lower case, upper case, digit and
+// symbol a password has to mix.
+func passwordCharClasses() int {
+	classes := config.Raw.Int("PASSWORD_CHAR_CLASSES")
+	if classes <= 0 {
+		return defaultPasswordCharClasses
+	}
+	return min(classes, 4)
+}
+
+// passwordHistory is how many of the last passwords, the current one
+// included, cannot be used again.
+func passwordHistory() int {
+	history := config.Raw.Int("PASSWORD_HISTORY")
+	if history <= 0 {
+		return defaultPasswordHistory
+	}
+	return history
+}
+
+func bcryptCost() int {
+	cost := config.Raw.Int("BCRYPT_COST")
+	if cost <= 0 {
+		return bcrypt.DefaultCost
+	}
+	return min(max(cost, bcrypt.MinCost), bcrypt.MaxCost)
+}
+
+// registerPasswordValidation adds the password policy as validate tags, one
+// per rule, so that the error detail tells which one failed.
+func registerPasswordValidation(v *validator.Validate) {
+	_ = v.RegisterValidation("password_length", func(fl validator.FieldLevel) bool {
+		return utf8.RuneCountInString(fl.Field().String()) >= passwordMinLength()
+	})
+	_ = v.RegisterValidation("password_class", func(fl validator.FieldLevel) bool {
+		return charClasses(fl.Field().String()) >= passwordCharClasses()
+	})
+	_ = v.RegisterValidation("password_banned", func(fl validator.FieldLevel) bool {
+		return !bannedPassword[strings.ToLower(fl.Field().String())]
+	})
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// rotatePassword reports whether password is one of the last passwords of
+// the user. When it is not, the current hash moves into the history, so the
+// caller only has to write the new one.
+func rotatePassword(ctx context.Context, query *model.Queries, userID int32, password string) (bool, error) {
+	user, err := query.GetUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	listParams := model.ListPasswordHistoryByUserIDParams{
+		UserID: userID,
+		Limit:  int32(passwordHistory() - 1),
+	}
+	historyList, err := query.ListPasswordHistoryByUserID(ctx, listParams)
+	if err != nil {
+		return false, err
+	}
+	for _, h := range append([]string{user.Password}, historyList...) {
+		if bcrypt.CompareHashAndPassword([]byte(h), []byte(password)) == nil {
+			return true, nil
+		}
+	}
+
+	createParams := model.CreatePasswordHistoryParams{
+		UserID:   userID,
+		Password: user.Password,
+		Created:  pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	err = query.CreatePasswordHistory(ctx, createParams)
+	if err != nil {
+		return false, err
+	}
+
+	trimParams := model.TrimPasswordHistoryParams{
+		UserID: userID,
+		Limit:  int32(passwordHistory() - 1),
+	}
+	return false, query.TrimPasswordHistory(ctx, trimParams)
+}
+
+// passwordReused writes Validate for a password found in the history.
+func passwordReused(w http.ResponseWriter, field string) {
+	writeErr(w, errcode.Validate, []ErrorDetail{{Field: field, Tag: "password_history"}})
+}
+
+// needsRehash reports whether h was hashed with another cost than the one
+// configured now, it is rehashed on the next login.
+func needsRehash(h string) bool {
+	cost, err := bcrypt.Cost([]byte(h))
+	return err == nil && cost != bcryptCost()
+}
+
+func (a *API) PostApiV1MePassword(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		Err(w, errcode.TokenInvalid)
+		return
+	}
+
+	var req PasswordChange
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	_, err = query.LockUserVersion(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	userByGet, err := query.GetUser(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(userByGet.Password), []byte(req.OldPassword))
+	if err != nil {
+		Err(w, errcode.PasswordIncorrect)
+		return
+	}
+
+	reused, err := rotatePassword(ctx, query, userID, req.NewPassword)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if reused {
+		passwordReused(w, "new_password")
+		return
+	}
+
+	userBySet, err := setPassword(ctx, query, userID, req.NewPassword, false)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Update, auditUser, userID, userResp(userByGet), userResp(userBySet))
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	// every session ends, the current device goes on with a new one
+	resp, err := startSession(r, query, userID, time.Now())
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1UsersIdPasswordReset(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req PasswordReset
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	_, err = query.LockUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	userByGet, err := userWithRole(ctx, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	reused, err := rotatePassword(ctx, query, id, req.Password)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if reused {
+		passwordReused(w, "password")
+		return
+	}
+
+	userBySet, err := setPassword(ctx, query, id, req.Password, true)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	resp := userResp(userBySet)
+	resp.Role = userByGet.Role
+
+	err = writeAudit(r, query, Update, auditUser, id, userByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	w.Header().Set("ETag", etag(userBySet.Version))
+	encode(w, resp)
+}
+
+// setPassword writes the new password and signs the user out everywhere,
+// mustChange keeps every other route closed until the user changes it.
+func setPassword(ctx context.Context, query *model.Queries, userID int32, password string, mustChange bool) (model.AppUser, error) {
+	h, err := hash(password)
+	if err != nil {
+		return model.AppUser{}, err
+	}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	setParams := model.SetUserPasswordParams{
+		ID:                 userID,
+		Password:           h,
+		MustChangePassword: mustChange,
+		Updated:            now,
+	}
+	user, err := query.SetUserPassword(ctx, setParams)
+	if err != nil {
+		return model.AppUser{}, err
+	}
+	return user, query.DeleteSessionByUserID(ctx, userID)
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+)
+
+// patch is a JSON merge patch (RFC 7396), its keys are the fields to write.
+type patch map[string]any
+
+func (p patch) has(field string) bool {
+	_, ok := p[field]
+	return ok
+}
+
+// bindPatch decodes the merge patch in the body and applies it to current,
+// the resource as it is now, into req, which is validated like bind does.
+// It writes the error response itself and returns the patch, so the handler
+// can write only the fields the patch has.
+func bindPatch(w http.ResponseWriter, r *http.Request, current, req any) (patch, error) {
+	var p patch
+	err := decodeBody(w, r, &p)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := toMap(current)
+	if err != nil {
+		Err(w, errcode.Parse)
+		return nil, err
+	}
+	merged, err := json.Marshal(mergePatch(target, p))
+	if err != nil {
+		Err(w, errcode.Parse)
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	if config.Raw.Bool("DISALLOW_UNKNOWN_FIELDS") {
+		decoder.DisallowUnknownFields()
+	}
+	err = decoder.Decode(req)
+	if err != nil {
+		Err(w, errcode.Parse)
+		return nil, err
+	}
+
+	err = validate.Struct(req)
+	if err != nil {
+		validateErr(w, err)
+		return nil, err
+	}
+	return p, nil
+}
+
+// mergePatch applies p to target as RFC 7396 describes: null removes a
+// field, objects merge and anything else, arrays too, replaces.
+func mergePatch(target map[string]any, p map[string]any) map[string]any {
+	if target == nil {
+		target = map[string]any{}
+	}
+	for field, value := range p {
+		if value == nil {
+			delete(target, field)
+			continue
+		}
+		object, ok := value.(map[string]any)
+		if !ok {
+			target[field] = value
+			continue
+		}
+		targetObject, _ := target[field].(map[string]any)
+		target[field] = mergePatch(targetObject, object)
+	}
+	return target
+}
+
+func toMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/model"
+)
+
+const defaultPermissionCacheTTL = time.Minute
+
+// permissionCacheTTL bounds how long a permission set can be stale when an
+// invalidation is lost, such as while redis is unreachable.
+func permissionCacheTTL() time.Duration {
+	ttl := config.Raw.Int("PERMISSION_CACHE_TTL")
+	if ttl <= 0 {
+		return defaultPermissionCacheTTL
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// permission is a resource a user is allowed, the method is upper case.
+type permission struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+func permissionKey(userID int32) string {
+	return "permission:" + strconv.Itoa(int(userID))
+}
+
+// permissionMatcher returns the permissions the enabled roles of the user
+// grant through their enabled menus, compiled. They are cached per user, a
+// cache that fails is passed by so that requests go on from the database.
+func (a *API) permissionMatcher(ctx context.Context, query *model.Queries, userID int32) (*matcher, error) {
+	key := permissionKey(userID)
+
+	if a.Cache != nil {
+		value, ok, err := a.Cache.Get(ctx, key)
+		if err != nil {
+			slog.Warn("permission cache", "err", err)
+		}
+		if ok {
+			m, ok := compiledMatcher(value)
+			if ok {
+				return m, nil
+			}
+		}
+	}
+
+	resourceList, err := query.ListResourceByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	permissionList := compilePermission(resourceList)
+	m := newMatcher(permissionList)
+
+	if a.Cache != nil {
+		value, err := json.Marshal(permissionList)
+		if err == nil {
+			err = a.Cache.Set(ctx, key, value, permissionCacheTTL())
+		}
+		if err != nil {
+			slog.Warn("permission cache", "err", err)
+		}
+		if err == nil {
+			storeMatcher(value, m)
+		}
+	}
+	return m, nil
+}
+
+// maxCompiledMatcher bounds the compiled permission sets kept in memory.
+const maxCompiledMatcher = 4096
+
+// compiledMatcherMap memoizes matchers by the cached permission set they
+// were compiled from. A set that is invalidated or expires is never read
+// again, so an entry cannot be stale, it only takes memory until the map
+// is reset.
+var (
+	compiledMatcherMu  sync.Mutex
+	compiledMatcherMap = make(map[string]*matcher)
+)
+
+// compiledMatcher returns the matcher of a cached permission set, compiling
+// it on the first sight of the set.
+func compiledMatcher(value []byte) (*matcher, bool) {
+	compiledMatcherMu.Lock()
+	m, ok := compiledMatcherMap[string(value)]
+	compiledMatcherMu.Unlock()
+	if ok {
+		return m, true
+	}
+
+	var permissionList []permission
+	if json.Unmarshal(value, &permissionList) != nil {
+		return nil, false
+	}
+	m = newMatcher(permissionList)
+	storeMatcher(value, m)
+	return m, true
+}
+
+func storeMatcher(value []byte, m *matcher) {
+	compiledMatcherMu.Lock()
+	defer compiledMatcherMu.Unlock()
+	if len(compiledMatcherMap) >= maxCompiledMatcher {
+		compiledMatcherMap = make(map[string]*matcher)
+	}
+	compiledMatcherMap[string(value)] = m
+}
+
+// compilePermission drops the duplicates the roles and menus granting the
+// same resource leave and sorts the rest.
+func compilePermission(resourceList []model.Resource) []permission {
+	permissionList := make([]permission, 0, len(resourceList))
+	for _, resource := range resourceList {
+		permissionList = append(permissionList, permission{
+			Method: strings.ToUpper(resource.Method),
+			Path:   resource.Path,
+		})
+	}
+	slices.SortFunc(permissionList, func(a, b permission) int {
+		return strings.Compare(a.Method+" "+a.Path, b.Method+" "+b.Path)
+	})
+	return slices.Compact(permissionList)
+}
+
+// invalidateUserPermission drops the cached permissions of the users after
+// their roles changed.
+func (a *API) invalidateUserPermission(ctx context.Context, userIDList ...int32) {
+	if a.Cache == nil {
+		return
+	}
+	keys := make([]string, len(userIDList))
+	for i, userID := range userIDList {
+		keys[i] = permissionKey(userID)
+	}
+	err := a.Cache.Delete(ctx, keys...)
+	if err != nil {
+		slog.Error("permission cache invalidate", "err", err)
+	}
+}
+
+// invalidatePermission drops every cached permission set, after a change to
+// roles, menus or resources that may reach any user.
+func (a *API) invalidatePermission(ctx context.Context) {
+	if a.Cache == nil {
+		return
+	}
+	err := a.Cache.Clear(ctx)
+	if err != nil {
+		slog.Error("permission cache invalidate", "err", err)
+	}
+}
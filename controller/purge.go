@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/model"
+)
+
+const defaultPurgeInterval = time.Hour
+
+func purgeRetention() time.Duration {
+	return time.Duration(config.Raw.Int("PURGE_RETENTION")) * time.Second
+}
+
+func purgeInterval() time.Duration {
+	interval := time.Duration(config.Raw.Int("PURGE_INTERVAL")) * time.Second
+	if interval <= 0 {
+		return defaultPurgeInterval
+	}
+	return interval
+}
+
+// purgeLoop purges every PURGE_INTERVAL until ctx is done. Expired sessions
+// and refresh tokens always go, a PURGE_RETENTION of zero keeps soft deleted
+// rows forever.
+func (a *API) purgeLoop(ctx context.Context) {
+	ticker := time.NewTicker(purgeInterval())
+	defer ticker.Stop()
+	for {
+		rows, err := a.PurgeExpired(ctx, time.Now())
+		if err != nil {
+			slog.Error("purge expired", "err", err)
+		} else if rows > 0 {
+			slog.Info("purge expired", "rows", rows)
+		}
+
+		if purgeRetention() > 0 {
+			rows, err := a.Purge(ctx, time.Now().Add(-purgeRetention()))
+			if err != nil {
+				slog.Error("purge", "err", err)
+			} else if rows > 0 {
+				slog.Info("purge", "rows", rows)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the rows soft deleted before the given time for good.
+func (a *API) Purge(ctx context.Context, before time.Time) (int64, error) {
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Purge runs in the background, a failed rollback must not take the
+	// server down, the connection is dropped by the pool anyway
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			slog.Error("purge rollback", "err", err)
+		}
+	}()
+
+	query := model.New(transaction)
+	deletedAt := pgtype.Timestamp{Time: before, Valid: true}
+
+	// link rows first, the rest would go by ON DELETE CASCADE anyway
+	var total int64
+	for _, purge := range []func(context.Context, pgtype.Timestamp) (int64, error){
+		query.PurgeUserRole,
+		query.PurgeRoleMenu,
+		query.PurgeResource,
+		query.PurgeUser,
+		query.PurgeRole,
+		query.PurgeMenu,
+	} {
+		rows, err := purge(ctx, deletedAt)
+		if err != nil {
+			return 0, err
+		}
+		total += rows
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// PurgeExpired removes the sessions and refresh tokens expired before the
+// given time, nothing can be done with them anymore.
+func (a *API) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := model.New(a.DB)
+	expired := pgtype.Timestamp{Time: before, Valid: true}
+
+	// refresh tokens of live sessions expire as they are rotated
+	var total int64
+	for _, purge := range []func(context.Context, pgtype.Timestamp) (int64, error){
+		query.PurgeRefreshToken,
+		query.PurgeSession,
+	} {
+		rows, err := purge(ctx, expired)
+		if err != nil {
+			return 0, err
+		}
+		total += rows
+	}
+	return total, nil
+}
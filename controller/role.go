@@ -1,12 +1,13 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/linehk/go-admin/errcode"
 	"github.com/linehk/go-admin/model"
 )
@@ -16,10 +17,8 @@ func (a *API) PostApiV1Roles(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req Role
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
 		return
 	}
 
@@ -36,26 +35,16 @@ func (a *API) PostApiV1Roles(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	exist, err := query.CheckRoleByCode(ctx, params.Code)
-	if err != nil {
-		Err(w, errcode.Database)
-		return
-	}
-	if exist {
-		Err(w, errcode.RoleCodeOccupy)
-		return
-	}
-
 	role, err := query.CreateRole(ctx, params)
 	if err != nil {
-		Err(w, errcode.Database)
+		dbErr(w, err)
 		return
 	}
 
@@ -69,21 +58,29 @@ func (a *API) PostApiV1Roles(w http.ResponseWriter, r *http.Request) {
 
 		roleMenu, err := query.CreateRoleMenu(ctx, params)
 		if err != nil {
-			Err(w, errcode.Database)
+			dbErr(w, err)
 			return
 		}
 
 		roleMenuList = append(roleMenuList, roleMenuResp(roleMenu))
 	}
 
+	resp := roleResp(role)
+	resp.Menu = roleMenuList
+
+	err = writeAudit(r, query, Create, auditRole, role.ID, nil, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
 	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	resp := roleResp(role)
-	resp.Menu = roleMenuList
+	w.Header().Set("ETag", etag(role.Version))
 	encode(w, resp)
 }
 
@@ -91,27 +88,46 @@ func (a *API) GetApiV1Roles(w http.ResponseWriter, r *http.Request, params GetAp
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 
-	err := validator.New().Struct(params)
+	err := validate.Struct(params)
 	if err != nil {
-		Err(w, errcode.Validate)
+		validateErr(w, err)
 		return
 	}
 
-	var modelParams model.ListRoleParams
-	modelParams.Column1 = params.Name
-	modelParams.Column2 = params.Status
-	modelParams.ID, modelParams.Limit = paging(params.Current, params.PageSize)
+	option, err := listOption(w, model.RoleColumn, params.Filter, params.Sort, params.Cursor, roleSort)
+	if err != nil {
+		return
+	}
+	option.IncludeDeleted = params.IncludeDeleted
+	pageSize := pageLimit(params.PageSize)
+	// one more row tells whether there is a next page
+	option.Limit = pageSize + 1
 
 	query := model.New(a.DB)
 
-	roleList, err := query.ListRole(ctx, modelParams)
+	roleList, err := query.ListRoleBy(ctx, option)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
+	var resp RolePage
+	if len(roleList) > int(pageSize) {
+		roleList = roleList[:pageSize]
+		resp.NextCursor = encodeCursor(cursor{Sort: params.Sort, After: model.After(option.Sort, roleList[len(roleList)-1])})
+	}
+
+	if params.Total {
+		total, err := query.CountRoleBy(ctx, option)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		resp.Total = &total
+	}
+
 	var roleIDList []int32
-	var respList []Role
+	respList := make([]Role, 0, len(roleList))
 	for _, role := range roleList {
 		roleIDList = append(roleIDList, role.ID)
 		respList = append(respList, roleResp(role))
@@ -132,7 +148,8 @@ func (a *API) GetApiV1Roles(w http.ResponseWriter, r *http.Request, params GetAp
 		respList[i].Menu = roleIDToRoleMenuList[*respList[i].Id]
 	}
 
-	encode(w, respList)
+	resp.Items = respList
+	encode(w, resp)
 }
 
 func (a *API) DeleteApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -146,30 +163,59 @@ func (a *API) DeleteApiV1RolesId(w http.ResponseWriter, r *http.Request, id int3
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	exist, err := query.CheckRoleByID(ctx, id)
-	if err != nil {
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockRoleVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
 	}
-	if !exist {
+	if errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.RoleNotExist)
 		return
 	}
+	if !ifMatch(w, r, version) {
+		return
+	}
 
-	err = query.DeleteRole(ctx, id)
+	roleByGet, err := roleWithMenu(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+
+	// the rows are kept until purged, so that the role can be restored
+	deletedAt := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	err = query.SoftDeleteRole(ctx, model.SoftDeleteRoleParams{ID: id, DeletedAt: deletedAt})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = query.SoftDeleteUserRoleByRoleID(ctx, model.SoftDeleteUserRoleByRoleIDParams{RoleID: id, DeletedAt: deletedAt})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = query.SoftDeleteRoleMenuByRoleID(ctx, model.SoftDeleteRoleMenuByRoleIDParams{RoleID: id, DeletedAt: deletedAt})
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	err = query.DeleteRoleMenuByRoleID(ctx, id)
+	err = writeAudit(r, query, Delete, auditRole, id, roleByGet, nil)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
@@ -180,6 +226,8 @@ func (a *API) DeleteApiV1RolesId(w http.ResponseWriter, r *http.Request, id int3
 		Err(w, errcode.Database)
 		return
 	}
+
+	a.invalidatePermission(ctx)
 }
 
 func (a *API) GetApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -188,7 +236,8 @@ func (a *API) GetApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 
 	query := model.New(a.DB)
 
-	role, err := query.GetRole(ctx, id)
+	// read before the role, so the ETag can only be older than the body
+	version, err := query.GetRoleVersion(ctx, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -197,20 +246,21 @@ func (a *API) GetApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.RoleNotExist)
 		return
 	}
+	if ifNoneMatch(w, r, version) {
+		return
+	}
 
-	roleMenuList, err := query.ListRoleMenuByRoleIDList(ctx, []int32{role.ID})
-	if err != nil {
+	resp, err := roleWithMenu(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
 	}
-
-	var roleMenuListResp []RoleMenu
-	for _, roleMenu := range roleMenuList {
-		roleMenuListResp = append(roleMenuListResp, roleMenuResp(roleMenu))
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
 	}
 
-	resp := roleResp(role)
-	resp.Menu = roleMenuListResp
+	w.Header().Set("ETag", etag(version))
 	encode(w, resp)
 }
 
@@ -219,10 +269,8 @@ func (a *API) PutApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 	ctx := r.Context()
 
 	var req Role
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
 		return
 	}
 
@@ -239,14 +287,15 @@ func (a *API) PutApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	roleByGet, err := query.GetRole(ctx, id)
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockRoleVersion(ctx, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -255,25 +304,25 @@ func (a *API) PutApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.RoleNotExist)
 		return
 	}
+	if !ifMatch(w, r, version) {
+		return
+	}
 
-	// update code
-	if req.Code != roleByGet.Code {
-		exist, err := query.CheckRoleByCode(ctx, req.Code)
-		if err != nil {
-			Err(w, errcode.Database)
-			return
-		}
-		if exist {
-			Err(w, errcode.RoleCodeOccupy)
-			return
-		}
+	roleByGet, err := roleWithMenu(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
 	}
 
 	params.ID = id
 
 	roleByUpdate, err := query.UpdateRole(ctx, params)
 	if err != nil {
-		Err(w, errcode.Database)
+		dbErr(w, err)
 		return
 	}
 
@@ -293,24 +342,332 @@ func (a *API) PutApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32)
 
 		roleMenu, err := query.CreateRoleMenu(ctx, params)
 		if err != nil {
-			Err(w, errcode.Database)
+			dbErr(w, err)
 			return
 		}
 
 		roleMenuList = append(roleMenuList, roleMenuResp(roleMenu))
 	}
 
+	resp := roleResp(roleByUpdate)
+	resp.Menu = roleMenuList
+
+	err = writeAudit(r, query, Update, auditRole, id, roleByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
 	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	resp := roleResp(roleByUpdate)
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(roleByUpdate.Version))
+	encode(w, resp)
+}
+
+func (a *API) PatchApiV1RolesId(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockRoleVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	roleByGet, err := roleWithMenu(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+
+	var req Role
+	p, err := bindPatch(w, r, roleByGet, &req)
+	if err != nil {
+		return
+	}
+
+	params, err := patchRoleParams(p, req)
+	if err != nil {
+		Err(w, errcode.Convert)
+		return
+	}
+	params.ID = id
+
+	roleByPatch, err := query.PatchRole(ctx, params)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	roleMenuList := roleByGet.Menu
+	if p.has("menu") {
+		err = query.DeleteRoleMenuByRoleID(ctx, id)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
+		roleMenuList = nil
+		for _, req := range req.Menu {
+			params, err := createRoleMenuParams(req, id)
+			if err != nil {
+				Err(w, errcode.Convert)
+				return
+			}
+
+			roleMenu, err := query.CreateRoleMenu(ctx, params)
+			if err != nil {
+				dbErr(w, err)
+				return
+			}
+
+			roleMenuList = append(roleMenuList, roleMenuResp(roleMenu))
+		}
+	}
+
+	resp := roleResp(roleByPatch)
 	resp.Menu = roleMenuList
+
+	err = writeAudit(r, query, Update, auditRole, id, roleByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(roleByPatch.Version))
 	encode(w, resp)
 }
 
+func (a *API) PostApiV1RolesIdRestore(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	roleByGet, err := query.GetDeletedRole(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+
+	restoreParams := model.RestoreRoleParams{
+		ID:      id,
+		Updated: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	roleByRestore, err := query.RestoreRole(ctx, restoreParams)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	// only the users and menus deleted along with the role come back
+	restoreUserRoleParams := model.RestoreUserRoleByRoleIDParams{
+		RoleID:    id,
+		DeletedAt: roleByGet.DeletedAt,
+	}
+	err = query.RestoreUserRoleByRoleID(ctx, restoreUserRoleParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	restoreRoleMenuParams := model.RestoreRoleMenuByRoleIDParams{
+		RoleID:    id,
+		DeletedAt: roleByGet.DeletedAt,
+	}
+	err = query.RestoreRoleMenuByRoleID(ctx, restoreRoleMenuParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	resp, err := roleWithMenu(ctx, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Restore, auditRole, id, roleResp(roleByGet), resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(roleByRestore.Version))
+	encode(w, resp)
+}
+
+var roleBatch = batchEntity{
+	create:     (*API).PostApiV1Roles,
+	update:     (*API).PutApiV1RolesId,
+	patch:      (*API).PatchApiV1RolesId,
+	delete:     (*API).DeleteApiV1RolesId,
+	createList: createRoleList,
+}
+
+func (a *API) PostApiV1RolesBatch(w http.ResponseWriter, r *http.Request) {
+	a.batch(w, r, roleBatch)
+}
+
+// createRoleList inserts the roles in one batch, their menus and audit
+// entries with one copy each.
+func createRoleList(r *http.Request, query *model.Queries, bodyList [][]byte) ([]BatchResult, error) {
+	ctx := r.Context()
+
+	reqList := make([]Role, len(bodyList))
+	paramsList := make([]model.CreateRoleBatchParams, len(bodyList))
+	for i, body := range bodyList {
+		err := decodeItem(body, &reqList[i])
+		if err != nil {
+			return nil, err
+		}
+		params, err := createRoleParams(reqList[i])
+		if err != nil {
+			return nil, err
+		}
+		paramsList[i] = model.CreateRoleBatchParams(params)
+	}
+
+	roleList := make([]model.Role, len(paramsList))
+	var batchErr error
+	query.CreateRoleBatch(ctx, paramsList).QueryRow(func(i int, role model.Role, err error) {
+		if err != nil && batchErr == nil {
+			batchErr = err
+		}
+		roleList[i] = role
+	})
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	var roleIDList []int32
+	var roleMenuParamsList []model.CopyRoleMenuParams
+	for i, req := range reqList {
+		roleIDList = append(roleIDList, roleList[i].ID)
+		for _, req := range req.Menu {
+			params, err := createRoleMenuParams(req, roleList[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			roleMenuParamsList = append(roleMenuParamsList, model.CopyRoleMenuParams(params))
+		}
+	}
+	_, err := query.CopyRoleMenu(ctx, roleMenuParamsList)
+	if err != nil {
+		return nil, err
+	}
+
+	roleMenuList, err := query.ListRoleMenuByRoleIDList(ctx, roleIDList)
+	if err != nil {
+		return nil, err
+	}
+	roleMenuMap := make(map[int32][]RoleMenu)
+	for _, roleMenu := range roleMenuList {
+		roleMenuMap[roleMenu.RoleID] = append(roleMenuMap[roleMenu.RoleID], roleMenuResp(roleMenu))
+	}
+
+	resultList := make([]BatchResult, len(roleList))
+	var auditParamsList []model.CopyAuditLogParams
+	for i, role := range roleList {
+		resp := roleResp(role)
+		resp.Menu = roleMenuMap[role.ID]
+		params, err := auditParams(r, Create, auditRole, role.ID, nil, resp)
+		if err != nil {
+			return nil, err
+		}
+		auditParamsList = append(auditParamsList, model.CopyAuditLogParams(params))
+		resultList[i] = BatchResult{Status: http.StatusOK, Id: role.ID, Etag: etag(role.Version)}
+	}
+	_, err = query.CopyAuditLog(ctx, auditParamsList)
+	if err != nil {
+		return nil, err
+	}
+	return resultList, nil
+}
+
+// roleWithMenu reads the role with its menus, pgx.ErrNoRows if it does not exist.
+func roleWithMenu(ctx context.Context, query *model.Queries, id int32) (Role, error) {
+	role, err := query.GetRole(ctx, id)
+	if err != nil {
+		return Role{}, err
+	}
+
+	roleMenuList, err := query.ListRoleMenuByRoleIDList(ctx, []int32{role.ID})
+	if err != nil {
+		return Role{}, err
+	}
+
+	resp := roleResp(role)
+	for _, roleMenu := range roleMenuList {
+		resp.Menu = append(resp.Menu, roleMenuResp(roleMenu))
+	}
+	return resp, nil
+}
+
 func createRoleParams(req Role) (model.CreateRoleParams, error) {
 	var params model.CreateRoleParams
 	params.Code = req.Code
@@ -321,6 +678,7 @@ func createRoleParams(req Role) (model.CreateRoleParams, error) {
 		req.Status = RoleStatusEnabled
 	}
 	params.Status = string(req.Status)
+	params.Require2fa = req.Require2fa
 	if req.Created == "" {
 		req.Created = time.Now().Format(pgTimestampFormat)
 	}
@@ -345,6 +703,7 @@ func updateRoleParams(req Role) (model.UpdateRoleParams, error) {
 	params.Description = req.Description
 	params.Sequence = req.Sequence
 	params.Status = string(req.Status)
+	params.Require2fa = req.Require2fa
 	err := params.Created.Scan(req.Created)
 	if err != nil {
 		return model.UpdateRoleParams{}, err
@@ -359,6 +718,31 @@ func updateRoleParams(req Role) (model.UpdateRoleParams, error) {
 	return params, nil
 }
 
+// patchRoleParams sets only the fields p has.
+func patchRoleParams(p patch, req Role) (model.PatchRoleParams, error) {
+	var params model.PatchRoleParams
+	params.Code = pgtype.Text{String: req.Code, Valid: p.has("code")}
+	params.Name = pgtype.Text{String: req.Name, Valid: p.has("name")}
+	params.Description = pgtype.Text{String: req.Description, Valid: p.has("description")}
+	params.Sequence = pgtype.Int2{Int16: req.Sequence, Valid: p.has("sequence")}
+	params.Status = pgtype.Text{String: string(req.Status), Valid: p.has("status")}
+	params.Require2fa = pgtype.Bool{Bool: req.Require2fa, Valid: p.has("require_2fa")}
+	if p.has("created") {
+		err := params.Created.Scan(req.Created)
+		if err != nil {
+			return model.PatchRoleParams{}, err
+		}
+	}
+	if !p.has("updated") {
+		req.Updated = time.Now().Format(pgTimestampFormat)
+	}
+	err := params.Updated.Scan(req.Updated)
+	if err != nil {
+		return model.PatchRoleParams{}, err
+	}
+	return params, nil
+}
+
 func roleResp(roleModel model.Role) Role {
 	var resp Role
 	resp.Id = &roleModel.ID
@@ -367,8 +751,12 @@ func roleResp(roleModel model.Role) Role {
 	resp.Description = roleModel.Description
 	resp.Sequence = roleModel.Sequence
 	resp.Status = RoleStatus(roleModel.Status)
+	resp.Require2fa = roleModel.Require2fa
 	resp.Created = roleModel.Created.Time.Format(pgTimestampFormat)
 	resp.Updated = roleModel.Updated.Time.Format(pgTimestampFormat)
+	if roleModel.DeletedAt.Valid {
+		resp.DeletedAt = roleModel.DeletedAt.Time.Format(pgTimestampFormat)
+	}
 	return resp
 }
 
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+func (a *API) GetApiV1RolesIdParents(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	// read before the parents, so the ETag can only be older than the body
+	version, err := query.GetRoleVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+	if ifNoneMatch(w, r, version) {
+		return
+	}
+
+	resp, err := roleParentList(r, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	w.Header().Set("ETag", etag(version))
+	encode(w, resp)
+}
+
+func (a *API) PutApiV1RolesIdParents(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req RoleParentList
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+	slices.Sort(req.ParentId)
+	req.ParentId = slices.Compact(req.ParentId)
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// writes run one at a time, two of them linking roles to each other
+	// would otherwise both pass the cycle check
+	err = query.LockRoleTree(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	version, err := query.LockRoleVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	before, err := roleParentList(r, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	for _, parentID := range req.ParentId {
+		exist, err := query.CheckRoleByID(ctx, parentID)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		if !exist {
+			Err(w, errcode.RoleNotExist)
+			return
+		}
+	}
+
+	// deleted roles count as well, restoring one must not close a cycle
+	ancestorParams := model.CheckRoleAncestorParams{
+		RoleID:       id,
+		ParentIDList: req.ParentId,
+	}
+	cycle, err := query.CheckRoleAncestor(ctx, ancestorParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if cycle {
+		Err(w, errcode.RoleCycle)
+		return
+	}
+
+	err = query.DeleteRoleParentByRoleID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	for _, parentID := range req.ParentId {
+		params := model.CreateRoleParentParams{
+			RoleID:   id,
+			ParentID: parentID,
+			Created:  now,
+			Updated:  now,
+		}
+		_, err := query.CreateRoleParent(ctx, params)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+	}
+
+	version, err = query.BumpRoleVersion(ctx, model.BumpRoleVersionParams{ID: id, Updated: now})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	resp := RoleParentList{ParentId: append([]int32{}, req.ParentId...)}
+
+	err = writeAudit(r, query, Update, auditRole, id, before, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidatePermission(ctx)
+
+	w.Header().Set("ETag", etag(version))
+	encode(w, resp)
+}
+
+func (a *API) GetApiV1RolesIdPermissions(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	exist, err := query.CheckRoleByID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !exist {
+		Err(w, errcode.RoleNotExist)
+		return
+	}
+
+	ancestorIDList, err := query.ListInheritedRoleID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	menuList, err := query.ListMenuByRoleIDList(ctx, append([]int32{id}, ancestorIDList...))
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var menuIDList []int32
+	for _, menu := range menuList {
+		menuIDList = append(menuIDList, menu.ID)
+	}
+
+	resourceList, err := query.ListResourceByMenuIDList(ctx, menuIDList)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var resp RolePermission
+	resp.AncestorId = append([]int32{}, ancestorIDList...)
+	resp.Menu = append([]Menu{}, menuTree(menuList, resourceList)...)
+
+	encode(w, resp)
+}
+
+func roleParentList(r *http.Request, query *model.Queries, id int32) (RoleParentList, error) {
+	roleParentList, err := query.ListRoleParentByRoleID(r.Context(), id)
+	if err != nil {
+		return RoleParentList{}, err
+	}
+
+	resp := RoleParentList{ParentId: []int32{}}
+	for _, roleParent := range roleParentList {
+		resp.ParentId = append(resp.ParentId, roleParent.ParentID)
+	}
+	return resp, nil
+}
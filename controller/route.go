@@ -0,0 +1,156 @@
+package controller
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var openapiSpec []byte
+
+// route is an operation HandlerWithOptions registers.
+type route struct {
+	Method      string
+	Pattern     string
+	OperationID string
+	Tag         string
+	Description string
+}
+
+type operation struct {
+	OperationID string   `yaml:"operationId"`
+	Tags        []string `yaml:"tags"`
+	Description string   `yaml:"description"`
+}
+
+// routeList holds the routes of the spec the handlers are generated from,
+// Setup checks that they are the ones registered.
+var routeList = func() []route {
+	var spec struct {
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+	err := yaml.Unmarshal(openapiSpec, &spec)
+	if err != nil {
+		panic(err)
+	}
+
+	var routeList []route
+	for pattern, item := range spec.Paths {
+		for method, node := range item {
+			method = strings.ToUpper(method)
+			if !knownMethod[method] {
+				continue
+			}
+			var op operation
+			err := node.Decode(&op)
+			if err != nil {
+				panic(err)
+			}
+			r := route{
+				Method:      method,
+				Pattern:     pattern,
+				OperationID: op.OperationID,
+				Description: op.Description,
+			}
+			if r.OperationID == "" {
+				r.OperationID = operationID(method, pattern)
+			}
+			if len(op.Tags) > 0 {
+				r.Tag = op.Tags[0]
+			}
+			routeList = append(routeList, r)
+		}
+	}
+	slices.SortFunc(routeList, func(a, b route) int {
+		return strings.Compare(a.Pattern+" "+a.Method, b.Pattern+" "+b.Method)
+	})
+	return routeList
+}()
+
+// operationID names an operation without an operationId the way
+// oapi-codegen names its handler, GET /api/v1/users/{id} is GetApiV1UsersId.
+func operationID(method, pattern string) string {
+	var b strings.Builder
+	b.WriteString(method[:1] + strings.ToLower(method[1:]))
+	words := strings.FieldsFunc(pattern, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// checkRouteList fails when a route of the spec does not reach its own
+// pattern on mux or names no handler, the spec and the generated code are
+// then out of step.
+func checkRouteList(mux *http.ServeMux) error {
+	serverInterface := reflect.TypeOf((*ServerInterface)(nil)).Elem()
+	for _, route := range routeList {
+		_, ok := serverInterface.MethodByName(route.OperationID)
+		if !ok {
+			return fmt.Errorf("route %s %s has no handler %s", route.Method, route.Pattern, route.OperationID)
+		}
+
+		// a wildcard takes any value, so it is probed with one
+		segments := strings.Split(route.Pattern, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, "{") {
+				segments[i] = "probe"
+			}
+		}
+		req, err := http.NewRequest(route.Method, strings.Join(segments, "/"), nil)
+		if err != nil {
+			return err
+		}
+		_, pattern := mux.Handler(req)
+		if pattern != route.Method+" "+route.Pattern {
+			return fmt.Errorf("route %s %s is not registered", route.Method, route.Pattern)
+		}
+	}
+	return nil
+}
+
+// registerResourceValidation checks the method and path of resources, and
+// that they grant at least one registered route.
+func registerResourceValidation(v *validator.Validate) {
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		resource := sl.Current().Interface().(Resource)
+
+		methodList, anyMethod, err := parseMethods(resource.Method)
+		if err != nil {
+			sl.ReportError(resource.Method, "method", "Method", "resource_method", "")
+		}
+		segments, err := parsePattern(resource.Path)
+		if err != nil {
+			sl.ReportError(resource.Path, "path", "Path", "resource_path", "")
+			return
+		}
+		if (anyMethod || methodList != nil) && !routed(methodList, anyMethod, segments) {
+			sl.ReportError(resource.Path, "path", "Path", "resource_route", "")
+		}
+	}, Resource{})
+}
+
+// routed reports whether the methods and segments of a resource reach a
+// registered route.
+func routed(methodList []string, anyMethod bool, segments []segment) bool {
+	for _, route := range routeList {
+		if !anyMethod && !slices.Contains(methodList, route.Method) {
+			continue
+		}
+		routeSegments, err := parsePattern(route.Pattern)
+		if err == nil && overlap(segments, routeSegments) {
+			return true
+		}
+	}
+	return false
+}
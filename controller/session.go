@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+)
+
+// last_seen is written at most this often per session, not on every request
+const sessionTouchInterval = time.Minute
+
+func (a *API) GetApiV1Sessions(w http.ResponseWriter, r *http.Request, params GetApiV1SessionsParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	err := validate.Struct(params)
+	if err != nil {
+		validateErr(w, err)
+		return
+	}
+
+	var modelParams model.ListSessionParams
+	modelParams.UserID = pgtype.Int4{Int32: params.UserId, Valid: params.UserId != 0}
+	modelParams.Now = pgtype.Timestamp{Time: time.Now(), Valid: true}
+	modelParams.Offset, modelParams.Limit = paging(params.Current, params.PageSize)
+
+	query := model.New(a.DB)
+
+	sessionList, err := query.ListSession(ctx, modelParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	currentID, _ := currentSessionID(ctx)
+
+	var respList []Session
+	for _, session := range sessionList {
+		respList = append(respList, sessionResp(session, currentID))
+	}
+
+	encode(w, respList)
+}
+
+func (a *API) DeleteApiV1SessionsId(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	rows, err := query.DeleteSession(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if rows == 0 {
+		Err(w, errcode.SessionNotExist)
+		return
+	}
+}
+
+func (a *API) DeleteApiV1UsersIdSessions(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	query := model.New(a.DB)
+
+	_, err := query.GetUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	err = query.DeleteSessionByUserID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+}
+
+// startSession opens a session for the device the request comes from and
+// issues its first tokens.
+func startSession(r *http.Request, query *model.Queries, userID int32, now time.Time) (Token, error) {
+	ctx := r.Context()
+
+	var params model.CreateSessionParams
+	params.UserID = userID
+	params.UserAgent = r.UserAgent()
+	params.Ip = clientIP(r)
+	params.Created = pgtype.Timestamp{Time: now, Valid: true}
+	params.LastSeen = pgtype.Timestamp{Time: now, Valid: true}
+	params.Expired = pgtype.Timestamp{Time: now.Add(refreshTokenExpire()), Valid: true}
+	session, err := query.CreateSession(ctx, params)
+	if err != nil {
+		return Token{}, err
+	}
+	return issueToken(ctx, query, userID, session.ID)
+}
+
+// checkSession reports whether the session of an access token is still open,
+// not expired and belongs to the user, and notes that it has been seen.
+func checkSession(ctx context.Context, query *model.Queries, userID, sessionID int32) (bool, error) {
+	session, err := query.GetSession(ctx, sessionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if session.UserID != userID {
+		return false, nil
+	}
+
+	// an expired session is refused before the purge gets to it
+	now := time.Now()
+	if now.After(session.Expired.Time) {
+		return false, nil
+	}
+	if now.Sub(session.LastSeen.Time) < sessionTouchInterval {
+		return true, nil
+	}
+	params := model.TouchSessionParams{
+		ID:       sessionID,
+		LastSeen: pgtype.Timestamp{Time: now, Valid: true},
+	}
+	return true, query.TouchSession(ctx, params)
+}
+
+// endFrozenSessions logs a frozen user out of every session.
+func endFrozenSessions(ctx context.Context, query *model.Queries, user model.AppUser) error {
+	if UserStatus(user.Status) != Frozen {
+		return nil
+	}
+	return query.DeleteSessionByUserID(ctx, user.ID)
+}
+
+func sessionResp(m model.Session, currentID int32) Session {
+	var resp Session
+	resp.Id = m.ID
+	resp.UserId = m.UserID
+	resp.UserAgent = m.UserAgent
+	resp.Ip = m.Ip
+	resp.Created = m.Created.Time.Format(pgTimestampFormat)
+	resp.LastSeen = m.LastSeen.Time.Format(pgTimestampFormat)
+	resp.Expired = m.Expired.Time.Format(pgTimestampFormat)
+	resp.Current = m.ID == currentID
+	return resp
+}
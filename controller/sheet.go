@@ -0,0 +1,580 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	csvType  = "text/csv"
+	xlsxType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	exportPageSize = 500
+	roleSeparator  = ";"
+
+	defaultMaxSheetUnzipBytes = 64 << 20
+)
+
+// maxSheetUnzipBytes bounds an uploaded XLSX once unzipped, a small body can
+// unzip to gigabytes.
+func maxSheetUnzipBytes() int64 {
+	size := config.Raw.Int64("MAX_SHEET_UNZIP_BYTES")
+	if size <= 0 {
+		return defaultMaxSheetUnzipBytes
+	}
+	return size
+}
+
+// userColumn lists the columns of an exported sheet. An import takes the
+// same sheet back, id, created, updated and deleted_at are ignored there
+// and password may be added.
+var userColumn = []string{"id", "username", "name", "email", "phone", "remark", "status", "role", "created", "updated", "deleted_at"}
+
+var importColumn = map[string]bool{
+	"username": true,
+	"password": true,
+	"name":     true,
+	"email":    true,
+	"phone":    true,
+	"remark":   true,
+	"status":   true,
+	"role":     true,
+}
+
+// GetApiV1UsersExport writes the users a page at a time as it reads them.
+// A failure after the first row can only cut the response short.
+func (a *API) GetApiV1UsersExport(w http.ResponseWriter, r *http.Request, params GetApiV1UsersExportParams) {
+	ctx := r.Context()
+
+	err := validate.Struct(params)
+	if err != nil {
+		validateErr(w, err)
+		return
+	}
+
+	option, err := listOption(w, model.UserColumn, params.Filter, params.Sort, "", userSort)
+	if err != nil {
+		return
+	}
+	option.IncludeDeleted = params.IncludeDeleted
+	option.Limit = exportPageSize
+
+	query := model.New(a.DB)
+
+	userList, err := query.ListUserBy(ctx, option)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	sheet, err := newSheetWriter(w, params.Format)
+	if err != nil {
+		Err(w, errcode.Parse)
+		return
+	}
+	err = sheet.Write(userColumn)
+	for err == nil {
+		err = writeUserRow(r, query, sheet, userList)
+		if err != nil || len(userList) < exportPageSize {
+			break
+		}
+
+		option.After = model.After(option.Sort, userList[len(userList)-1])
+		userList, err = query.ListUserBy(ctx, option)
+	}
+	if err == nil {
+		err = sheet.Close()
+	}
+	if err != nil {
+		slog.Error("export users", "err", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func writeUserRow(r *http.Request, query *model.Queries, sheet sheetWriter, userList []model.AppUser) error {
+	var userIDList []int32
+	for _, user := range userList {
+		userIDList = append(userIDList, user.ID)
+	}
+
+	roleCodeList, err := query.ListRoleCodeByUserIDList(r.Context(), userIDList)
+	if err != nil {
+		return err
+	}
+	userIDToRoleCode := make(map[int32][]string)
+	for _, roleCode := range roleCodeList {
+		userIDToRoleCode[roleCode.UserID] = append(userIDToRoleCode[roleCode.UserID], roleCode.Code)
+	}
+
+	for _, user := range userList {
+		resp := userResp(user)
+		err = sheet.Write([]string{
+			strconv.Itoa(int(user.ID)),
+			resp.Username,
+			resp.Name,
+			resp.Email,
+			resp.Phone,
+			resp.Remark,
+			string(resp.Status),
+			strings.Join(userIDToRoleCode[user.ID], roleSeparator),
+			resp.Created,
+			resp.Updated,
+			resp.DeletedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return sheet.Flush()
+}
+
+// formulaPrefix holds what makes a spreadsheet run a cell as a formula.
+const formulaPrefix = "=+-@\t\r"
+
+// escapeCell puts ' before a CSV value a spreadsheet would run as a formula,
+// so that it is shown as text. unescapeCell drops it again on import, so that
+// an export imports as it was. XLSX cells are typed as strings and need
+// neither.
+func escapeCell(value string) string {
+	if value != "" && strings.IndexByte(formulaPrefix, value[0]) >= 0 {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.IndexByte(formulaPrefix, value[1]) >= 0 {
+		return value[1:]
+	}
+	return value
+}
+
+// PostApiV1UsersImport runs every row in a savepoint of one transaction,
+// so that all rows are checked against the database even after one failed.
+// It commits only when no row failed and it is no dry run.
+func (a *API) PostApiV1UsersImport(w http.ResponseWriter, r *http.Request, params PostApiV1UsersImportParams) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	rowList, err := readSheet(w, r)
+	if err != nil {
+		return
+	}
+	if len(rowList) == 0 {
+		Err(w, errcode.SheetInvalid)
+		return
+	}
+
+	header := rowList[0]
+	var details []ErrorDetail
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		header[i] = column
+		if !importColumn[column] && !slices.Contains(userColumn, column) {
+			details = append(details, ErrorDetail{Field: column, Tag: "column"})
+		}
+	}
+	if !slices.Contains(header, "username") {
+		details = append(details, ErrorDetail{Field: "username", Tag: "required"})
+	}
+	if details != nil {
+		writeErr(w, errcode.SheetInvalid, details)
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	var roleCodeList []string
+	for _, row := range rowList[1:] {
+		for i, value := range row {
+			if i < len(header) && header[i] == "role" {
+				roleCodeList = append(roleCodeList, splitRoleCode(value)...)
+			}
+		}
+	}
+	roleList, err := query.ListRoleByCodeList(ctx, roleCodeList)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	roleCodeToID := make(map[string]int32)
+	for _, role := range roleList {
+		roleCodeToID[role.Code] = role.ID
+	}
+
+	var resp ImportResult
+	for i, row := range rowList[1:] {
+		// an empty cell leaves the field as it is, so only the others patch
+		p := patch{}
+		for j, value := range row {
+			value = strings.TrimSpace(value)
+			if j < len(header) && importColumn[header[j]] && value != "" {
+				p[header[j]] = value
+			}
+		}
+		if len(p) == 0 {
+			continue
+		}
+
+		created, importErr := importUser(r, transaction, roleCodeToID, p)
+		switch {
+		case importErr != nil:
+			importErr.Row = int32(i + 2)
+			resp.Errors = append(resp.Errors, *importErr)
+		case created:
+			resp.Created++
+		default:
+			resp.Updated++
+		}
+	}
+
+	if len(resp.Errors) == 0 && !params.DryRun {
+		err = transaction.Commit(ctx)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		resp.Committed = true
+		a.invalidatePermission(ctx)
+	}
+
+	encode(w, resp)
+}
+
+// importUser creates the user of a row, or updates it when the username
+// exists, and reports whether it created one.
+func importUser(r *http.Request, transaction pgx.Tx, roleCodeToID map[string]int32, p patch) (bool, *ImportError) {
+	ctx := r.Context()
+
+	savepoint, err := transaction.Begin(ctx)
+	if err != nil {
+		return false, importErr(errcode.Database, nil)
+	}
+	defer func() {
+		err := savepoint.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	username, _ := p["username"].(string)
+	if username == "" {
+		return false, importErr(errcode.Validate, []ErrorDetail{{Field: "username", Tag: "required"}})
+	}
+
+	query := model.New(savepoint)
+
+	userByGet, err := query.GetUserByUsername(ctx, username)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, importErr(errcode.Database, nil)
+	}
+	exist := err == nil
+
+	var req User
+	if exist {
+		req, err = userWithRole(ctx, query, userByGet.ID)
+		if err != nil {
+			return false, importErr(errcode.Database, nil)
+		}
+	} else {
+		req.Status = Activated
+	}
+	before := req
+
+	var details []ErrorDetail
+	for field, value := range p {
+		value := value.(string)
+		switch field {
+		case "username":
+			req.Username = value
+		case "password":
+			req.Password = value
+		case "name":
+			req.Name = value
+		case "email":
+			req.Email = value
+		case "phone":
+			req.Phone = value
+		case "remark":
+			req.Remark = value
+		case "status":
+			req.Status = UserStatus(value)
+		case "role":
+			req.Role = nil
+			for _, code := range splitRoleCode(value) {
+				roleID, ok := roleCodeToID[code]
+				if !ok {
+					details = append(details, ErrorDetail{Field: "role", Tag: "exists", Param: code})
+					continue
+				}
+				req.Role = append(req.Role, UserRole{RoleId: roleID})
+			}
+		}
+	}
+	if !exist && req.Password == "" {
+		details = append(details, passwordRequired...)
+	}
+	err = validate.Struct(req)
+	details = append(details, validateDetails(err)...)
+	if details != nil {
+		return false, importErr(errcode.Validate, details)
+	}
+
+	var user model.AppUser
+	if exist {
+		if req.Password != "" {
+			reused, err := rotatePassword(ctx, query, userByGet.ID, req.Password)
+			if err != nil {
+				return false, importErr(errcode.Database, nil)
+			}
+			if reused {
+				return false, importErr(errcode.Validate, []ErrorDetail{{Field: "password", Tag: "password_history"}})
+			}
+
+			// a new password signs the user out everywhere, as setPassword does
+			err = query.DeleteSessionByUserID(ctx, userByGet.ID)
+			if err != nil {
+				return false, importErr(errcode.Database, nil)
+			}
+		}
+
+		params, err := patchUserParams(p, req)
+		if err != nil {
+			return false, importErr(errcode.Convert, nil)
+		}
+		params.ID = userByGet.ID
+		user, err = query.PatchUser(ctx, params)
+		if err != nil {
+			return false, importErr(dbErrcode(err), nil)
+		}
+
+		err = endFrozenSessions(ctx, query, user)
+		if err != nil {
+			return false, importErr(errcode.Database, nil)
+		}
+	} else {
+		params, err := createUserParams(req)
+		if err != nil {
+			return false, importErr(errcode.Convert, nil)
+		}
+		user, err = query.CreateUser(ctx, params)
+		if err != nil {
+			return false, importErr(dbErrcode(err), nil)
+		}
+	}
+
+	userRoleList := before.Role
+	if !exist || p.has("role") {
+		err = query.DeleteUserRoleByUserID(ctx, user.ID)
+		if err != nil {
+			return false, importErr(errcode.Database, nil)
+		}
+
+		userRoleList = nil
+		for _, req := range req.Role {
+			params, err := createUserRoleParams(req, user.ID)
+			if err != nil {
+				return false, importErr(errcode.Convert, nil)
+			}
+
+			userRole, err := query.CreateUserRole(ctx, params)
+			if err != nil {
+				return false, importErr(dbErrcode(err), nil)
+			}
+
+			userRoleList = append(userRoleList, userRoleResp(userRole))
+		}
+	}
+
+	resp := userResp(user)
+	resp.Role = userRoleList
+
+	if exist {
+		err = writeAudit(r, query, Update, auditUser, user.ID, before, resp)
+	} else {
+		err = writeAudit(r, query, Create, auditUser, user.ID, nil, resp)
+	}
+	if err != nil {
+		return false, importErr(errcode.Database, nil)
+	}
+
+	err = savepoint.Commit(ctx)
+	if err != nil {
+		return false, importErr(errcode.Database, nil)
+	}
+	return !exist, nil
+}
+
+func importErr(e int32, details []ErrorDetail) *ImportError {
+	return &ImportError{Code: e, Message: errcode.Msg(e), Details: details}
+}
+
+func splitRoleCode(value string) []string {
+	var codeList []string
+	for _, code := range strings.Split(value, roleSeparator) {
+		code = strings.TrimSpace(code)
+		if code != "" {
+			codeList = append(codeList, code)
+		}
+	}
+	return codeList
+}
+
+// readSheet reads the rows of a CSV or XLSX body, told apart by its
+// Content-Type, and writes the error response itself like bind.
+func readSheet(w http.ResponseWriter, r *http.Request) ([][]string, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != csvType && mediaType != xlsxType) {
+		Err(w, errcode.UnsupportedMediaType)
+		return nil, errBind
+	}
+	body := http.MaxBytesReader(w, r.Body, maxBodyBytes())
+
+	var rowList [][]string
+	if mediaType == csvType {
+		reader := csv.NewReader(body)
+		reader.FieldsPerRecord = -1
+		rowList, err = reader.ReadAll()
+		// Excel puts a byte order mark before UTF-8 CSV
+		if err == nil && len(rowList) > 0 && len(rowList[0]) > 0 {
+			rowList[0][0] = strings.TrimPrefix(rowList[0][0], "\ufeff")
+		}
+		for _, row := range rowList {
+			for i, value := range row {
+				row[i] = unescapeCell(value)
+			}
+		}
+	} else {
+		var f *excelize.File
+		// the body limit holds the compressed size only
+		f, err = excelize.OpenReader(body, excelize.Options{
+			UnzipSizeLimit:    maxSheetUnzipBytes(),
+			UnzipXMLSizeLimit: maxSheetUnzipBytes(),
+		})
+		if err == nil {
+			rowList, err = f.GetRows(f.GetSheetName(0))
+			_ = f.Close()
+		}
+	}
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		Err(w, errcode.BodyTooLarge)
+		return nil, err
+	}
+	if err != nil {
+		Err(w, errcode.SheetInvalid)
+		return nil, err
+	}
+	return rowList, nil
+}
+
+// sheetWriter writes rows as CSV or XLSX, Flush hands the rows written so
+// far to the client where the format allows.
+type sheetWriter interface {
+	Write(row []string) error
+	Flush() error
+	Close() error
+}
+
+func newSheetWriter(w http.ResponseWriter, format GetApiV1UsersExportParamsFormat) (sheetWriter, error) {
+	if format == Xlsx {
+		f := excelize.NewFile()
+		stream, err := f.NewStreamWriter(f.GetSheetName(0))
+		if err != nil {
+			return nil, err
+		}
+		w.Header().Set("Content-Type", xlsxType)
+		w.Header().Set("Content-Disposition", `attachment; filename="user.xlsx"`)
+		return &xlsxWriter{w: w, file: f, stream: stream}, nil
+	}
+	w.Header().Set("Content-Type", csvType)
+	w.Header().Set("Content-Disposition", `attachment; filename="user.csv"`)
+	return &csvWriter{w: w, writer: csv.NewWriter(w)}, nil
+}
+
+type csvWriter struct {
+	w      http.ResponseWriter
+	writer *csv.Writer
+}
+
+func (c *csvWriter) Write(row []string) error {
+	escaped := make([]string, len(row))
+	for i, value := range row {
+		escaped[i] = escapeCell(value)
+	}
+	return c.writer.Write(escaped)
+}
+
+func (c *csvWriter) Flush() error {
+	c.writer.Flush()
+	err := c.writer.Error()
+	if err != nil {
+		return err
+	}
+	return http.NewResponseController(c.w).Flush()
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// xlsxWriter streams the rows into the workbook, which can only be sent
+// once complete.
+type xlsxWriter struct {
+	w      http.ResponseWriter
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (x *xlsxWriter) Write(row []string) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return x.stream.SetRow(cell, values)
+}
+
+func (x *xlsxWriter) Flush() error {
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	err := x.stream.Flush()
+	if err != nil {
+		return err
+	}
+	return x.file.Write(x.w)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/linehk/go-admin/config"
+)
+
+const tokenType = "Bearer"
+
+func accessTokenExpire() time.Duration {
+	return time.Duration(config.Raw.Int("ACCESS_TOKEN_EXPIRE")) * time.Second
+}
+
+func refreshTokenExpire() time.Duration {
+	return time.Duration(config.Raw.Int("REFRESH_TOKEN_EXPIRE")) * time.Second
+}
+
+var errJWTSecret = errors.New("JWT_SECRET is empty")
+
+// jwtSecret refuses an empty key, anyone could sign tokens with it.
+func jwtSecret() ([]byte, error) {
+	secret := config.Raw.String("JWT_SECRET")
+	if secret == "" {
+		return nil, errJWTSecret
+	}
+	return []byte(secret), nil
+}
+
+// accessClaims carries the session the token is issued for, so that
+// revoking the session refuses the token before it expires.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID int32 `json:"sid"`
+}
+
+// newAccessToken signs a short-lived JWT whose subject is the user id.
+func newAccessToken(userID, sessionID int32, now time.Time) (string, error) {
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(int(userID)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenExpire())),
+		},
+		SessionID: sessionID,
+	}
+	secret, err := jwtSecret()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(secret)
+}
+
+// parseAccessToken verifies the signature and expiry and returns the user id
+// and the session id.
+func parseAccessToken(accessToken string) (int32, int32, error) {
+	var claims accessClaims
+	_, err := jwt.ParseWithClaims(accessToken, &claims, func(*jwt.Token) (any, error) {
+		return jwtSecret()
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, 0, err
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int32(userID), claims.SessionID, nil
+}
+
+// newRefreshToken returns an opaque random token, only its hash is stored.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
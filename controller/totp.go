@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linehk/go-admin/config"
+)
+
+// TOTP as RFC 6238 describes it with the parameters authenticator apps
+// assume: HMAC-SHA1, 6 digits and a 30 second period.
+const (
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	// codes of the periods next to the current one are accepted as well,
+	// for clocks that drift a little
+	totpSkew = 1
+
+	defaultTOTPIssuer = "go-admin"
+
+	recoveryCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func totpIssuer() string {
+	issuer := config.Raw.String("TOTP_ISSUER")
+	if issuer == "" {
+		return defaultTOTPIssuer
+	}
+	return issuer
+}
+
+// newTOTPSecret returns a random 160 bit secret in base32.
+func newTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// otpauthURI is the key URI authenticator apps scan as a QR code.
+func otpauthURI(secret, username string) string {
+	issuer := totpIssuer()
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(totpDigits))
+	values.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	label := url.PathEscape(issuer + ":" + username)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+// totpCode is the HOTP value (RFC 4226) of the secret at counter.
+func totpCode(key []byte, counter uint64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// verifyTOTP reports whether code is the one of secret at now, or of the
+// periods next to it, and returns the period it is the one of.
+func verifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) == 0 || len(code) != totpDigits {
+		return 0, false
+	}
+	counter := uint64(now.Unix()) / uint64(totpPeriod.Seconds())
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		step := counter + uint64(skew)
+		expected := totpCode(key, step)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return int64(step), true
+		}
+	}
+	return 0, false
+}
+
+// newRecoveryCode returns a random code such as 4f7qx-m2kzp.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode hashes the code the way it is stored, ignoring case,
+// spaces and dashes so that it can be typed back as it was shown.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return hashToken(code)
+}
@@ -0,0 +1,462 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultLoginChallengeExpire = 5 * time.Minute
+
+	reasonCodeIncorrect = "code_incorrect"
+)
+
+func loginChallengeExpire() time.Duration {
+	expire := config.Raw.Int("LOGIN_CHALLENGE_EXPIRE")
+	if expire <= 0 {
+		return defaultLoginChallengeExpire
+	}
+	return time.Duration(expire) * time.Second
+}
+
+func (a *API) PostApiV1Auth2fa(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	var req TwoFactorLogin
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	query := model.New(a.DB)
+
+	// a challenge is good for one code, a wrong one means logging in again
+	challenge, err := query.UseLoginChallenge(ctx, hashToken(req.Challenge))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) || now.After(challenge.Expired.Time) {
+		Err(w, errcode.ChallengeInvalid)
+		return
+	}
+
+	user, err := query.GetUser(ctx, challenge.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	// 2fa was turned off since the challenge was issued
+	if errors.Is(err, pgx.ErrNoRows) || !user.TotpEnabled {
+		Err(w, errcode.ChallengeInvalid)
+		return
+	}
+	userID := pgtype.Int4{Int32: user.ID, Valid: true}
+
+	userUntil, err := lockedUntil(ctx, query, attemptUser, strconv.Itoa(int(user.ID)), now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !userUntil.IsZero() {
+		err = writeLoginLog(r, query, userID, user.Username, LoginFailure, reasonUserLocked, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		loginLocked(w, userUntil, now)
+		return
+	}
+
+	// wrong codes count as failed logins, so guessing them runs into the lockout
+	ok, err := verifySecondFactor(ctx, query, user, req.Code, now)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !ok {
+		err = failLogin(r, query, userID, user.Username, reasonCodeIncorrect, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		Err(w, errcode.TwoFactorCodeIncorrect)
+		return
+	}
+
+	if UserStatus(user.Status) == Frozen {
+		err = writeLoginLog(r, query, userID, user.Username, LoginFailure, reasonUserFrozen, now)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		Err(w, errcode.UserFrozen)
+		return
+	}
+
+	completeLogin(w, r, query, user, now)
+}
+
+func (a *API) PostApiV1Me2faEnroll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		Err(w, errcode.TokenInvalid)
+		return
+	}
+
+	query := model.New(a.DB)
+
+	user, err := query.GetUser(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+	if user.TotpEnabled {
+		Err(w, errcode.TwoFactorEnabled)
+		return
+	}
+
+	// enrolling again replaces a secret that was never verified
+	secret, err := newTOTPSecret()
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	_, err = setTOTP(ctx, query, userID, secret, false)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var resp TOTPEnrollment
+	resp.Secret = secret
+	resp.OtpauthUri = otpauthURI(secret, user.Username)
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1Me2faVerify(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		Err(w, errcode.TokenInvalid)
+		return
+	}
+
+	var req TOTPCode
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	_, err = query.LockUserVersion(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	userByGet, err := query.GetUser(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if userByGet.TotpEnabled {
+		Err(w, errcode.TwoFactorEnabled)
+		return
+	}
+	if userByGet.TotpSecret == "" {
+		Err(w, errcode.TwoFactorNotEnrolled)
+		return
+	}
+	ok, err = useTOTP(ctx, query, userByGet, req.Code, time.Now())
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !ok {
+		Err(w, errcode.TwoFactorCodeIncorrect)
+		return
+	}
+
+	userBySet, err := setTOTP(ctx, query, userID, userByGet.TotpSecret, true)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	var resp RecoveryCodes
+	resp.RecoveryCodes, err = newRecoveryCodeList(ctx, query, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Update, auditUser, userID, userResp(userByGet), userResp(userBySet))
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	encode(w, resp)
+}
+
+func (a *API) PostApiV1Me2faDisable(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		Err(w, errcode.TokenInvalid)
+		return
+	}
+
+	var req TOTPDisable
+	err := bind(w, r, &req)
+	if err != nil {
+		return
+	}
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	_, err = query.LockUserVersion(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	userByGet, err := query.GetUser(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !userByGet.TotpEnabled {
+		Err(w, errcode.TwoFactorNotEnrolled)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(userByGet.Password), []byte(req.Password))
+	if err != nil {
+		Err(w, errcode.PasswordIncorrect)
+		return
+	}
+
+	required, err := query.CheckUserRequire2FA(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if required {
+		Err(w, errcode.TwoFactorRequired)
+		return
+	}
+
+	ok, err = verifySecondFactor(ctx, query, userByGet, req.Code, time.Now())
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	if !ok {
+		Err(w, errcode.TwoFactorCodeIncorrect)
+		return
+	}
+
+	userBySet, err := setTOTP(ctx, query, userID, "", false)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = query.DeleteRecoveryCodeByUserID(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	// the open challenges would be answered against no secret
+	err = query.DeleteLoginChallengeByUserID(ctx, userID)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Update, auditUser, userID, userResp(userByGet), userResp(userBySet))
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+}
+
+// verifySecondFactor reports whether code is the current totp code of the
+// user or one of its unused recovery codes, which is used up then.
+func verifySecondFactor(ctx context.Context, query *model.Queries, user model.AppUser, code string, now time.Time) (bool, error) {
+	ok, err := useTOTP(ctx, query, user, code, now)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	params := model.UseRecoveryCodeParams{
+		UserID: user.ID,
+		Code:   hashRecoveryCode(code),
+		UsedAt: pgtype.Timestamp{Time: now, Valid: true},
+	}
+	rows, err := query.UseRecoveryCode(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// useTOTP reports whether code is a totp code of the user that was not used
+// yet. A code is used once, neither it nor one of an earlier period is taken
+// afterwards, so that a code seen in passing cannot be replayed.
+func useTOTP(ctx context.Context, query *model.Queries, user model.AppUser, code string, now time.Time) (bool, error) {
+	step, ok := verifyTOTP(user.TotpSecret, code, now)
+	if !ok {
+		return false, nil
+	}
+
+	params := model.UseUserTOTPStepParams{
+		ID:           user.ID,
+		TotpLastStep: step,
+	}
+	rows, err := query.UseUserTOTPStep(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func setTOTP(ctx context.Context, query *model.Queries, userID int32, secret string, enabled bool) (model.AppUser, error) {
+	params := model.SetUserTOTPParams{
+		ID:          userID,
+		TotpSecret:  secret,
+		TotpEnabled: enabled,
+		Updated:     pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	return query.SetUserTOTP(ctx, params)
+}
+
+// newRecoveryCodeList replaces the recovery codes of the user with new ones
+// and returns them, only their hashes are stored.
+func newRecoveryCodeList(ctx context.Context, query *model.Queries, userID int32) ([]string, error) {
+	err := query.DeleteRecoveryCodeByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	var codeList []string
+	var paramsList []model.CopyRecoveryCodeParams
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codeList = append(codeList, code)
+		paramsList = append(paramsList, model.CopyRecoveryCodeParams{
+			UserID:  userID,
+			Code:    hashRecoveryCode(code),
+			Created: now,
+		})
+	}
+
+	_, err = query.CopyRecoveryCode(ctx, paramsList)
+	if err != nil {
+		return nil, err
+	}
+	return codeList, nil
+}
+
+// newLoginChallenge stores a challenge for a login whose password is right,
+// only its hash is stored like for refresh tokens. It replaces the earlier
+// challenges of the user, so one is open at a time.
+func newLoginChallenge(ctx context.Context, query *model.Queries, userID int32, now time.Time) (string, error) {
+	err := query.DeleteLoginChallengeByUserID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	challenge, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	var params model.CreateLoginChallengeParams
+	params.UserID = userID
+	params.Token = hashToken(challenge)
+	params.Expired = pgtype.Timestamp{Time: now.Add(loginChallengeExpire()), Valid: true}
+	params.Created = pgtype.Timestamp{Time: now, Valid: true}
+	err = query.CreateLoginChallenge(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
@@ -1,12 +1,13 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/linehk/go-admin/errcode"
 	"github.com/linehk/go-admin/model"
 	"golang.org/x/crypto/bcrypt"
@@ -17,10 +18,12 @@ func (a *API) PostApiV1Users(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	var req User
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
+		return
+	}
+	if req.Password == "" {
+		writeErr(w, errcode.Validate, passwordRequired)
 		return
 	}
 
@@ -37,26 +40,16 @@ func (a *API) PostApiV1Users(w http.ResponseWriter, r *http.Request) {
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	exist, err := query.CheckUserByUsername(ctx, params.Username)
-	if err != nil {
-		Err(w, errcode.Database)
-		return
-	}
-	if exist {
-		Err(w, errcode.UsernameOccupy)
-		return
-	}
-
 	user, err := query.CreateUser(ctx, params)
 	if err != nil {
-		Err(w, errcode.Database)
+		dbErr(w, err)
 		return
 	}
 
@@ -70,21 +63,29 @@ func (a *API) PostApiV1Users(w http.ResponseWriter, r *http.Request) {
 
 		userRole, err := query.CreateUserRole(ctx, params)
 		if err != nil {
-			Err(w, errcode.Database)
+			dbErr(w, err)
 			return
 		}
 
 		userRoleList = append(userRoleList, userRoleResp(userRole))
 	}
 
+	resp := userResp(user)
+	resp.Role = userRoleList
+
+	err = writeAudit(r, query, Create, auditUser, user.ID, nil, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
 	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	resp := userResp(user)
-	resp.Role = userRoleList
+	w.Header().Set("ETag", etag(user.Version))
 	encode(w, resp)
 }
 
@@ -92,28 +93,46 @@ func (a *API) GetApiV1Users(w http.ResponseWriter, r *http.Request, params GetAp
 	w.Header().Set("Content-Type", "application/json")
 	ctx := r.Context()
 
-	err := validator.New().Struct(params)
+	err := validate.Struct(params)
 	if err != nil {
-		Err(w, errcode.Validate)
+		validateErr(w, err)
 		return
 	}
 
-	var modelParams model.ListUserParams
-	modelParams.Column1 = params.Username
-	modelParams.Column2 = params.Name
-	modelParams.Column3 = params.Status
-	modelParams.ID, modelParams.Limit = paging(params.Current, params.PageSize)
+	option, err := listOption(w, model.UserColumn, params.Filter, params.Sort, params.Cursor, userSort)
+	if err != nil {
+		return
+	}
+	option.IncludeDeleted = params.IncludeDeleted
+	pageSize := pageLimit(params.PageSize)
+	// one more row tells whether there is a next page
+	option.Limit = pageSize + 1
 
 	query := model.New(a.DB)
 
-	userList, err := query.ListUser(ctx, modelParams)
+	userList, err := query.ListUserBy(ctx, option)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
+	var resp UserPage
+	if len(userList) > int(pageSize) {
+		userList = userList[:pageSize]
+		resp.NextCursor = encodeCursor(cursor{Sort: params.Sort, After: model.After(option.Sort, userList[len(userList)-1])})
+	}
+
+	if params.Total {
+		total, err := query.CountUserBy(ctx, option)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		resp.Total = &total
+	}
+
 	var userIDList []int32
-	var respList []User
+	respList := make([]User, 0, len(userList))
 	for _, user := range userList {
 		userIDList = append(userIDList, user.ID)
 		respList = append(respList, userResp(user))
@@ -134,7 +153,8 @@ func (a *API) GetApiV1Users(w http.ResponseWriter, r *http.Request, params GetAp
 		respList[i].Role = userIDToUserRoleList[*respList[i].Id]
 	}
 
-	encode(w, respList)
+	resp.Items = respList
+	encode(w, resp)
 }
 
 func (a *API) DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -148,30 +168,63 @@ func (a *API) DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request, id int3
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	exist, err := query.CheckUserByID(ctx, id)
-	if err != nil {
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	userByGet, err := userWithRole(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
 	}
-	if !exist {
+	if errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.UserNotExist)
 		return
 	}
 
-	err = query.DeleteUser(ctx, id)
+	if rootProtected(ctx, w, query, id) {
+		return
+	}
+
+	// the rows are kept until purged, so that the user can be restored
+	deletedAt := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	err = query.SoftDeleteUser(ctx, model.SoftDeleteUserParams{ID: id, DeletedAt: deletedAt})
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	err = query.DeleteUserRoleByUserID(ctx, id)
+	err = query.SoftDeleteUserRoleByUserID(ctx, model.SoftDeleteUserRoleByUserIDParams{UserID: id, DeletedAt: deletedAt})
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = query.DeleteSessionByUserID(ctx, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Delete, auditUser, id, userByGet, nil)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
@@ -182,6 +235,8 @@ func (a *API) DeleteApiV1UsersId(w http.ResponseWriter, r *http.Request, id int3
 		Err(w, errcode.Database)
 		return
 	}
+
+	a.invalidateUserPermission(ctx, id)
 }
 
 func (a *API) GetApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32) {
@@ -190,7 +245,8 @@ func (a *API) GetApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 
 	query := model.New(a.DB)
 
-	user, err := query.GetUser(ctx, id)
+	// read before the user, so the ETag can only be older than the body
+	version, err := query.GetUserVersion(ctx, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -199,20 +255,21 @@ func (a *API) GetApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.UserNotExist)
 		return
 	}
+	if ifNoneMatch(w, r, version) {
+		return
+	}
 
-	userRoleList, err := query.ListUserRoleByUserIDList(ctx, []int32{user.ID})
-	if err != nil {
+	resp, err := userWithRole(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
 	}
-
-	var userRoleListResp []UserRole
-	for _, userRole := range userRoleList {
-		userRoleListResp = append(userRoleListResp, userRoleResp(userRole))
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
 	}
 
-	resp := userResp(user)
-	resp.Role = userRoleListResp
+	w.Header().Set("ETag", etag(version))
 	encode(w, resp)
 }
 
@@ -221,10 +278,8 @@ func (a *API) PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 	ctx := r.Context()
 
 	var req User
-	decode(w, r, &req)
-	err := validator.New().Struct(req)
+	err := bind(w, r, &req)
 	if err != nil {
-		Err(w, errcode.Validate)
 		return
 	}
 
@@ -241,14 +296,28 @@ func (a *API) PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 	}
 	defer func() {
 		err := transaction.Rollback(ctx)
-		if !errors.Is(err, pgx.ErrTxClosed) {
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
 			panic(err)
 		}
 	}()
 
 	query := model.New(transaction)
 
-	userByGet, err := query.GetUser(ctx, id)
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	userByGet, err := userWithRole(ctx, query, id)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		Err(w, errcode.Database)
 		return
@@ -257,16 +326,25 @@ func (a *API) PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 		Err(w, errcode.UserNotExist)
 		return
 	}
+	if req.Username != userByGet.Username && rootProtected(ctx, w, query, id) {
+		return
+	}
 
-	// update username
-	if req.Username != userByGet.Username {
-		exist, err := query.CheckUserByUsername(ctx, req.Username)
+	if req.Password != "" {
+		reused, err := rotatePassword(ctx, query, id, req.Password)
 		if err != nil {
 			Err(w, errcode.Database)
 			return
 		}
-		if exist {
-			Err(w, errcode.UsernameOccupy)
+		if reused {
+			passwordReused(w, "password")
+			return
+		}
+
+		// a new password signs the user out everywhere, as setPassword does
+		err = query.DeleteSessionByUserID(ctx, id)
+		if err != nil {
+			Err(w, errcode.Database)
 			return
 		}
 	}
@@ -274,6 +352,12 @@ func (a *API) PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 	params.ID = id
 
 	userByUpdate, err := query.UpdateUser(ctx, params)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	err = endFrozenSessions(ctx, query, userByUpdate)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
@@ -295,24 +379,353 @@ func (a *API) PutApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32)
 
 		userRole, err := query.CreateUserRole(ctx, params)
 		if err != nil {
-			Err(w, errcode.Database)
+			dbErr(w, err)
 			return
 		}
 		
 		userRoleList = append(userRoleList, userRoleResp(userRole))
 	}
 
+	resp := userResp(userByUpdate)
+	resp.Role = userRoleList
+
+	err = writeAudit(r, query, Update, auditUser, id, userByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
 	err = transaction.Commit(ctx)
 	if err != nil {
 		Err(w, errcode.Database)
 		return
 	}
 
-	resp := userResp(userByUpdate)
+	a.invalidateUserPermission(ctx, id)
+
+	w.Header().Set("ETag", etag(userByUpdate.Version))
+	encode(w, resp)
+}
+
+func (a *API) PatchApiV1UsersId(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	// the row stays locked, so the version cannot move before the write
+	version, err := query.LockUserVersion(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+	if !ifMatch(w, r, version) {
+		return
+	}
+
+	userByGet, err := userWithRole(ctx, query, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	var req User
+	p, err := bindPatch(w, r, userByGet, &req)
+	if err != nil {
+		return
+	}
+	if req.Username != userByGet.Username && rootProtected(ctx, w, query, id) {
+		return
+	}
+
+	if req.Password != "" {
+		reused, err := rotatePassword(ctx, query, id, req.Password)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+		if reused {
+			passwordReused(w, "password")
+			return
+		}
+
+		// a new password signs the user out everywhere, as setPassword does
+		err = query.DeleteSessionByUserID(ctx, id)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+	}
+
+	params, err := patchUserParams(p, req)
+	if err != nil {
+		Err(w, errcode.Convert)
+		return
+	}
+	params.ID = id
+
+	userByPatch, err := query.PatchUser(ctx, params)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	err = endFrozenSessions(ctx, query, userByPatch)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	userRoleList := userByGet.Role
+	if p.has("role") {
+		err = query.DeleteUserRoleByUserID(ctx, id)
+		if err != nil {
+			Err(w, errcode.Database)
+			return
+		}
+
+		userRoleList = nil
+		for _, req := range req.Role {
+			params, err := createUserRoleParams(req, id)
+			if err != nil {
+				Err(w, errcode.Convert)
+				return
+			}
+
+			userRole, err := query.CreateUserRole(ctx, params)
+			if err != nil {
+				dbErr(w, err)
+				return
+			}
+
+			userRoleList = append(userRoleList, userRoleResp(userRole))
+		}
+	}
+
+	resp := userResp(userByPatch)
 	resp.Role = userRoleList
+
+	err = writeAudit(r, query, Update, auditUser, id, userByGet, resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidateUserPermission(ctx, id)
+
+	w.Header().Set("ETag", etag(userByPatch.Version))
 	encode(w, resp)
 }
 
+func (a *API) PostApiV1UsersIdRestore(w http.ResponseWriter, r *http.Request, id int32) {
+	w.Header().Set("Content-Type", "application/json")
+	ctx := r.Context()
+
+	transaction, err := a.DB.Begin(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+	defer func() {
+		err := transaction.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			panic(err)
+		}
+	}()
+
+	query := model.New(transaction)
+
+	userByGet, err := query.GetDeletedUser(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.Database)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		Err(w, errcode.UserNotExist)
+		return
+	}
+
+	restoreParams := model.RestoreUserParams{
+		ID:      id,
+		Updated: pgtype.Timestamp{Time: time.Now(), Valid: true},
+	}
+	userByRestore, err := query.RestoreUser(ctx, restoreParams)
+	if err != nil {
+		dbErr(w, err)
+		return
+	}
+
+	// only the roles deleted along with the user come back
+	restoreUserRoleParams := model.RestoreUserRoleByUserIDParams{
+		UserID:    id,
+		DeletedAt: userByGet.DeletedAt,
+	}
+	err = query.RestoreUserRoleByUserID(ctx, restoreUserRoleParams)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	resp, err := userWithRole(ctx, query, id)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = writeAudit(r, query, Restore, auditUser, id, userResp(userByGet), resp)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	err = transaction.Commit(ctx)
+	if err != nil {
+		Err(w, errcode.Database)
+		return
+	}
+
+	a.invalidateUserPermission(ctx, id)
+
+	w.Header().Set("ETag", etag(userByRestore.Version))
+	encode(w, resp)
+}
+
+var userBatch = batchEntity{
+	create:     (*API).PostApiV1Users,
+	update:     (*API).PutApiV1UsersId,
+	patch:      (*API).PatchApiV1UsersId,
+	delete:     (*API).DeleteApiV1UsersId,
+	createList: createUserList,
+}
+
+func (a *API) PostApiV1UsersBatch(w http.ResponseWriter, r *http.Request) {
+	a.batch(w, r, userBatch)
+}
+
+// createUserList inserts the users in one batch, their roles and audit
+// entries with one copy each.
+func createUserList(r *http.Request, query *model.Queries, bodyList [][]byte) ([]BatchResult, error) {
+	ctx := r.Context()
+
+	reqList := make([]User, len(bodyList))
+	paramsList := make([]model.CreateUserBatchParams, len(bodyList))
+	for i, body := range bodyList {
+		err := decodeItem(body, &reqList[i])
+		if err != nil {
+			return nil, err
+		}
+		if reqList[i].Password == "" {
+			return nil, errPasswordRequired
+		}
+		params, err := createUserParams(reqList[i])
+		if err != nil {
+			return nil, err
+		}
+		paramsList[i] = model.CreateUserBatchParams(params)
+	}
+
+	userList := make([]model.AppUser, len(paramsList))
+	var batchErr error
+	query.CreateUserBatch(ctx, paramsList).QueryRow(func(i int, user model.AppUser, err error) {
+		if err != nil && batchErr == nil {
+			batchErr = err
+		}
+		userList[i] = user
+	})
+	if batchErr != nil {
+		return nil, batchErr
+	}
+
+	var userIDList []int32
+	var userRoleParamsList []model.CopyUserRoleParams
+	for i, req := range reqList {
+		userIDList = append(userIDList, userList[i].ID)
+		for _, req := range req.Role {
+			params, err := createUserRoleParams(req, userList[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			userRoleParamsList = append(userRoleParamsList, model.CopyUserRoleParams(params))
+		}
+	}
+	_, err := query.CopyUserRole(ctx, userRoleParamsList)
+	if err != nil {
+		return nil, err
+	}
+
+	userRoleList, err := query.ListUserRoleByUserIDList(ctx, userIDList)
+	if err != nil {
+		return nil, err
+	}
+	userRoleMap := make(map[int32][]UserRole)
+	for _, userRole := range userRoleList {
+		userRoleMap[userRole.UserID] = append(userRoleMap[userRole.UserID], userRoleResp(userRole))
+	}
+
+	resultList := make([]BatchResult, len(userList))
+	var auditParamsList []model.CopyAuditLogParams
+	for i, user := range userList {
+		resp := userResp(user)
+		resp.Role = userRoleMap[user.ID]
+		params, err := auditParams(r, Create, auditUser, user.ID, nil, resp)
+		if err != nil {
+			return nil, err
+		}
+		auditParamsList = append(auditParamsList, model.CopyAuditLogParams(params))
+		resultList[i] = BatchResult{Status: http.StatusOK, Id: user.ID, Etag: etag(user.Version)}
+	}
+	_, err = query.CopyAuditLog(ctx, auditParamsList)
+	if err != nil {
+		return nil, err
+	}
+	return resultList, nil
+}
+
+// userWithRole reads the user with its roles, pgx.ErrNoRows if it does not exist.
+func userWithRole(ctx context.Context, query *model.Queries, id int32) (User, error) {
+	user, err := query.GetUser(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	userRoleList, err := query.ListUserRoleByUserIDList(ctx, []int32{user.ID})
+	if err != nil {
+		return User{}, err
+	}
+
+	resp := userResp(user)
+	for _, userRole := range userRoleList {
+		resp.Role = append(resp.Role, userRoleResp(userRole))
+	}
+	return resp, nil
+}
+
 func createUserParams(req User) (model.CreateUserParams, error) {
 	var params model.CreateUserParams
 	params.Username = req.Username
@@ -346,20 +759,24 @@ func createUserParams(req User) (model.CreateUserParams, error) {
 	return params, nil
 }
 
+// updateUserParams keeps the password when req has none, it is write only
+// and never part of what a client reads back and puts again.
 func updateUserParams(req User) (model.UpdateUserParams, error) {
 	var params model.UpdateUserParams
 	params.Username = req.Username
-	password, err := hash(req.Password)
-	if err != nil {
-		return model.UpdateUserParams{}, err
+	if req.Password != "" {
+		password, err := hash(req.Password)
+		if err != nil {
+			return model.UpdateUserParams{}, err
+		}
+		params.Password = pgtype.Text{String: password, Valid: true}
 	}
-	params.Password = password
 	params.Name = req.Name
 	params.Email = req.Email
 	params.Phone = req.Phone
 	params.Remark = req.Remark
 	params.Status = string(req.Status)
-	err = params.Created.Scan(req.Created)
+	err := params.Created.Scan(req.Created)
 	if err != nil {
 		return model.UpdateUserParams{}, err
 	}
@@ -373,8 +790,41 @@ func updateUserParams(req User) (model.UpdateUserParams, error) {
 	return params, nil
 }
 
+// patchUserParams sets only the fields p has, the password is never part of
+// the current user, so it is hashed only when the patch brings a new one.
+func patchUserParams(p patch, req User) (model.PatchUserParams, error) {
+	var params model.PatchUserParams
+	params.Username = pgtype.Text{String: req.Username, Valid: p.has("username")}
+	if req.Password != "" {
+		password, err := hash(req.Password)
+		if err != nil {
+			return model.PatchUserParams{}, err
+		}
+		params.Password = pgtype.Text{String: password, Valid: true}
+	}
+	params.Name = pgtype.Text{String: req.Name, Valid: p.has("name")}
+	params.Email = pgtype.Text{String: req.Email, Valid: p.has("email")}
+	params.Phone = pgtype.Text{String: req.Phone, Valid: p.has("phone")}
+	params.Remark = pgtype.Text{String: req.Remark, Valid: p.has("remark")}
+	params.Status = pgtype.Text{String: string(req.Status), Valid: p.has("status")}
+	if p.has("created") {
+		err := params.Created.Scan(req.Created)
+		if err != nil {
+			return model.PatchUserParams{}, err
+		}
+	}
+	if !p.has("updated") {
+		req.Updated = time.Now().Format(pgTimestampFormat)
+	}
+	err := params.Updated.Scan(req.Updated)
+	if err != nil {
+		return model.PatchUserParams{}, err
+	}
+	return params, nil
+}
+
 func hash(password string) (string, error) {
-	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost())
 	return string(h), err
 }
 
@@ -387,8 +837,13 @@ func userResp(m model.AppUser) User {
 	resp.Phone = m.Phone
 	resp.Remark = m.Remark
 	resp.Status = UserStatus(m.Status)
+	resp.MustChangePassword = m.MustChangePassword
+	resp.TotpEnabled = m.TotpEnabled
 	resp.Created = m.Created.Time.Format(pgTimestampFormat)
 	resp.Updated = m.Updated.Time.Format(pgTimestampFormat)
+	if m.DeletedAt.Valid {
+		resp.DeletedAt = m.DeletedAt.Time.Format(pgTimestampFormat)
+	}
 	return resp
 }
 
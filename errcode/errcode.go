@@ -1,19 +1,51 @@
 package errcode
 
+import "net/http"
+
 const (
 	Parse    int32 = 20000
 	Database int32 = 20001
 	Convert  int32 = 20002
 	Validate int32 = 20003
 
-	UsernameOccupy int32 = 30000
-	UserNotExist   int32 = 30001
+	UnsupportedMediaType int32 = 20004
+	BodyTooLarge         int32 = 20005
+	CursorInvalid        int32 = 20006
+	FilterInvalid        int32 = 20007
+	SortInvalid          int32 = 20008
+	PreconditionFailed   int32 = 20009
+	PreconditionRequired int32 = 20010
+	BatchTooLarge        int32 = 20011
+	BatchAborted         int32 = 20012
+	SheetInvalid         int32 = 20013
+
+	UsernameOccupy              int32 = 30000
+	UserNotExist                int32 = 30001
+	UsernameOrPasswordIncorrect int32 = 30002
+	UserFrozen                  int32 = 30003
+	PasswordIncorrect           int32 = 30004
+	PasswordChangeRequired      int32 = 30005
+	LoginLocked                 int32 = 30006
+	TwoFactorCodeIncorrect      int32 = 30007
+	TwoFactorEnabled            int32 = 30008
+	TwoFactorNotEnrolled        int32 = 30009
+	TwoFactorRequired           int32 = 30010
+	RootUserProtected           int32 = 30011
 
 	RoleCodeOccupy int32 = 40000
 	RoleNotExist   int32 = 40001
+	RoleCycle      int32 = 40002
 
 	MenuCodeOccupy int32 = 50000
 	MenuNotExist   int32 = 50001
+	MenuCycle      int32 = 50002
+	MenuNotSibling int32 = 50003
+
+	RefreshTokenInvalid int32 = 60000
+	TokenInvalid        int32 = 60001
+	PermissionDenied    int32 = 60002
+	ChallengeInvalid    int32 = 60003
+	SessionNotExist     int32 = 60004
 )
 
 var msg = map[int32]string{
@@ -22,16 +54,101 @@ var msg = map[int32]string{
 	Convert:  "convert error",
 	Validate: "validate error",
 
-	UsernameOccupy: "username occupy",
-	UserNotExist:   "user not exist",
+	UnsupportedMediaType: "unsupported media type",
+	BodyTooLarge:         "body too large",
+	CursorInvalid:        "cursor invalid",
+	FilterInvalid:        "filter invalid",
+	SortInvalid:          "sort invalid",
+	PreconditionFailed:   "precondition failed",
+	PreconditionRequired: "precondition required",
+	BatchTooLarge:        "batch too large",
+	BatchAborted:         "batch aborted",
+	SheetInvalid:         "sheet invalid",
+
+	UsernameOccupy:              "username occupy",
+	UserNotExist:                "user not exist",
+	UsernameOrPasswordIncorrect: "username or password incorrect",
+	UserFrozen:                  "user frozen",
+	PasswordIncorrect:           "password incorrect",
+	PasswordChangeRequired:      "password change required",
+	LoginLocked:                 "login locked",
+	TwoFactorCodeIncorrect:      "two factor code incorrect",
+	TwoFactorEnabled:            "two factor enabled",
+	TwoFactorNotEnrolled:        "two factor not enrolled",
+	TwoFactorRequired:           "two factor required",
+	RootUserProtected:           "root user cannot be deleted or renamed",
 
 	RoleCodeOccupy: "role code occupy",
 	RoleNotExist:   "role not exist",
+	RoleCycle:      "role cannot inherit from itself",
 
 	MenuCodeOccupy: "menu code occupy",
 	MenuNotExist:   "menu not exist",
+	MenuCycle:      "menu cannot move under itself",
+	MenuNotSibling: "menu not a child of the parent",
+
+	RefreshTokenInvalid: "refresh token invalid",
+	TokenInvalid:        "token invalid",
+	PermissionDenied:    "permission denied",
+	ChallengeInvalid:    "challenge invalid",
+	SessionNotExist:     "session not exist",
+}
+
+var status = map[int32]int{
+	Parse:    http.StatusBadRequest,
+	Database: http.StatusInternalServerError,
+	Convert:  http.StatusBadRequest,
+	Validate: http.StatusBadRequest,
+
+	UnsupportedMediaType: http.StatusUnsupportedMediaType,
+	BodyTooLarge:         http.StatusRequestEntityTooLarge,
+	CursorInvalid:        http.StatusBadRequest,
+	FilterInvalid:        http.StatusBadRequest,
+	SortInvalid:          http.StatusBadRequest,
+	PreconditionFailed:   http.StatusPreconditionFailed,
+	PreconditionRequired: http.StatusPreconditionRequired,
+	BatchTooLarge:        http.StatusRequestEntityTooLarge,
+	BatchAborted:         http.StatusFailedDependency,
+	SheetInvalid:         http.StatusBadRequest,
+
+	UsernameOccupy:              http.StatusConflict,
+	UserNotExist:                http.StatusNotFound,
+	UsernameOrPasswordIncorrect: http.StatusUnauthorized,
+	UserFrozen:                  http.StatusForbidden,
+	PasswordIncorrect:           http.StatusBadRequest,
+	PasswordChangeRequired:      http.StatusForbidden,
+	LoginLocked:                 http.StatusTooManyRequests,
+	TwoFactorCodeIncorrect:      http.StatusBadRequest,
+	TwoFactorEnabled:            http.StatusConflict,
+	TwoFactorNotEnrolled:        http.StatusConflict,
+	TwoFactorRequired:           http.StatusForbidden,
+	RootUserProtected:           http.StatusForbidden,
+
+	RoleCodeOccupy: http.StatusConflict,
+	RoleNotExist:   http.StatusNotFound,
+	RoleCycle:      http.StatusBadRequest,
+
+	MenuCodeOccupy: http.StatusConflict,
+	MenuNotExist:   http.StatusNotFound,
+	MenuCycle:      http.StatusBadRequest,
+	MenuNotSibling: http.StatusBadRequest,
+
+	RefreshTokenInvalid: http.StatusUnauthorized,
+	TokenInvalid:        http.StatusUnauthorized,
+	PermissionDenied:    http.StatusForbidden,
+	ChallengeInvalid:    http.StatusUnauthorized,
+	SessionNotExist:     http.StatusNotFound,
 }
 
 func Msg(e int32) string {
 	return msg[e]
 }
+
+// Status returns the HTTP status code answered with e.
+func Status(e int32) int {
+	s, ok := status[e]
+	if !ok {
+		return http.StatusInternalServerError
+	}
+	return s
+}
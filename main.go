@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/linehk/go-admin/config"
 	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/model"
 )
 
 func main() {
 	config.Setup()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		migrate(os.Args[2:])
+		return
+	}
+
 	handler := controller.Setup()
 
 	server := &http.Server{
@@ -22,3 +32,35 @@ func main() {
 	}
 	log.Fatal(server.ListenAndServe())
 }
+
+// migrate runs "migrate up" or "migrate down [steps]", down rolls back one
+// migration when steps is omitted.
+func migrate(args []string) {
+	ctx := context.Background()
+	pool := model.Setup(ctx, config.DSN())
+	defer pool.Close()
+
+	if len(args) == 0 {
+		log.Fatal("usage: migrate up | migrate down [steps]")
+	}
+
+	var err error
+	switch args[0] {
+	case "up":
+		err = model.MigrateUp(ctx, pool)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		err = model.MigrateDown(ctx, pool, steps)
+	default:
+		log.Fatalf("unknown migrate command %s", args[0])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,273 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: batch.go
+
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBatchAlreadyClosed = errors.New("batch already closed")
+)
+
+const createMenuBatch = `-- name: CreateMenuBatch :batchone
+INSERT INTO menu (code, name, description, sequence, type, path, property,
+parent_id, parent_path, status, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+`
+
+type CreateMenuBatchBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type CreateMenuBatchParams struct {
+	Code        string
+	Name        string
+	Description string
+	Sequence    int16
+	Type        string
+	Path        string
+	Property    string
+	ParentID    pgtype.Int4
+	ParentPath  string
+	Status      string
+	Created     pgtype.Timestamp
+	Updated     pgtype.Timestamp
+}
+
+func (q *Queries) CreateMenuBatch(ctx context.Context, arg []CreateMenuBatchParams) *CreateMenuBatchBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.Code,
+			a.Name,
+			a.Description,
+			a.Sequence,
+			a.Type,
+			a.Path,
+			a.Property,
+			a.ParentID,
+			a.ParentPath,
+			a.Status,
+			a.Created,
+			a.Updated,
+		}
+		batch.Queue(createMenuBatch, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &CreateMenuBatchBatchResults{br, len(arg), false}
+}
+
+func (b *CreateMenuBatchBatchResults) QueryRow(f func(int, Menu, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var i Menu
+		if b.closed {
+			if f != nil {
+				f(t, i, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		)
+		if f != nil {
+			f(t, i, err)
+		}
+	}
+}
+
+func (b *CreateMenuBatchBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
+
+const createRoleBatch = `-- name: CreateRoleBatch :batchone
+INSERT INTO role (code, name, description, sequence, status, require_2fa, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
+`
+
+type CreateRoleBatchBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type CreateRoleBatchParams struct {
+	Code        string
+	Name        string
+	Description string
+	Sequence    int16
+	Status      string
+	Require2fa  bool
+	Created     pgtype.Timestamp
+	Updated     pgtype.Timestamp
+}
+
+func (q *Queries) CreateRoleBatch(ctx context.Context, arg []CreateRoleBatchParams) *CreateRoleBatchBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.Code,
+			a.Name,
+			a.Description,
+			a.Sequence,
+			a.Status,
+			a.Require2fa,
+			a.Created,
+			a.Updated,
+		}
+		batch.Queue(createRoleBatch, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &CreateRoleBatchBatchResults{br, len(arg), false}
+}
+
+func (b *CreateRoleBatchBatchResults) QueryRow(f func(int, Role, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var i Role
+		if b.closed {
+			if f != nil {
+				f(t, i, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+			&i.Require2fa,
+		)
+		if f != nil {
+			f(t, i, err)
+		}
+	}
+}
+
+func (b *CreateRoleBatchBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
+
+const createUserBatch = `-- name: CreateUserBatch :batchone
+INSERT INTO app_user (username, password, name, email, phone, remark, status,
+must_change_password, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+`
+
+type CreateUserBatchBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+}
+
+type CreateUserBatchParams struct {
+	Username           string
+	Password           string
+	Name               string
+	Email              string
+	Phone              string
+	Remark             string
+	Status             string
+	MustChangePassword bool
+	Created            pgtype.Timestamp
+	Updated            pgtype.Timestamp
+}
+
+func (q *Queries) CreateUserBatch(ctx context.Context, arg []CreateUserBatchParams) *CreateUserBatchBatchResults {
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		vals := []interface{}{
+			a.Username,
+			a.Password,
+			a.Name,
+			a.Email,
+			a.Phone,
+			a.Remark,
+			a.Status,
+			a.MustChangePassword,
+			a.Created,
+			a.Updated,
+		}
+		batch.Queue(createUserBatch, vals...)
+	}
+	br := q.db.SendBatch(ctx, batch)
+	return &CreateUserBatchBatchResults{br, len(arg), false}
+}
+
+func (b *CreateUserBatchBatchResults) QueryRow(f func(int, AppUser, error)) {
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var i AppUser
+		if b.closed {
+			if f != nil {
+				f(t, i, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Password,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+			&i.Remark,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+			&i.MustChangePassword,
+			&i.TotpSecret,
+			&i.TotpEnabled,
+			&i.IsRoot,
+			&i.TotpLastStep,
+		)
+		if f != nil {
+			f(t, i, err)
+		}
+	}
+}
+
+func (b *CreateUserBatchBatchResults) Close() error {
+	b.closed = true
+	return b.br.Close()
+}
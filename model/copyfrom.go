@@ -0,0 +1,191 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.26.0
+// source: copyfrom.go
+
+package model
+
+import (
+	"context"
+)
+
+// iteratorForCopyAuditLog implements pgx.CopyFromSource.
+type iteratorForCopyAuditLog struct {
+	rows                 []CopyAuditLogParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyAuditLog) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyAuditLog) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ActorID,
+		r.rows[0].Action,
+		r.rows[0].Entity,
+		r.rows[0].EntityID,
+		r.rows[0].Before,
+		r.rows[0].After,
+		r.rows[0].Ip,
+		r.rows[0].RequestID,
+		r.rows[0].Created,
+	}, nil
+}
+
+func (r iteratorForCopyAuditLog) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyAuditLog(ctx context.Context, arg []CopyAuditLogParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"audit_log"}, []string{"actor_id", "action", "entity", "entity_id", "before", "after", "ip", "request_id", "created"}, &iteratorForCopyAuditLog{rows: arg})
+}
+
+// iteratorForCopyRecoveryCode implements pgx.CopyFromSource.
+type iteratorForCopyRecoveryCode struct {
+	rows                 []CopyRecoveryCodeParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyRecoveryCode) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyRecoveryCode) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].UserID,
+		r.rows[0].Code,
+		r.rows[0].Created,
+	}, nil
+}
+
+func (r iteratorForCopyRecoveryCode) Err() error {
+	return nil
+}
+
+// ----------------------------- RecoveryCode -------------------------------
+func (q *Queries) CopyRecoveryCode(ctx context.Context, arg []CopyRecoveryCodeParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"recovery_code"}, []string{"user_id", "code", "created"}, &iteratorForCopyRecoveryCode{rows: arg})
+}
+
+// iteratorForCopyResource implements pgx.CopyFromSource.
+type iteratorForCopyResource struct {
+	rows                 []CopyResourceParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyResource) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyResource) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].MenuID,
+		r.rows[0].Method,
+		r.rows[0].Path,
+		r.rows[0].Created,
+		r.rows[0].Updated,
+	}, nil
+}
+
+func (r iteratorForCopyResource) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyResource(ctx context.Context, arg []CopyResourceParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"resource"}, []string{"menu_id", "method", "path", "created", "updated"}, &iteratorForCopyResource{rows: arg})
+}
+
+// iteratorForCopyRoleMenu implements pgx.CopyFromSource.
+type iteratorForCopyRoleMenu struct {
+	rows                 []CopyRoleMenuParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyRoleMenu) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyRoleMenu) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RoleID,
+		r.rows[0].MenuID,
+		r.rows[0].Created,
+		r.rows[0].Updated,
+	}, nil
+}
+
+func (r iteratorForCopyRoleMenu) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyRoleMenu(ctx context.Context, arg []CopyRoleMenuParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"role_menu"}, []string{"role_id", "menu_id", "created", "updated"}, &iteratorForCopyRoleMenu{rows: arg})
+}
+
+// iteratorForCopyUserRole implements pgx.CopyFromSource.
+type iteratorForCopyUserRole struct {
+	rows                 []CopyUserRoleParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCopyUserRole) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCopyUserRole) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].UserID,
+		r.rows[0].RoleID,
+		r.rows[0].Created,
+		r.rows[0].Updated,
+	}, nil
+}
+
+func (r iteratorForCopyUserRole) Err() error {
+	return nil
+}
+
+func (q *Queries) CopyUserRole(ctx context.Context, arg []CopyUserRoleParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"user_role"}, []string{"user_id", "role_id", "created", "updated"}, &iteratorForCopyUserRole{rows: arg})
+}
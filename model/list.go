@@ -0,0 +1,300 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The list queries filter and sort on columns chosen by the client, which
+// sqlc cannot express, so they are built here. Only whitelisted columns
+// reach the SQL and every value is passed as a parameter.
+
+type ColumnType int
+
+const (
+	Text ColumnType = iota
+	Int
+	Timestamp
+)
+
+// Column is a column a list query may filter and sort on, Field is the
+// name of its field in the model.
+type Column struct {
+	Name  string
+	Field string
+	Type  ColumnType
+}
+
+const (
+	Eq   = "eq"
+	Ne   = "ne"
+	In   = "in"
+	Like = "like"
+	Gte  = "gte"
+	Lte  = "lte"
+	// Prefix is not part of the query language, it scopes menus to a subtree.
+	Prefix = "prefix"
+)
+
+// Op lists the operators allowed on each column type.
+var Op = map[ColumnType][]string{
+	Text:      {Eq, Ne, In, Like},
+	Int:       {Eq, Ne, In},
+	Timestamp: {Gte, Lte},
+}
+
+var (
+	created = Column{Name: "created", Field: "Created", Type: Timestamp}
+	updated = Column{Name: "updated", Field: "Updated", Type: Timestamp}
+	id      = Column{Name: "id", Field: "ID", Type: Int}
+
+	UserColumn = map[string]Column{
+		"username": {Name: "username", Field: "Username", Type: Text},
+		"name":     {Name: "name", Field: "Name", Type: Text},
+		"email":    {Name: "email", Field: "Email", Type: Text},
+		"phone":    {Name: "phone", Field: "Phone", Type: Text},
+		"status":   {Name: "status", Field: "Status", Type: Text},
+		"created":  created,
+		"updated":  updated,
+	}
+
+	RoleColumn = map[string]Column{
+		"code":     {Name: "code", Field: "Code", Type: Text},
+		"name":     {Name: "name", Field: "Name", Type: Text},
+		"sequence": {Name: "sequence", Field: "Sequence", Type: Int},
+		"status":   {Name: "status", Field: "Status", Type: Text},
+		"created":  created,
+		"updated":  updated,
+	}
+
+	MenuColumn = map[string]Column{
+		"code":        {Name: "code", Field: "Code", Type: Text},
+		"name":        {Name: "name", Field: "Name", Type: Text},
+		"sequence":    {Name: "sequence", Field: "Sequence", Type: Int},
+		"type":        {Name: "type", Field: "Type", Type: Text},
+		"path":        {Name: "path", Field: "Path", Type: Text},
+		"parent_path": {Name: "parent_path", Field: "ParentPath", Type: Text},
+		"status":      {Name: "status", Field: "Status", Type: Text},
+		"created":     created,
+		"updated":     updated,
+	}
+)
+
+type Filter struct {
+	Column Column
+	Op     string
+	// Value holds several values for In and one otherwise.
+	Value []string
+}
+
+type Sort struct {
+	Column Column
+	Desc   bool
+}
+
+type ListOption struct {
+	Filter []Filter
+	// Sort is followed by the id, so that the order is total.
+	Sort []Sort
+	// After holds the values of the Sort columns and the id of the last
+	// row of the previous page, empty for the first page.
+	After          []string
+	IncludeDeleted bool
+	Limit          int32
+}
+
+func (q *Queries) ListUserBy(ctx context.Context, option ListOption) ([]AppUser, error) {
+	return list[AppUser](ctx, q.db, "app_user", option)
+}
+
+func (q *Queries) CountUserBy(ctx context.Context, option ListOption) (int64, error) {
+	return count(ctx, q.db, "app_user", option)
+}
+
+func (q *Queries) ListRoleBy(ctx context.Context, option ListOption) ([]Role, error) {
+	return list[Role](ctx, q.db, "role", option)
+}
+
+func (q *Queries) CountRoleBy(ctx context.Context, option ListOption) (int64, error) {
+	return count(ctx, q.db, "role", option)
+}
+
+func (q *Queries) ListMenuBy(ctx context.Context, option ListOption) ([]Menu, error) {
+	return list[Menu](ctx, q.db, "menu", option)
+}
+
+func (q *Queries) CountMenuBy(ctx context.Context, option ListOption) (int64, error) {
+	return count(ctx, q.db, "menu", option)
+}
+
+// After returns the values of the sort columns and the id of row, in the
+// form ListOption.After takes them.
+func After(sort []Sort, row any) []string {
+	v := reflect.ValueOf(row)
+	var after []string
+	for _, s := range append(sort[:len(sort):len(sort)], Sort{Column: id}) {
+		switch field := v.FieldByName(s.Column.Field).Interface().(type) {
+		case string:
+			after = append(after, field)
+		case int16:
+			after = append(after, strconv.Itoa(int(field)))
+		case int32:
+			after = append(after, strconv.Itoa(int(field)))
+		case pgtype.Timestamp:
+			after = append(after, field.Time.Format("2006-01-02 15:04:05.999999"))
+		}
+	}
+	return after
+}
+
+func list[T any](ctx context.Context, db DBTX, table string, option ListOption) ([]T, error) {
+	var b builder
+	where, err := b.where(option, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	for _, s := range option.Sort {
+		order = append(order, s.Column.Name+direction(s.Desc))
+	}
+	order = append(order, "id"+direction(idDesc(option.Sort)))
+
+	sql := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY %s LIMIT %s",
+		table, where, strings.Join(order, ", "), b.arg(option.Limit))
+	rows, err := db.Query(ctx, sql, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[T])
+}
+
+func count(ctx context.Context, db DBTX, table string, option ListOption) (int64, error) {
+	var b builder
+	where, err := b.where(option, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = db.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s WHERE %s", table, where), b.args...).Scan(&total)
+	return total, err
+}
+
+type builder struct {
+	args []any
+}
+
+// arg adds a parameter and returns its placeholder.
+func (b *builder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *builder) where(option ListOption, keyset bool) (string, error) {
+	condition := []string{"TRUE"}
+	if !option.IncludeDeleted {
+		condition = append(condition, "deleted_at IS NULL")
+	}
+
+	for _, f := range option.Filter {
+		c, err := b.filter(f)
+		if err != nil {
+			return "", err
+		}
+		condition = append(condition, c)
+	}
+
+	if keyset && len(option.After) > 0 {
+		c, err := b.keyset(option.Sort, option.After)
+		if err != nil {
+			return "", err
+		}
+		condition = append(condition, c)
+	}
+
+	return strings.Join(condition, " AND "), nil
+}
+
+func (b *builder) filter(f Filter) (string, error) {
+	if len(f.Value) == 0 || (f.Op != In && len(f.Value) != 1) {
+		return "", fmt.Errorf("filter %s %s: want one value", f.Column.Name, f.Op)
+	}
+
+	column := f.Column.Name
+	cast := castOf(f.Column.Type)
+	switch f.Op {
+	case Eq:
+		return column + " = " + b.arg(f.Value[0]) + cast, nil
+	case Ne:
+		return column + " <> " + b.arg(f.Value[0]) + cast, nil
+	case In:
+		return column + " = ANY(" + b.arg(f.Value) + cast + "[])", nil
+	case Like:
+		return column + " ILIKE '%' || " + b.arg(escapeLike(f.Value[0])) + " || '%'", nil
+	case Gte:
+		return column + " >= " + b.arg(f.Value[0]) + cast, nil
+	case Lte:
+		return column + " <= " + b.arg(f.Value[0]) + cast, nil
+	case Prefix:
+		return column + " LIKE " + b.arg(escapeLike(f.Value[0])) + " || '%'", nil
+	}
+	return "", fmt.Errorf("filter %s: unknown operator %s", column, f.Op)
+}
+
+// keyset matches the rows after the given values in the order of sort
+// and id, (a > x) OR (a = x AND b < y) OR ... as directions may differ.
+func (b *builder) keyset(sort []Sort, after []string) (string, error) {
+	sort = append(sort[:len(sort):len(sort)], Sort{Column: id, Desc: idDesc(sort)})
+	if len(after) != len(sort) {
+		return "", fmt.Errorf("keyset: want %d values, got %d", len(sort), len(after))
+	}
+
+	var or []string
+	for i, s := range sort {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, sort[j].Column.Name+" = "+b.arg(after[j])+castOf(sort[j].Column.Type))
+		}
+		operator := " > "
+		if s.Desc {
+			operator = " < "
+		}
+		and = append(and, s.Column.Name+operator+b.arg(after[i])+castOf(s.Column.Type))
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return "(" + strings.Join(or, " OR ") + ")", nil
+}
+
+func castOf(t ColumnType) string {
+	switch t {
+	case Int:
+		return "::INTEGER"
+	case Timestamp:
+		return "::TIMESTAMP"
+	}
+	return "::VARCHAR"
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return ""
+}
+
+// idDesc follows the last sort column, so that the default orders can use
+// an index on (..., created DESC, id DESC).
+func idDesc(sort []Sort) bool {
+	return len(sort) > 0 && sort[len(sort)-1].Desc
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
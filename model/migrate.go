@@ -0,0 +1,192 @@
+package model
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migration/*.sql
+var migrationFS embed.FS
+
+// migrationLockID is the pg_advisory_lock key held while migrating, so that
+// instances starting together apply each migration only once.
+const migrationLockID = 7300412
+
+type migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrateUp applies every migration newer than the current version.
+func MigrateUp(ctx context.Context, pool *pgxpool.Pool) error {
+	return withMigrationLock(ctx, pool, func(conn *pgx.Conn) error {
+		migrationList, err := loadMigration()
+		if err != nil {
+			return err
+		}
+
+		applied, err := appliedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrationList {
+			if applied[m.Version] {
+				continue
+			}
+			err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, m.Up)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version, name, applied) VALUES ($1, $2, $3)",
+					m.Version, m.Name, time.Now())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate up %d_%s: %w", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown rolls back the latest steps applied migrations.
+func MigrateDown(ctx context.Context, pool *pgxpool.Pool, steps int) error {
+	return withMigrationLock(ctx, pool, func(conn *pgx.Conn) error {
+		migrationList, err := loadMigration()
+		if err != nil {
+			return err
+		}
+
+		applied, err := appliedVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrationList) - 1; i >= 0 && steps > 0; i-- {
+			m := migrationList[i]
+			if !applied[m.Version] {
+				continue
+			}
+			err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, m.Down)
+				if err != nil {
+					return err
+				}
+				_, err = tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrate down %d_%s: %w", m.Version, m.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+func withMigrationLock(ctx context.Context, pool *pgxpool.Pool, f func(conn *pgx.Conn) error) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// advisory locks belong to the session, so lock and unlock on the same connection
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version BIGINT PRIMARY KEY,
+  name VARCHAR NOT NULL,
+  applied TIMESTAMP NOT NULL
+)`)
+	if err != nil {
+		return err
+	}
+
+	return f(conn.Conn())
+}
+
+func appliedVersion(ctx context.Context, conn *pgx.Conn) (map[int64]bool, error) {
+	rows, err := conn.Query(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	versionList, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]bool)
+	for _, version := range versionList {
+		applied[version] = true
+	}
+	return applied, nil
+}
+
+// loadMigration reads the embedded files named like 000001_init.up.sql
+// and 000001_init.down.sql, sorted by version.
+func loadMigration() ([]migration, error) {
+	entryList, err := fs.ReadDir(migrationFS, "migration")
+	if err != nil {
+		return nil, err
+	}
+
+	versionToMigration := make(map[int64]*migration)
+	for _, entry := range entryList {
+		filename := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(filename, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration filename %s", filename)
+		}
+		versionText, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration filename %s: %w", filename, err)
+		}
+
+		content, err := migrationFS.ReadFile(path.Join("migration", filename))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := versionToMigration[version]
+		if !ok {
+			m = &migration{Version: version, Name: name}
+			versionToMigration[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	var migrationList []migration
+	for _, m := range versionToMigration {
+		migrationList = append(migrationList, *m)
+	}
+	sort.Slice(migrationList, func(i, j int) bool {
+		return migrationList[i].Version < migrationList[j].Version
+	})
+	return migrationList, nil
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS refresh_token;
+DROP TABLE IF EXISTS resource;
+DROP TABLE IF EXISTS menu;
+DROP TABLE IF EXISTS role_menu;
+DROP TABLE IF EXISTS role;
+DROP TABLE IF EXISTS user_role;
+DROP TABLE IF EXISTS app_user;
\ No newline at end of file
@@ -1,4 +1,4 @@
-CREATE TABLE app_user (
+CREATE TABLE IF NOT EXISTS app_user (
   id SERIAL PRIMARY KEY,
   username VARCHAR NOT NULL,
   password VARCHAR NOT NULL,
@@ -11,7 +11,7 @@ CREATE TABLE app_user (
   updated TIMESTAMP NOT NULL
 );
 
-CREATE TABLE user_role (
+CREATE TABLE IF NOT EXISTS user_role (
   id SERIAL PRIMARY KEY,
   user_id SERIAL NOT NULL,
   role_id SERIAL NOT NULL,
@@ -19,7 +19,7 @@ CREATE TABLE user_role (
   updated TIMESTAMP NOT NULL
 );
 
-CREATE TABLE role (
+CREATE TABLE IF NOT EXISTS role (
   id SERIAL PRIMARY KEY,
   code VARCHAR NOT NULL,
   name VARCHAR NOT NULL,
@@ -30,7 +30,7 @@ CREATE TABLE role (
   updated TIMESTAMP NOT NULL
 );
 
-CREATE TABLE role_menu (
+CREATE TABLE IF NOT EXISTS role_menu (
   id SERIAL PRIMARY KEY,
   role_id SERIAL NOT NULL,
   menu_id SERIAL NOT NULL,
@@ -38,7 +38,7 @@ CREATE TABLE role_menu (
   updated TIMESTAMP NOT NULL
 );
 
-CREATE TABLE menu (
+CREATE TABLE IF NOT EXISTS menu (
   id SERIAL PRIMARY KEY,
   code VARCHAR NOT NULL,
   name VARCHAR NOT NULL,
@@ -54,11 +54,21 @@ CREATE TABLE menu (
   updated TIMESTAMP NOT NULL
 );
 
-CREATE TABLE resource (
+CREATE TABLE IF NOT EXISTS resource (
   id SERIAL PRIMARY KEY,
   menu_id SERIAL NOT NULL,
   method VARCHAR NOT NULL,
   path VARCHAR NOT NULL,
   created TIMESTAMP NOT NULL,
   updated TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  token VARCHAR NOT NULL,
+  expired TIMESTAMP NOT NULL,
+  revoked BOOLEAN NOT NULL,
+  created TIMESTAMP NOT NULL,
+  updated TIMESTAMP NOT NULL
 );
\ No newline at end of file
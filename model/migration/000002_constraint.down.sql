@@ -0,0 +1,18 @@
+DROP INDEX refresh_token_user_id_idx;
+DROP INDEX resource_menu_id_idx;
+DROP INDEX menu_parent_path_idx;
+DROP INDEX role_menu_menu_id_idx;
+DROP INDEX user_role_role_id_idx;
+
+ALTER TABLE refresh_token DROP CONSTRAINT refresh_token_user_id_fkey, DROP CONSTRAINT refresh_token_token_key;
+ALTER TABLE resource DROP CONSTRAINT resource_menu_id_fkey;
+ALTER TABLE menu DROP CONSTRAINT menu_parent_id_fkey, DROP CONSTRAINT menu_parent_id_code_key;
+ALTER TABLE role_menu DROP CONSTRAINT role_menu_role_id_fkey, DROP CONSTRAINT role_menu_menu_id_fkey,
+  DROP CONSTRAINT role_menu_role_id_menu_id_key;
+ALTER TABLE user_role DROP CONSTRAINT user_role_user_id_fkey, DROP CONSTRAINT user_role_role_id_fkey,
+  DROP CONSTRAINT user_role_user_id_role_id_key;
+ALTER TABLE role DROP CONSTRAINT role_code_key;
+ALTER TABLE app_user DROP CONSTRAINT app_user_username_key;
+
+UPDATE menu SET parent_id = 0 WHERE parent_id IS NULL;
+ALTER TABLE menu ALTER COLUMN parent_id SET NOT NULL;
@@ -0,0 +1,50 @@
+-- link columns were declared SERIAL, drop their sequences
+ALTER TABLE user_role ALTER COLUMN user_id DROP DEFAULT, ALTER COLUMN role_id DROP DEFAULT;
+ALTER TABLE role_menu ALTER COLUMN role_id DROP DEFAULT, ALTER COLUMN menu_id DROP DEFAULT;
+ALTER TABLE menu ALTER COLUMN parent_id DROP DEFAULT;
+ALTER TABLE resource ALTER COLUMN menu_id DROP DEFAULT;
+DROP SEQUENCE IF EXISTS user_role_user_id_seq, user_role_role_id_seq, role_menu_role_id_seq,
+  role_menu_menu_id_seq, menu_parent_id_seq, resource_menu_id_seq;
+
+-- top level menus have no parent
+ALTER TABLE menu ALTER COLUMN parent_id DROP NOT NULL;
+UPDATE menu SET parent_id = NULL WHERE parent_id = 0;
+
+-- remove rows left behind by deletes before foreign keys existed
+DELETE FROM user_role WHERE user_id NOT IN (SELECT id FROM app_user) OR role_id NOT IN (SELECT id FROM role);
+DELETE FROM role_menu WHERE role_id NOT IN (SELECT id FROM role) OR menu_id NOT IN (SELECT id FROM menu);
+DELETE FROM resource WHERE menu_id NOT IN (SELECT id FROM menu);
+DELETE FROM refresh_token WHERE user_id NOT IN (SELECT id FROM app_user);
+UPDATE menu SET parent_id = NULL WHERE parent_id NOT IN (SELECT id FROM menu);
+DELETE FROM user_role a USING user_role b WHERE a.user_id = b.user_id AND a.role_id = b.role_id AND a.id > b.id;
+DELETE FROM role_menu a USING role_menu b WHERE a.role_id = b.role_id AND a.menu_id = b.menu_id AND a.id > b.id;
+
+ALTER TABLE app_user ADD CONSTRAINT app_user_username_key UNIQUE (username);
+ALTER TABLE role ADD CONSTRAINT role_code_key UNIQUE (code);
+ALTER TABLE menu ADD CONSTRAINT menu_parent_id_code_key UNIQUE NULLS NOT DISTINCT (parent_id, code);
+ALTER TABLE refresh_token ADD CONSTRAINT refresh_token_token_key UNIQUE (token);
+
+ALTER TABLE user_role
+  ADD CONSTRAINT user_role_user_id_fkey FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE,
+  ADD CONSTRAINT user_role_role_id_fkey FOREIGN KEY (role_id) REFERENCES role (id) ON DELETE CASCADE,
+  ADD CONSTRAINT user_role_user_id_role_id_key UNIQUE (user_id, role_id);
+
+ALTER TABLE role_menu
+  ADD CONSTRAINT role_menu_role_id_fkey FOREIGN KEY (role_id) REFERENCES role (id) ON DELETE CASCADE,
+  ADD CONSTRAINT role_menu_menu_id_fkey FOREIGN KEY (menu_id) REFERENCES menu (id) ON DELETE CASCADE,
+  ADD CONSTRAINT role_menu_role_id_menu_id_key UNIQUE (role_id, menu_id);
+
+ALTER TABLE menu
+  ADD CONSTRAINT menu_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES menu (id) ON DELETE CASCADE;
+
+ALTER TABLE resource
+  ADD CONSTRAINT resource_menu_id_fkey FOREIGN KEY (menu_id) REFERENCES menu (id) ON DELETE CASCADE;
+
+ALTER TABLE refresh_token
+  ADD CONSTRAINT refresh_token_user_id_fkey FOREIGN KEY (user_id) REFERENCES app_user (id) ON DELETE CASCADE;
+
+CREATE INDEX user_role_role_id_idx ON user_role (role_id);
+CREATE INDEX role_menu_menu_id_idx ON role_menu (menu_id);
+CREATE INDEX menu_parent_path_idx ON menu (parent_path varchar_pattern_ops);
+CREATE INDEX resource_menu_id_idx ON resource (menu_id);
+CREATE INDEX refresh_token_user_id_idx ON refresh_token (user_id);
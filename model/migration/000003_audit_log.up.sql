@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+  id SERIAL PRIMARY KEY,
+  actor_id INTEGER,
+  action VARCHAR NOT NULL,
+  entity VARCHAR NOT NULL,
+  entity_id INTEGER NOT NULL,
+  before JSONB,
+  after JSONB,
+  ip VARCHAR NOT NULL,
+  request_id VARCHAR NOT NULL,
+  created TIMESTAMP NOT NULL
+);
+
+-- actor_id has no foreign key, the log outlives the users it mentions
+CREATE INDEX audit_log_actor_id_idx ON audit_log (actor_id);
+CREATE INDEX audit_log_entity_entity_id_idx ON audit_log (entity, entity_id);
+CREATE INDEX audit_log_created_idx ON audit_log (created);
\ No newline at end of file
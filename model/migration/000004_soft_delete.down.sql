@@ -0,0 +1,22 @@
+-- soft deleted rows would break the unique constraints
+DELETE FROM user_role WHERE deleted_at IS NOT NULL;
+DELETE FROM role_menu WHERE deleted_at IS NOT NULL;
+DELETE FROM resource WHERE deleted_at IS NOT NULL;
+DELETE FROM app_user WHERE deleted_at IS NOT NULL;
+DELETE FROM role WHERE deleted_at IS NOT NULL;
+DELETE FROM menu WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS app_user_username_key, role_code_key, menu_parent_id_code_key,
+  user_role_user_id_role_id_key, role_menu_role_id_menu_id_key;
+ALTER TABLE app_user ADD CONSTRAINT app_user_username_key UNIQUE (username);
+ALTER TABLE role ADD CONSTRAINT role_code_key UNIQUE (code);
+ALTER TABLE menu ADD CONSTRAINT menu_parent_id_code_key UNIQUE NULLS NOT DISTINCT (parent_id, code);
+ALTER TABLE user_role ADD CONSTRAINT user_role_user_id_role_id_key UNIQUE (user_id, role_id);
+ALTER TABLE role_menu ADD CONSTRAINT role_menu_role_id_menu_id_key UNIQUE (role_id, menu_id);
+
+ALTER TABLE app_user DROP COLUMN deleted_at;
+ALTER TABLE user_role DROP COLUMN deleted_at;
+ALTER TABLE role DROP COLUMN deleted_at;
+ALTER TABLE role_menu DROP COLUMN deleted_at;
+ALTER TABLE menu DROP COLUMN deleted_at;
+ALTER TABLE resource DROP COLUMN deleted_at;
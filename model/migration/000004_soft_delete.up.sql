@@ -0,0 +1,27 @@
+ALTER TABLE app_user ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE user_role ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE role ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE role_menu ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE menu ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE resource ADD COLUMN deleted_at TIMESTAMP;
+
+-- soft deleted rows must not occupy unique values, the indexes keep the
+-- constraint names so that errors map onto the same errcodes
+ALTER TABLE app_user DROP CONSTRAINT app_user_username_key;
+CREATE UNIQUE INDEX app_user_username_key ON app_user (username) WHERE deleted_at IS NULL;
+ALTER TABLE role DROP CONSTRAINT role_code_key;
+CREATE UNIQUE INDEX role_code_key ON role (code) WHERE deleted_at IS NULL;
+ALTER TABLE menu DROP CONSTRAINT menu_parent_id_code_key;
+CREATE UNIQUE INDEX menu_parent_id_code_key ON menu (parent_id, code) NULLS NOT DISTINCT WHERE deleted_at IS NULL;
+ALTER TABLE user_role DROP CONSTRAINT user_role_user_id_role_id_key;
+CREATE UNIQUE INDEX user_role_user_id_role_id_key ON user_role (user_id, role_id) WHERE deleted_at IS NULL;
+ALTER TABLE role_menu DROP CONSTRAINT role_menu_role_id_menu_id_key;
+CREATE UNIQUE INDEX role_menu_role_id_menu_id_key ON role_menu (role_id, menu_id) WHERE deleted_at IS NULL;
+
+-- used by the purge job
+CREATE INDEX app_user_deleted_at_idx ON app_user (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX user_role_deleted_at_idx ON user_role (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX role_deleted_at_idx ON role (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX role_menu_deleted_at_idx ON role_menu (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX menu_deleted_at_idx ON menu (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX resource_deleted_at_idx ON resource (deleted_at) WHERE deleted_at IS NOT NULL;
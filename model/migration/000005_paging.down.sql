@@ -0,0 +1 @@
+DROP INDEX IF EXISTS app_user_created_id_idx, role_sequence_created_id_idx, menu_sequence_created_id_idx;
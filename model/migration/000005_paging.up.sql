@@ -0,0 +1,4 @@
+-- match the keyset order of the list queries
+CREATE INDEX app_user_created_id_idx ON app_user (created DESC, id DESC);
+CREATE INDEX role_sequence_created_id_idx ON role (sequence, created DESC, id DESC);
+CREATE INDEX menu_sequence_created_id_idx ON menu (sequence, created DESC, id DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE app_user DROP COLUMN IF EXISTS version;
+ALTER TABLE role DROP COLUMN IF EXISTS version;
+ALTER TABLE menu DROP COLUMN IF EXISTS version;
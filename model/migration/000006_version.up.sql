@@ -0,0 +1,4 @@
+-- bumped on every write, exposed as the ETag
+ALTER TABLE app_user ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE role ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE menu ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS password_history;
+ALTER TABLE app_user DROP COLUMN IF EXISTS must_change_password;
@@ -0,0 +1,12 @@
+-- set by an admin reset, the user has to change the password before anything else
+ALTER TABLE app_user ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT false;
+
+-- the previous password hashes of a user, the current one stays in app_user
+CREATE TABLE IF NOT EXISTS password_history (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+  password VARCHAR NOT NULL,
+  created TIMESTAMP NOT NULL
+);
+
+CREATE INDEX password_history_user_id_idx ON password_history (user_id);
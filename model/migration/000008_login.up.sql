@@ -0,0 +1,26 @@
+-- every login attempt, user_id is null when the username does not exist
+CREATE TABLE IF NOT EXISTS login_log (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER,
+  username VARCHAR NOT NULL,
+  ip VARCHAR NOT NULL,
+  user_agent VARCHAR NOT NULL,
+  result VARCHAR NOT NULL,
+  reason VARCHAR NOT NULL,
+  created TIMESTAMP NOT NULL
+);
+
+CREATE INDEX login_log_user_id_idx ON login_log (user_id);
+CREATE INDEX login_log_ip_idx ON login_log (ip);
+CREATE INDEX login_log_created_idx ON login_log (created);
+
+-- the failed logins in a row of a user or an ip, scope is user or ip
+CREATE TABLE IF NOT EXISTS login_attempt (
+  id SERIAL PRIMARY KEY,
+  scope VARCHAR NOT NULL,
+  key VARCHAR NOT NULL,
+  failed INTEGER NOT NULL,
+  locked_until TIMESTAMP,
+  updated TIMESTAMP NOT NULL,
+  CONSTRAINT login_attempt_scope_key_key UNIQUE (scope, key)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS login_challenge;
+DROP TABLE IF EXISTS recovery_code;
+ALTER TABLE role DROP COLUMN IF EXISTS require_2fa;
+ALTER TABLE app_user DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE app_user DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,27 @@
+-- the totp secret is kept from enrollment on, it is enabled once verified
+ALTER TABLE app_user ADD COLUMN totp_secret VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE app_user ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT false;
+
+-- members of the role have to enable 2fa before anything else
+ALTER TABLE role ADD COLUMN require_2fa BOOLEAN NOT NULL DEFAULT false;
+
+-- one time codes for a lost authenticator, only their hashes are stored
+CREATE TABLE IF NOT EXISTS recovery_code (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+  code VARCHAR NOT NULL,
+  used_at TIMESTAMP,
+  created TIMESTAMP NOT NULL
+);
+
+CREATE INDEX recovery_code_user_id_idx ON recovery_code (user_id);
+
+-- handed out by a login whose password is right, traded with a code for the tokens
+CREATE TABLE IF NOT EXISTS login_challenge (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+  token VARCHAR NOT NULL,
+  expired TIMESTAMP NOT NULL,
+  created TIMESTAMP NOT NULL,
+  CONSTRAINT login_challenge_token_key UNIQUE (token)
+);
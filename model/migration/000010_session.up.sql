@@ -0,0 +1,21 @@
+-- a login on a device, it lives as long as its refresh tokens are rotated
+-- and ends on logout or when revoked
+CREATE TABLE IF NOT EXISTS session (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES app_user (id) ON DELETE CASCADE,
+  user_agent VARCHAR NOT NULL,
+  ip VARCHAR NOT NULL,
+  created TIMESTAMP NOT NULL,
+  last_seen TIMESTAMP NOT NULL,
+  expired TIMESTAMP NOT NULL
+);
+
+CREATE INDEX session_user_id_idx ON session (user_id);
+
+-- refresh tokens issued before sessions belong to none, their users log in again
+DELETE FROM refresh_token;
+
+ALTER TABLE refresh_token
+  ADD COLUMN session_id INTEGER NOT NULL REFERENCES session (id) ON DELETE CASCADE;
+
+CREATE INDEX refresh_token_session_id_idx ON refresh_token (session_id);
@@ -0,0 +1,12 @@
+-- the routes of the spec, synced on start so that menus can pick resources from them
+CREATE TABLE IF NOT EXISTS api_resource (
+  id SERIAL PRIMARY KEY,
+  method VARCHAR NOT NULL,
+  path VARCHAR NOT NULL,
+  operation_id VARCHAR NOT NULL,
+  tag VARCHAR NOT NULL,
+  description VARCHAR NOT NULL,
+  created TIMESTAMP NOT NULL,
+  updated TIMESTAMP NOT NULL,
+  CONSTRAINT api_resource_method_path_key UNIQUE (method, path)
+);
@@ -0,0 +1,12 @@
+-- a role inherits the menus of its parents and, through them, of all its ancestors
+CREATE TABLE IF NOT EXISTS role_parent (
+  id SERIAL PRIMARY KEY,
+  role_id INTEGER NOT NULL REFERENCES role (id) ON DELETE CASCADE,
+  parent_id INTEGER NOT NULL REFERENCES role (id) ON DELETE CASCADE,
+  created TIMESTAMP NOT NULL,
+  updated TIMESTAMP NOT NULL,
+  CONSTRAINT role_parent_role_id_parent_id_key UNIQUE (role_id, parent_id),
+  CONSTRAINT role_parent_check CHECK (role_id <> parent_id)
+);
+
+CREATE INDEX role_parent_parent_id_idx ON role_parent (parent_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS app_user_is_root_key;
+ALTER TABLE app_user DROP COLUMN IF EXISTS is_root;
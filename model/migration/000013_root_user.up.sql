@@ -0,0 +1,5 @@
+-- root passes every permission check, it is told by this flag and not by its
+-- username, which another user could take once root is renamed or deleted
+ALTER TABLE app_user ADD COLUMN is_root BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX app_user_is_root_key ON app_user (is_root) WHERE is_root;
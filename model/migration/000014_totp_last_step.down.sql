@@ -0,0 +1 @@
+ALTER TABLE app_user DROP COLUMN IF EXISTS totp_last_step;
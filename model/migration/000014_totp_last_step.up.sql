@@ -0,0 +1,3 @@
+-- a totp code is accepted once, a code of this period or an earlier one is
+-- refused afterwards so that a code seen over a shoulder cannot be replayed
+ALTER TABLE app_user ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
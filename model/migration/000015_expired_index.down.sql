@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS refresh_token_expired_idx;
+DROP INDEX IF EXISTS session_expired_idx;
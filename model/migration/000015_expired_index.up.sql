@@ -0,0 +1,3 @@
+-- expired sessions and refresh tokens are purged periodically
+CREATE INDEX IF NOT EXISTS session_expired_idx ON session (expired);
+CREATE INDEX IF NOT EXISTS refresh_token_expired_idx ON refresh_token (expired);
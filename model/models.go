@@ -8,17 +8,76 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiResource struct {
+	ID          int32
+	Method      string
+	Path        string
+	OperationID string
+	Tag         string
+	Description string
+	Created     pgtype.Timestamp
+	Updated     pgtype.Timestamp
+}
+
 type AppUser struct {
-	ID       int32
-	Username string
-	Password string
-	Name     string
-	Email    string
-	Phone    string
-	Remark   string
-	Status   string
-	Created  pgtype.Timestamp
-	Updated  pgtype.Timestamp
+	ID                 int32
+	Username           string
+	Password           string
+	Name               string
+	Email              string
+	Phone              string
+	Remark             string
+	Status             string
+	Created            pgtype.Timestamp
+	Updated            pgtype.Timestamp
+	DeletedAt          pgtype.Timestamp
+	Version            int32
+	MustChangePassword bool
+	TotpSecret         string
+	TotpEnabled        bool
+	IsRoot             bool
+	TotpLastStep       int64
+}
+
+type AuditLog struct {
+	ID        int32
+	ActorID   pgtype.Int4
+	Action    string
+	Entity    string
+	EntityID  int32
+	Before    []byte
+	After     []byte
+	Ip        string
+	RequestID string
+	Created   pgtype.Timestamp
+}
+
+type LoginAttempt struct {
+	ID          int32
+	Scope       string
+	Key         string
+	Failed      int32
+	LockedUntil pgtype.Timestamp
+	Updated     pgtype.Timestamp
+}
+
+type LoginChallenge struct {
+	ID      int32
+	UserID  int32
+	Token   string
+	Expired pgtype.Timestamp
+	Created pgtype.Timestamp
+}
+
+type LoginLog struct {
+	ID        int32
+	UserID    pgtype.Int4
+	Username  string
+	Ip        string
+	UserAgent string
+	Result    string
+	Reason    string
+	Created   pgtype.Timestamp
 }
 
 type Menu struct {
@@ -30,20 +89,49 @@ type Menu struct {
 	Type        string
 	Path        string
 	Property    string
-	ParentID    int32
+	ParentID    pgtype.Int4
 	ParentPath  string
 	Status      string
 	Created     pgtype.Timestamp
 	Updated     pgtype.Timestamp
+	DeletedAt   pgtype.Timestamp
+	Version     int32
 }
 
-type Resource struct {
+type PasswordHistory struct {
+	ID       int32
+	UserID   int32
+	Password string
+	Created  pgtype.Timestamp
+}
+
+type RecoveryCode struct {
 	ID      int32
-	MenuID  int32
-	Method  string
-	Path    string
+	UserID  int32
+	Code    string
+	UsedAt  pgtype.Timestamp
 	Created pgtype.Timestamp
-	Updated pgtype.Timestamp
+}
+
+type RefreshToken struct {
+	ID        int32
+	UserID    int32
+	Token     string
+	Expired   pgtype.Timestamp
+	Revoked   bool
+	Created   pgtype.Timestamp
+	Updated   pgtype.Timestamp
+	SessionID int32
+}
+
+type Resource struct {
+	ID        int32
+	MenuID    int32
+	Method    string
+	Path      string
+	Created   pgtype.Timestamp
+	Updated   pgtype.Timestamp
+	DeletedAt pgtype.Timestamp
 }
 
 type Role struct {
@@ -55,20 +143,43 @@ type Role struct {
 	Status      string
 	Created     pgtype.Timestamp
 	Updated     pgtype.Timestamp
+	DeletedAt   pgtype.Timestamp
+	Version     int32
+	Require2fa  bool
 }
 
 type RoleMenu struct {
-	ID      int32
-	RoleID  int32
-	MenuID  int32
-	Created pgtype.Timestamp
-	Updated pgtype.Timestamp
+	ID        int32
+	RoleID    int32
+	MenuID    int32
+	Created   pgtype.Timestamp
+	Updated   pgtype.Timestamp
+	DeletedAt pgtype.Timestamp
+}
+
+type RoleParent struct {
+	ID       int32
+	RoleID   int32
+	ParentID int32
+	Created  pgtype.Timestamp
+	Updated  pgtype.Timestamp
+}
+
+type Session struct {
+	ID        int32
+	UserID    int32
+	UserAgent string
+	Ip        string
+	Created   pgtype.Timestamp
+	LastSeen  pgtype.Timestamp
+	Expired   pgtype.Timestamp
 }
 
 type UserRole struct {
-	ID      int32
-	UserID  int32
-	RoleID  int32
-	Created pgtype.Timestamp
-	Updated pgtype.Timestamp
+	ID        int32
+	UserID    int32
+	RoleID    int32
+	Created   pgtype.Timestamp
+	Updated   pgtype.Timestamp
+	DeletedAt pgtype.Timestamp
 }
@@ -2,41 +2,150 @@
 -- name: GetUser :one
 SELECT *
 FROM app_user
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetUserVersion :one
+SELECT version
+FROM app_user
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
--- name: ListUser :many
+-- name: LockUserVersion :one
+SELECT version
+FROM app_user
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
+
+-- name: GetDeletedUser :one
 SELECT *
 FROM app_user
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE '%' || $1 || '%')
-AND ($2::VARCHAR = '' OR $2::VARCHAR ILIKE '%' || $2 || '%')
-AND ($3::VARCHAR = '' OR $3::VARCHAR = $3)
-AND id > $4
-ORDER BY created DESC
-LIMIT $5;
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
 
 -- name: CheckUserByID :one
-SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1);
+SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1 AND deleted_at IS NULL);
+
+-- name: GetUserByUsername :one
+SELECT *
+FROM app_user
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetRootUser :one
+SELECT *
+FROM app_user
+WHERE is_root LIMIT 1;
+
+-- name: CheckRootUserByID :one
+SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1 AND is_root);
+
+-- name: MarkRootUser :exec
+UPDATE app_user
+SET is_root = true
+WHERE id = $1;
 
 -- name: CheckUserByUsername :one
-SELECT EXISTS (SELECT 1 FROM app_user WHERE username = $1);
+SELECT EXISTS (SELECT 1 FROM app_user WHERE username = $1 AND deleted_at IS NULL);
 
 -- name: CreateUser :one
 INSERT INTO app_user (username, password, name, email, phone, remark, status,
-created, updated)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+must_change_password, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: CreateUserBatch :batchone
+INSERT INTO app_user (username, password, name, email, phone, remark, status,
+must_change_password, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: UpdateUser :one
 UPDATE app_user
-SET username = $2, password = $3, name = $4, email = $5, phone = $6,
-remark = $7, status = $8, created = $9, updated = $10
-WHERE id = $1
+SET username = sqlc.arg('username'),
+password = COALESCE(sqlc.narg('password'), password),
+name = sqlc.arg('name'), email = sqlc.arg('email'), phone = sqlc.arg('phone'),
+remark = sqlc.arg('remark'), status = sqlc.arg('status'),
+created = sqlc.arg('created'), updated = sqlc.arg('updated'),
+version = version + 1
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetUserPassword :one
+UPDATE app_user
+SET password = $2, must_change_password = $3, updated = $4, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RehashUserPassword :exec
+UPDATE app_user
+SET password = $2
+WHERE id = $1;
+
+-- name: SetUserTOTP :one
+UPDATE app_user
+SET totp_secret = $2, totp_enabled = $3, updated = $4, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UseUserTOTPStep :execrows
+UPDATE app_user
+SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2;
+
+-- name: CheckUserRequire2FA :one
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT EXISTS (
+  SELECT 1
+  FROM role
+  WHERE role.id IN (SELECT id FROM granting) AND role.require_2fa
+);
+
+-- name: PatchUser :one
+UPDATE app_user
+SET username = COALESCE(sqlc.narg('username'), username),
+password = COALESCE(sqlc.narg('password'), password),
+name = COALESCE(sqlc.narg('name'), name),
+email = COALESCE(sqlc.narg('email'), email),
+phone = COALESCE(sqlc.narg('phone'), phone),
+remark = COALESCE(sqlc.narg('remark'), remark),
+status = COALESCE(sqlc.narg('status'), status),
+created = COALESCE(sqlc.narg('created'), created),
+updated = sqlc.arg('updated'),
+version = version + 1
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM app_user
 WHERE id = $1;
 
+-- name: SoftDeleteUser :exec
+UPDATE app_user
+SET deleted_at = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreUser :one
+UPDATE app_user
+SET deleted_at = NULL, updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeUser :execrows
+DELETE FROM app_user
+WHERE deleted_at < $1;
+
 
 --------------------------------- UserRole --------------------------------
 -- name: GetUserRole :one
@@ -47,7 +156,15 @@ WHERE id = $1 LIMIT 1;
 -- name: ListUserRoleByUserIDList :many
 SELECT *
 FROM user_role
-WHERE user_id = ANY($1::int[]);
+WHERE user_id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: ListRoleCodeByUserIDList :many
+SELECT user_role.user_id, role.code
+FROM user_role
+JOIN role ON role.id = user_role.role_id
+WHERE user_role.user_id = ANY($1::int[])
+AND user_role.deleted_at IS NULL AND role.deleted_at IS NULL
+ORDER BY role.sequence, role.id;
 
 -- name: CheckUserRoleByID :one
 SELECT EXISTS (SELECT 1 FROM user_role WHERE id = $1);
@@ -57,6 +174,10 @@ INSERT INTO user_role (user_id, role_id, created, updated)
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
+-- name: CopyUserRole :copyfrom
+INSERT INTO user_role (user_id, role_id, created, updated)
+VALUES ($1, $2, $3, $4);
+
 -- name: UpdateUserRole :one
 UPDATE user_role
 SET user_id = $2, role_id = $3, created = $4, updated = $5
@@ -69,46 +190,134 @@ WHERE id = $1;
 
 -- name: DeleteUserRoleByUserID :exec
 DELETE FROM user_role
-WHERE user_id = $1;
+WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: SoftDeleteUserRoleByUserID :exec
+UPDATE user_role
+SET deleted_at = $2
+WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: SoftDeleteUserRoleByRoleID :exec
+UPDATE user_role
+SET deleted_at = $2
+WHERE role_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreUserRoleByUserID :exec
+UPDATE user_role
+SET deleted_at = NULL
+WHERE user_role.user_id = $1 AND user_role.deleted_at = $2
+AND user_role.role_id IN (SELECT id FROM role WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM user_role AS live
+  WHERE live.user_id = user_role.user_id AND live.role_id = user_role.role_id
+  AND live.deleted_at IS NULL
+);
+
+-- name: RestoreUserRoleByRoleID :exec
+UPDATE user_role
+SET deleted_at = NULL
+WHERE user_role.role_id = $1 AND user_role.deleted_at = $2
+AND user_role.user_id IN (SELECT id FROM app_user WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM user_role AS live
+  WHERE live.user_id = user_role.user_id AND live.role_id = user_role.role_id
+  AND live.deleted_at IS NULL
+);
+
+-- name: PurgeUserRole :execrows
+DELETE FROM user_role
+WHERE deleted_at < $1;
 
 
 --------------------------------- Role --------------------------------
 -- name: GetRole :one
 SELECT *
 FROM role
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
 
--- name: ListRole :many
+-- name: GetRoleVersion :one
+SELECT version
+FROM role
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: LockRoleVersion :one
+SELECT version
+FROM role
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
+
+-- name: GetDeletedRole :one
 SELECT *
 FROM role
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE '%' || $1 || '%')
-AND ($2::VARCHAR = '' OR $2::VARCHAR = $2)
-AND id > $3
-ORDER BY sequence, created DESC
-LIMIT $4;
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
 
 -- name: CheckRoleByID :one
-SELECT EXISTS (SELECT 1 FROM role WHERE id = $1);
+SELECT EXISTS (SELECT 1 FROM role WHERE id = $1 AND deleted_at IS NULL);
 
 -- name: CheckRoleByCode :one
-SELECT EXISTS (SELECT 1 FROM role WHERE code = $1);
+SELECT EXISTS (SELECT 1 FROM role WHERE code = $1 AND deleted_at IS NULL);
+
+-- name: ListRoleByCodeList :many
+SELECT *
+FROM role
+WHERE code = ANY($1::varchar[]) AND deleted_at IS NULL;
 
 -- name: CreateRole :one
-INSERT INTO role (code, name, description, sequence, status, created, updated)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO role (code, name, description, sequence, status, require_2fa, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: CreateRoleBatch :batchone
+INSERT INTO role (code, name, description, sequence, status, require_2fa, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: UpdateRole :one
 UPDATE role
 SET code = $2, name = $3, description = $4, sequence = $5, status = $6,
-created = $7, updated = $8
-WHERE id = $1
+require_2fa = $7, created = $8, updated = $9, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PatchRole :one
+UPDATE role
+SET code = COALESCE(sqlc.narg('code'), code),
+name = COALESCE(sqlc.narg('name'), name),
+description = COALESCE(sqlc.narg('description'), description),
+sequence = COALESCE(sqlc.narg('sequence'), sequence),
+status = COALESCE(sqlc.narg('status'), status),
+require_2fa = COALESCE(sqlc.narg('require_2fa'), require_2fa),
+created = COALESCE(sqlc.narg('created'), created),
+updated = sqlc.arg('updated'),
+version = version + 1
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
 RETURNING *;
 
+-- name: BumpRoleVersion :one
+UPDATE role
+SET updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING version;
+
 -- name: DeleteRole :exec
 DELETE FROM role
 WHERE id = $1;
 
+-- name: SoftDeleteRole :exec
+UPDATE role
+SET deleted_at = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreRole :one
+UPDATE role
+SET deleted_at = NULL, updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeRole :execrows
+DELETE FROM role
+WHERE deleted_at < $1;
+
 
 --------------------------------- RoleMenu --------------------------------
 -- name: GetRoleMenu :one
@@ -119,7 +328,7 @@ WHERE id = $1 LIMIT 1;
 -- name: ListRoleMenuByRoleIDList :many
 SELECT *
 FROM role_menu
-WHERE role_id = ANY($1::int[]);
+WHERE role_id = ANY($1::int[]) AND deleted_at IS NULL;
 
 -- name: CheckRoleMenuByID :one
 SELECT EXISTS (SELECT 1 FROM role_menu WHERE id = $1);
@@ -129,6 +338,10 @@ INSERT INTO role_menu (role_id, menu_id, created, updated)
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
+-- name: CopyRoleMenu :copyfrom
+INSERT INTO role_menu (role_id, menu_id, created, updated)
+VALUES ($1, $2, $3, $4);
+
 -- name: UpdateRoleMenu :one
 UPDATE role_menu
 SET role_id = $2, menu_id = $3, created = $4, updated = $5
@@ -147,22 +360,204 @@ WHERE role_id = $1;
 DELETE FROM role_menu
 WHERE menu_id = ANY($1::int[]);
 
+-- name: SoftDeleteRoleMenuByRoleID :exec
+UPDATE role_menu
+SET deleted_at = $2
+WHERE role_id = $1 AND deleted_at IS NULL;
+
+-- name: SoftDeleteRoleMenuByMenuIDList :exec
+UPDATE role_menu
+SET deleted_at = $2
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: RestoreRoleMenuByRoleID :exec
+UPDATE role_menu
+SET deleted_at = NULL
+WHERE role_menu.role_id = $1 AND role_menu.deleted_at = $2
+AND role_menu.menu_id IN (SELECT id FROM menu WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM role_menu AS live
+  WHERE live.role_id = role_menu.role_id AND live.menu_id = role_menu.menu_id
+  AND live.deleted_at IS NULL
+);
+
+-- name: RestoreRoleMenuByMenuIDList :exec
+UPDATE role_menu
+SET deleted_at = NULL
+WHERE role_menu.menu_id = ANY($1::int[]) AND role_menu.deleted_at = $2
+AND role_menu.role_id IN (SELECT id FROM role WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM role_menu AS live
+  WHERE live.role_id = role_menu.role_id AND live.menu_id = role_menu.menu_id
+  AND live.deleted_at IS NULL
+);
+
+-- name: PurgeRoleMenu :execrows
+DELETE FROM role_menu
+WHERE deleted_at < $1;
+
+-- name: ListMenuByRoleIDList :many
+SELECT DISTINCT menu.*
+FROM menu
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id = ANY($1::int[])
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY menu.sequence, menu.id;
+
+--------------------------------- RoleParent --------------------------------
+-- name: ListRoleParentByRoleID :many
+SELECT *
+FROM role_parent
+WHERE role_id = $1
+ORDER BY parent_id;
+
+-- name: CreateRoleParent :one
+INSERT INTO role_parent (role_id, parent_id, created, updated)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteRoleParentByRoleID :exec
+DELETE FROM role_parent
+WHERE role_id = $1;
+
+-- name: LockRoleTree :exec
+SELECT pg_advisory_xact_lock(hashtext('role_tree'));
+
+-- name: CheckRoleAncestor :one
+WITH RECURSIVE ancestor AS (
+  SELECT unnest(sqlc.arg('parent_id_list')::int[]) AS id
+  UNION
+  SELECT role_parent.parent_id
+  FROM ancestor
+  JOIN role_parent ON role_parent.role_id = ancestor.id
+)
+SELECT EXISTS (SELECT 1 FROM ancestor WHERE id = sqlc.arg('role_id')::INTEGER);
+
+-- name: ListInheritedRoleID :many
+WITH RECURSIVE granting AS (
+  SELECT role_parent.parent_id AS id
+  FROM role_parent
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role_parent.role_id = $1
+  AND role.status = 'enabled'
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT id::INTEGER
+FROM granting
+ORDER BY id;
+
 --------------------------------- Menu --------------------------------
 -- name: GetMenu :one
 SELECT *
 FROM menu
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: ListMenuByIDList :many
+SELECT *
+FROM menu
+WHERE id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: GetMenuVersion :one
+SELECT version
+FROM menu
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: LockMenuVersion :one
+SELECT version
+FROM menu
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE;
+
+-- name: GetDeletedMenu :one
+SELECT *
+FROM menu
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1;
+
+-- name: ListEnabledMenu :many
+SELECT *
+FROM menu
+WHERE status = 'enabled'
+AND deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY sequence, id;
+
+-- name: ListMenuByUserID :many
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT DISTINCT menu.*
+FROM menu
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id IN (SELECT id FROM granting)
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY menu.sequence, menu.id;
+
+-- name: ListMenuByParentPath :many
+SELECT *
+FROM menu
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at IS NULL
+ORDER BY sequence, id;
+
+-- name: ListMenuByParentID :many
+SELECT *
+FROM menu
+WHERE parent_id IS NOT DISTINCT FROM $1 AND deleted_at IS NULL
+ORDER BY sequence, id
+FOR UPDATE;
 
 -- name: ListChildID :many
 SELECT id
 FROM menu
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE $1 || '%');
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at IS NULL;
+
+-- name: ListDeletedChildID :many
+SELECT id
+FROM menu
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at = $2;
 
 -- name: CheckMenuByID :one
-SELECT EXISTS (SELECT 1 FROM menu WHERE id = $1);
+SELECT EXISTS (SELECT 1 FROM menu WHERE id = $1 AND deleted_at IS NULL);
 
 -- name: CheckMenuByCodeAndParentID :one
-SELECT EXISTS (SELECT 1 FROM menu WHERE code = $1 AND parent_id = $2);
+SELECT EXISTS (SELECT 1 FROM menu WHERE code = $1 AND parent_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL);
 
 -- name: CreateMenu :one
 INSERT INTO menu (code, name, description, sequence, type, path, property,
@@ -170,14 +565,61 @@ parent_id, parent_path, status, created, updated)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
+-- name: CreateMenuBatch :batchone
+INSERT INTO menu (code, name, description, sequence, type, path, property,
+parent_id, parent_path, status, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
 -- name: UpdateMenu :one
 UPDATE menu
 SET code = $2, name = $3, description = $4, sequence = $5, type = $6,
 path = $7, property = $8, parent_id = $9, parent_path = $10, status = $11,
-created = $12, updated = $13
-WHERE id = $1
+created = $12, updated = $13, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 RETURNING *;
 
+-- name: PatchMenu :one
+UPDATE menu
+SET code = COALESCE(sqlc.narg('code'), code),
+name = COALESCE(sqlc.narg('name'), name),
+description = COALESCE(sqlc.narg('description'), description),
+sequence = COALESCE(sqlc.narg('sequence'), sequence),
+type = COALESCE(sqlc.narg('type'), type),
+path = COALESCE(sqlc.narg('path'), path),
+property = COALESCE(sqlc.narg('property'), property),
+status = COALESCE(sqlc.narg('status'), status),
+created = COALESCE(sqlc.narg('created'), created),
+updated = sqlc.arg('updated'),
+version = version + 1
+WHERE id = sqlc.arg('id') AND deleted_at IS NULL
+RETURNING *;
+
+-- name: MoveMenu :many
+UPDATE menu
+SET parent_id = CASE WHEN id = sqlc.arg('id')::INTEGER THEN sqlc.narg('parent_id')::INTEGER ELSE parent_id END,
+parent_path = sqlc.arg('parent_path')::VARCHAR || substr(parent_path, length(sqlc.arg('old_parent_path')::VARCHAR) + 1),
+updated = sqlc.arg('updated'),
+version = version + 1
+WHERE id = sqlc.arg('id')::INTEGER
+OR parent_path LIKE sqlc.arg('old_parent_path')::VARCHAR || sqlc.arg('id')::INTEGER || '.%'
+RETURNING *;
+
+-- name: ReorderMenu :many
+UPDATE menu
+SET sequence = ordered.position::SMALLINT, updated = sqlc.arg('updated'), version = version + 1
+FROM unnest(sqlc.arg('id_list')::int[]) WITH ORDINALITY AS ordered(id, position)
+WHERE menu.id = ordered.id
+AND menu.parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')::INTEGER
+AND menu.deleted_at IS NULL
+RETURNING menu.*;
+
+-- name: LockMenuTree :exec
+SELECT pg_advisory_xact_lock(hashtext('menu_tree'));
+
+-- name: ShareMenuTree :exec
+SELECT pg_advisory_xact_lock_shared(hashtext('menu_tree'));
+
 -- name: DeleteMenu :exec
 DELETE FROM menu
 WHERE id = $1;
@@ -186,6 +628,20 @@ WHERE id = $1;
 DELETE FROM menu
 WHERE id = ANY($1::int[]);
 
+-- name: SoftDeleteMenuByIDList :exec
+UPDATE menu
+SET deleted_at = $2, version = version + 1
+WHERE id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: RestoreMenuByIDList :exec
+UPDATE menu
+SET deleted_at = NULL, updated = $3, version = version + 1
+WHERE id = ANY($1::int[]) AND deleted_at = $2;
+
+-- name: PurgeMenu :execrows
+DELETE FROM menu
+WHERE deleted_at < $1;
+
 --------------------------------- Resource --------------------------------
 -- name: GetResource :one
 SELECT *
@@ -195,7 +651,47 @@ WHERE id = $1 LIMIT 1;
 -- name: ListResourceByMenuIDList :many
 SELECT *
 FROM resource
-WHERE menu_id = ANY($1::int[]);
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: ListResourceByUserID :many
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT DISTINCT resource.*
+FROM resource
+JOIN menu ON menu.id = resource.menu_id
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id IN (SELECT id FROM granting)
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND resource.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+);
+
+-- name: ListResource :many
+SELECT resource.*
+FROM resource
+JOIN menu ON menu.id = resource.menu_id
+WHERE resource.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+ORDER BY resource.id;
 
 -- name: CheckResourceByID :one
 SELECT EXISTS (SELECT 1 FROM resource WHERE id = $1);
@@ -205,6 +701,10 @@ INSERT INTO resource (menu_id, method, path, created, updated)
 VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
+-- name: CopyResource :copyfrom
+INSERT INTO resource (menu_id, method, path, created, updated)
+VALUES ($1, $2, $3, $4, $5);
+
 -- name: UpdateResource :one
 UPDATE resource
 SET menu_id = $2, method = $3, path = $4, created = $5, updated = $6
@@ -219,6 +719,210 @@ WHERE id = $1;
 DELETE FROM resource
 WHERE menu_id = $1;
 
--- name: DeleteMenuByMenuIdList :exec
+-- name: DeleteResourceByMenuIDList :exec
 DELETE FROM resource
-WHERE menu_id = ANY($1::int[]);
\ No newline at end of file
+WHERE menu_id = ANY($1::int[]);
+
+-- name: SoftDeleteResourceByMenuIDList :exec
+UPDATE resource
+SET deleted_at = $2
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL;
+
+-- name: RestoreResourceByMenuIDList :exec
+UPDATE resource
+SET deleted_at = NULL
+WHERE menu_id = ANY($1::int[]) AND deleted_at = $2;
+
+-- name: PurgeResource :execrows
+DELETE FROM resource
+WHERE deleted_at < $1;
+
+------------------------------- ApiResource -------------------------------
+-- name: ListApiResource :many
+SELECT *
+FROM api_resource
+WHERE (sqlc.arg(tag)::VARCHAR = '' OR tag = sqlc.arg(tag))
+ORDER BY path, method;
+
+-- name: UpsertApiResource :one
+INSERT INTO api_resource (method, path, operation_id, tag, description, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+ON CONFLICT (method, path) DO UPDATE
+SET operation_id = EXCLUDED.operation_id,
+tag = EXCLUDED.tag,
+description = EXCLUDED.description,
+updated = EXCLUDED.updated
+RETURNING *;
+
+-- name: DeleteApiResourceBefore :execrows
+DELETE FROM api_resource
+WHERE updated < $1;
+
+--------------------------------- RefreshToken --------------------------------
+-- name: GetRefreshTokenByToken :one
+SELECT *
+FROM refresh_token
+WHERE token = $1 LIMIT 1;
+
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_token (user_id, session_id, token, expired, revoked, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE refresh_token
+SET revoked = TRUE, updated = $2
+WHERE id = $1 AND revoked = FALSE;
+
+-- name: PurgeRefreshToken :execrows
+DELETE FROM refresh_token
+WHERE expired < $1;
+
+--------------------------------- Session --------------------------------
+-- name: CreateSession :one
+INSERT INTO session (user_id, user_agent, ip, created, last_seen, expired)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT *
+FROM session
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSession :many
+SELECT *
+FROM session
+WHERE (sqlc.narg(user_id)::INTEGER IS NULL OR user_id = sqlc.narg(user_id))
+AND expired > sqlc.arg(now)
+ORDER BY last_seen DESC, id DESC
+OFFSET sqlc.arg(offset_) LIMIT sqlc.arg(limit_);
+
+-- name: RenewSession :exec
+UPDATE session
+SET last_seen = $2, expired = $3
+WHERE id = $1;
+
+-- name: TouchSession :exec
+UPDATE session
+SET last_seen = $2
+WHERE id = $1;
+
+-- name: DeleteSession :execrows
+DELETE FROM session
+WHERE id = $1;
+
+-- name: DeleteSessionByUserID :exec
+DELETE FROM session
+WHERE user_id = $1;
+
+-- name: PurgeSession :execrows
+DELETE FROM session
+WHERE expired < $1;
+
+------------------------------- PasswordHistory -------------------------------
+-- name: ListPasswordHistoryByUserID :many
+SELECT password
+FROM password_history
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (user_id, password, created)
+VALUES ($1, $2, $3);
+
+-- name: TrimPasswordHistory :exec
+DELETE FROM password_history
+WHERE password_history.user_id = $1 AND id NOT IN (
+  SELECT kept.id FROM password_history AS kept
+  WHERE kept.user_id = $1 ORDER BY kept.id DESC LIMIT $2
+);
+
+------------------------------- RecoveryCode -------------------------------
+-- name: CopyRecoveryCode :copyfrom
+INSERT INTO recovery_code (user_id, code, created)
+VALUES ($1, $2, $3);
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_code
+SET used_at = $3
+WHERE user_id = $1 AND code = $2 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodeByUserID :exec
+DELETE FROM recovery_code
+WHERE user_id = $1;
+
+------------------------------ LoginChallenge ------------------------------
+-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenge (user_id, token, expired, created)
+VALUES ($1, $2, $3, $4);
+
+-- name: UseLoginChallenge :one
+DELETE FROM login_challenge
+WHERE token = $1
+RETURNING *;
+
+-- name: DeleteLoginChallengeByUserID :exec
+DELETE FROM login_challenge
+WHERE user_id = $1;
+
+--------------------------------- LoginLog --------------------------------
+-- name: CreateLoginLog :exec
+INSERT INTO login_log (user_id, username, ip, user_agent, result, reason, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListLoginLog :many
+SELECT *
+FROM login_log
+WHERE (sqlc.narg(user_id)::INTEGER IS NULL OR user_id = sqlc.narg(user_id))
+AND (sqlc.arg(ip)::VARCHAR = '' OR ip = sqlc.arg(ip))
+AND (sqlc.arg(result)::VARCHAR = '' OR result = sqlc.arg(result))
+AND (sqlc.narg(start)::TIMESTAMP IS NULL OR created >= sqlc.narg(start))
+AND (sqlc.narg(end_)::TIMESTAMP IS NULL OR created < sqlc.narg(end_))
+ORDER BY id DESC
+OFFSET sqlc.arg(offset_) LIMIT sqlc.arg(limit_);
+
+------------------------------- LoginAttempt -------------------------------
+-- name: GetLoginAttempt :one
+SELECT *
+FROM login_attempt
+WHERE scope = $1 AND key = $2 LIMIT 1;
+
+-- name: FailLoginAttempt :one
+INSERT INTO login_attempt (scope, key, failed, updated)
+VALUES (sqlc.arg(scope), sqlc.arg(key), 1, sqlc.arg(updated))
+ON CONFLICT (scope, key) DO UPDATE
+SET failed = CASE WHEN login_attempt.updated < sqlc.arg(window_start) THEN 1
+ELSE login_attempt.failed + 1 END,
+updated = sqlc.arg(updated)
+RETURNING *;
+
+-- name: LockLoginAttempt :exec
+UPDATE login_attempt
+SET locked_until = $2
+WHERE id = $1;
+
+-- name: DeleteLoginAttempt :execrows
+DELETE FROM login_attempt
+WHERE scope = $1 AND key = $2;
+
+--------------------------------- AuditLog --------------------------------
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (actor_id, action, entity, entity_id, before, after, ip, request_id, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: CopyAuditLog :copyfrom
+INSERT INTO audit_log (actor_id, action, entity, entity_id, before, after, ip, request_id, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListAuditLog :many
+SELECT *
+FROM audit_log
+WHERE (sqlc.narg(actor_id)::INTEGER IS NULL OR actor_id = sqlc.narg(actor_id))
+AND (sqlc.arg(entity)::VARCHAR = '' OR entity = sqlc.arg(entity))
+AND (sqlc.narg(entity_id)::INTEGER IS NULL OR entity_id = sqlc.narg(entity_id))
+AND (sqlc.narg(start)::TIMESTAMP IS NULL OR created >= sqlc.narg(start))
+AND (sqlc.narg(end_)::TIMESTAMP IS NULL OR created < sqlc.narg(end_))
+ORDER BY id DESC
+OFFSET sqlc.arg(offset_) LIMIT sqlc.arg(limit_);
\ No newline at end of file
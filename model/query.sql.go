@@ -11,13 +11,32 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const bumpRoleVersion = `-- name: BumpRoleVersion :one
+UPDATE role
+SET updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING version
+`
+
+type BumpRoleVersionParams struct {
+	ID      int32
+	Updated pgtype.Timestamp
+}
+
+func (q *Queries) BumpRoleVersion(ctx context.Context, arg BumpRoleVersionParams) (int32, error) {
+	row := q.db.QueryRow(ctx, bumpRoleVersion, arg.ID, arg.Updated)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
 const checkMenuByCodeAndParentID = `-- name: CheckMenuByCodeAndParentID :one
-SELECT EXISTS (SELECT 1 FROM menu WHERE code = $1 AND parent_id = $2)
+SELECT EXISTS (SELECT 1 FROM menu WHERE code = $1 AND parent_id IS NOT DISTINCT FROM $2 AND deleted_at IS NULL)
 `
 
 type CheckMenuByCodeAndParentIDParams struct {
 	Code     string
-	ParentID int32
+	ParentID pgtype.Int4
 }
 
 func (q *Queries) CheckMenuByCodeAndParentID(ctx context.Context, arg CheckMenuByCodeAndParentIDParams) (bool, error) {
@@ -28,7 +47,7 @@ func (q *Queries) CheckMenuByCodeAndParentID(ctx context.Context, arg CheckMenuB
 }
 
 const checkMenuByID = `-- name: CheckMenuByID :one
-SELECT EXISTS (SELECT 1 FROM menu WHERE id = $1)
+SELECT EXISTS (SELECT 1 FROM menu WHERE id = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) CheckMenuByID(ctx context.Context, id int32) (bool, error) {
@@ -49,8 +68,31 @@ func (q *Queries) CheckResourceByID(ctx context.Context, id int32) (bool, error)
 	return exists, err
 }
 
+const checkRoleAncestor = `-- name: CheckRoleAncestor :one
+WITH RECURSIVE ancestor AS (
+  SELECT unnest($2::int[]) AS id
+  UNION
+  SELECT role_parent.parent_id
+  FROM ancestor
+  JOIN role_parent ON role_parent.role_id = ancestor.id
+)
+SELECT EXISTS (SELECT 1 FROM ancestor WHERE id = $1::INTEGER)
+`
+
+type CheckRoleAncestorParams struct {
+	RoleID       int32
+	ParentIDList []int32
+}
+
+func (q *Queries) CheckRoleAncestor(ctx context.Context, arg CheckRoleAncestorParams) (bool, error) {
+	row := q.db.QueryRow(ctx, checkRoleAncestor, arg.RoleID, arg.ParentIDList)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const checkRoleByCode = `-- name: CheckRoleByCode :one
-SELECT EXISTS (SELECT 1 FROM role WHERE code = $1)
+SELECT EXISTS (SELECT 1 FROM role WHERE code = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) CheckRoleByCode(ctx context.Context, code string) (bool, error) {
@@ -61,7 +103,7 @@ func (q *Queries) CheckRoleByCode(ctx context.Context, code string) (bool, error
 }
 
 const checkRoleByID = `-- name: CheckRoleByID :one
-SELECT EXISTS (SELECT 1 FROM role WHERE id = $1)
+SELECT EXISTS (SELECT 1 FROM role WHERE id = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) CheckRoleByID(ctx context.Context, id int32) (bool, error) {
@@ -82,8 +124,19 @@ func (q *Queries) CheckRoleMenuByID(ctx context.Context, id int32) (bool, error)
 	return exists, err
 }
 
+const checkRootUserByID = `-- name: CheckRootUserByID :one
+SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1 AND is_root)
+`
+
+func (q *Queries) CheckRootUserByID(ctx context.Context, id int32) (bool, error) {
+	row := q.db.QueryRow(ctx, checkRootUserByID, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const checkUserByID = `-- name: CheckUserByID :one
-SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1)
+SELECT EXISTS (SELECT 1 FROM app_user WHERE id = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) CheckUserByID(ctx context.Context, id int32) (bool, error) {
@@ -94,7 +147,7 @@ func (q *Queries) CheckUserByID(ctx context.Context, id int32) (bool, error) {
 }
 
 const checkUserByUsername = `-- name: CheckUserByUsername :one
-SELECT EXISTS (SELECT 1 FROM app_user WHERE username = $1)
+SELECT EXISTS (SELECT 1 FROM app_user WHERE username = $1 AND deleted_at IS NULL)
 `
 
 func (q *Queries) CheckUserByUsername(ctx context.Context, username string) (bool, error) {
@@ -104,6 +157,37 @@ func (q *Queries) CheckUserByUsername(ctx context.Context, username string) (boo
 	return exists, err
 }
 
+const checkUserRequire2FA = `-- name: CheckUserRequire2FA :one
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT EXISTS (
+  SELECT 1
+  FROM role
+  WHERE role.id IN (SELECT id FROM granting) AND role.require_2fa
+)
+`
+
+func (q *Queries) CheckUserRequire2FA(ctx context.Context, userID int32) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUserRequire2FA, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const checkUserRoleByID = `-- name: CheckUserRoleByID :one
 SELECT EXISTS (SELECT 1 FROM user_role WHERE id = $1)
 `
@@ -115,11 +199,150 @@ func (q *Queries) CheckUserRoleByID(ctx context.Context, id int32) (bool, error)
 	return exists, err
 }
 
+type CopyAuditLogParams struct {
+	ActorID   pgtype.Int4
+	Action    string
+	Entity    string
+	EntityID  int32
+	Before    []byte
+	After     []byte
+	Ip        string
+	RequestID string
+	Created   pgtype.Timestamp
+}
+
+type CopyRecoveryCodeParams struct {
+	UserID  int32
+	Code    string
+	Created pgtype.Timestamp
+}
+
+type CopyResourceParams struct {
+	MenuID  int32
+	Method  string
+	Path    string
+	Created pgtype.Timestamp
+	Updated pgtype.Timestamp
+}
+
+type CopyRoleMenuParams struct {
+	RoleID  int32
+	MenuID  int32
+	Created pgtype.Timestamp
+	Updated pgtype.Timestamp
+}
+
+type CopyUserRoleParams struct {
+	UserID  int32
+	RoleID  int32
+	Created pgtype.Timestamp
+	Updated pgtype.Timestamp
+}
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_log (actor_id, action, entity, entity_id, before, after, ip, request_id, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, actor_id, action, entity, entity_id, before, after, ip, request_id, created
+`
+
+type CreateAuditLogParams struct {
+	ActorID   pgtype.Int4
+	Action    string
+	Entity    string
+	EntityID  int32
+	Before    []byte
+	After     []byte
+	Ip        string
+	RequestID string
+	Created   pgtype.Timestamp
+}
+
+// ------------------------------- AuditLog --------------------------------
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRow(ctx, createAuditLog,
+		arg.ActorID,
+		arg.Action,
+		arg.Entity,
+		arg.EntityID,
+		arg.Before,
+		arg.After,
+		arg.Ip,
+		arg.RequestID,
+		arg.Created,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.ActorID,
+		&i.Action,
+		&i.Entity,
+		&i.EntityID,
+		&i.Before,
+		&i.After,
+		&i.Ip,
+		&i.RequestID,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createLoginChallenge = `-- name: CreateLoginChallenge :exec
+INSERT INTO login_challenge (user_id, token, expired, created)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateLoginChallengeParams struct {
+	UserID  int32
+	Token   string
+	Expired pgtype.Timestamp
+	Created pgtype.Timestamp
+}
+
+// ---------------------------- LoginChallenge ------------------------------
+func (q *Queries) CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) error {
+	_, err := q.db.Exec(ctx, createLoginChallenge,
+		arg.UserID,
+		arg.Token,
+		arg.Expired,
+		arg.Created,
+	)
+	return err
+}
+
+const createLoginLog = `-- name: CreateLoginLog :exec
+INSERT INTO login_log (user_id, username, ip, user_agent, result, reason, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateLoginLogParams struct {
+	UserID    pgtype.Int4
+	Username  string
+	Ip        string
+	UserAgent string
+	Result    string
+	Reason    string
+	Created   pgtype.Timestamp
+}
+
+// ------------------------------- LoginLog --------------------------------
+func (q *Queries) CreateLoginLog(ctx context.Context, arg CreateLoginLogParams) error {
+	_, err := q.db.Exec(ctx, createLoginLog,
+		arg.UserID,
+		arg.Username,
+		arg.Ip,
+		arg.UserAgent,
+		arg.Result,
+		arg.Reason,
+		arg.Created,
+	)
+	return err
+}
+
 const createMenu = `-- name: CreateMenu :one
 INSERT INTO menu (code, name, description, sequence, type, path, property,
 parent_id, parent_path, status, created, updated)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated
+RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
 `
 
 type CreateMenuParams struct {
@@ -130,7 +353,7 @@ type CreateMenuParams struct {
 	Type        string
 	Path        string
 	Property    string
-	ParentID    int32
+	ParentID    pgtype.Int4
 	ParentPath  string
 	Status      string
 	Created     pgtype.Timestamp
@@ -167,6 +390,64 @@ func (q *Queries) CreateMenu(ctx context.Context, arg CreateMenuParams) (Menu, e
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const createPasswordHistory = `-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (user_id, password, created)
+VALUES ($1, $2, $3)
+`
+
+type CreatePasswordHistoryParams struct {
+	UserID   int32
+	Password string
+	Created  pgtype.Timestamp
+}
+
+func (q *Queries) CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) error {
+	_, err := q.db.Exec(ctx, createPasswordHistory, arg.UserID, arg.Password, arg.Created)
+	return err
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :one
+INSERT INTO refresh_token (user_id, session_id, token, expired, revoked, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, token, expired, revoked, created, updated, session_id
+`
+
+type CreateRefreshTokenParams struct {
+	UserID    int32
+	SessionID int32
+	Token     string
+	Expired   pgtype.Timestamp
+	Revoked   bool
+	Created   pgtype.Timestamp
+	Updated   pgtype.Timestamp
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, createRefreshToken,
+		arg.UserID,
+		arg.SessionID,
+		arg.Token,
+		arg.Expired,
+		arg.Revoked,
+		arg.Created,
+		arg.Updated,
+	)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Token,
+		&i.Expired,
+		&i.Revoked,
+		&i.Created,
+		&i.Updated,
+		&i.SessionID,
 	)
 	return i, err
 }
@@ -174,7 +455,7 @@ func (q *Queries) CreateMenu(ctx context.Context, arg CreateMenuParams) (Menu, e
 const createResource = `-- name: CreateResource :one
 INSERT INTO resource (menu_id, method, path, created, updated)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, menu_id, method, path, created, updated
+RETURNING id, menu_id, method, path, created, updated, deleted_at
 `
 
 type CreateResourceParams struct {
@@ -201,14 +482,15 @@ func (q *Queries) CreateResource(ctx context.Context, arg CreateResourceParams)
 		&i.Path,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const createRole = `-- name: CreateRole :one
-INSERT INTO role (code, name, description, sequence, status, created, updated)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, code, name, description, sequence, status, created, updated
+INSERT INTO role (code, name, description, sequence, status, require_2fa, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
 `
 
 type CreateRoleParams struct {
@@ -217,6 +499,7 @@ type CreateRoleParams struct {
 	Description string
 	Sequence    int16
 	Status      string
+	Require2fa  bool
 	Created     pgtype.Timestamp
 	Updated     pgtype.Timestamp
 }
@@ -228,6 +511,7 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (Role, e
 		arg.Description,
 		arg.Sequence,
 		arg.Status,
+		arg.Require2fa,
 		arg.Created,
 		arg.Updated,
 	)
@@ -241,6 +525,9 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (Role, e
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
 	)
 	return i, err
 }
@@ -248,7 +535,7 @@ func (q *Queries) CreateRole(ctx context.Context, arg CreateRoleParams) (Role, e
 const createRoleMenu = `-- name: CreateRoleMenu :one
 INSERT INTO role_menu (role_id, menu_id, created, updated)
 VALUES ($1, $2, $3, $4)
-RETURNING id, role_id, menu_id, created, updated
+RETURNING id, role_id, menu_id, created, updated, deleted_at
 `
 
 type CreateRoleMenuParams struct {
@@ -272,27 +559,98 @@ func (q *Queries) CreateRoleMenu(ctx context.Context, arg CreateRoleMenuParams)
 		&i.MenuID,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createRoleParent = `-- name: CreateRoleParent :one
+INSERT INTO role_parent (role_id, parent_id, created, updated)
+VALUES ($1, $2, $3, $4)
+RETURNING id, role_id, parent_id, created, updated
+`
+
+type CreateRoleParentParams struct {
+	RoleID   int32
+	ParentID int32
+	Created  pgtype.Timestamp
+	Updated  pgtype.Timestamp
+}
+
+func (q *Queries) CreateRoleParent(ctx context.Context, arg CreateRoleParentParams) (RoleParent, error) {
+	row := q.db.QueryRow(ctx, createRoleParent,
+		arg.RoleID,
+		arg.ParentID,
+		arg.Created,
+		arg.Updated,
+	)
+	var i RoleParent
+	err := row.Scan(
+		&i.ID,
+		&i.RoleID,
+		&i.ParentID,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO session (user_id, user_agent, ip, created, last_seen, expired)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, user_agent, ip, created, last_seen, expired
+`
+
+type CreateSessionParams struct {
+	UserID    int32
+	UserAgent string
+	Ip        string
+	Created   pgtype.Timestamp
+	LastSeen  pgtype.Timestamp
+	Expired   pgtype.Timestamp
+}
+
+// ------------------------------- Session --------------------------------
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRow(ctx, createSession,
+		arg.UserID,
+		arg.UserAgent,
+		arg.Ip,
+		arg.Created,
+		arg.LastSeen,
+		arg.Expired,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.Ip,
+		&i.Created,
+		&i.LastSeen,
+		&i.Expired,
 	)
 	return i, err
 }
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO app_user (username, password, name, email, phone, remark, status,
-created, updated)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-RETURNING id, username, password, name, email, phone, remark, status, created, updated
+must_change_password, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
 `
 
 type CreateUserParams struct {
-	Username string
-	Password string
-	Name     string
-	Email    string
-	Phone    string
-	Remark   string
-	Status   string
-	Created  pgtype.Timestamp
-	Updated  pgtype.Timestamp
+	Username           string
+	Password           string
+	Name               string
+	Email              string
+	Phone              string
+	Remark             string
+	Status             string
+	MustChangePassword bool
+	Created            pgtype.Timestamp
+	Updated            pgtype.Timestamp
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (AppUser, error) {
@@ -304,6 +662,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (AppUser
 		arg.Phone,
 		arg.Remark,
 		arg.Status,
+		arg.MustChangePassword,
 		arg.Created,
 		arg.Updated,
 	)
@@ -319,6 +678,13 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (AppUser
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -326,7 +692,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (AppUser
 const createUserRole = `-- name: CreateUserRole :one
 INSERT INTO user_role (user_id, role_id, created, updated)
 VALUES ($1, $2, $3, $4)
-RETURNING id, user_id, role_id, created, updated
+RETURNING id, user_id, role_id, created, updated, deleted_at
 `
 
 type CreateUserRoleParams struct {
@@ -350,10 +716,52 @@ func (q *Queries) CreateUserRole(ctx context.Context, arg CreateUserRoleParams)
 		&i.RoleID,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const deleteApiResourceBefore = `-- name: DeleteApiResourceBefore :execrows
+DELETE FROM api_resource
+WHERE updated < $1
+`
+
+func (q *Queries) DeleteApiResourceBefore(ctx context.Context, updated pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteApiResourceBefore, updated)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLoginAttempt = `-- name: DeleteLoginAttempt :execrows
+DELETE FROM login_attempt
+WHERE scope = $1 AND key = $2
+`
+
+type DeleteLoginAttemptParams struct {
+	Scope string
+	Key   string
+}
+
+func (q *Queries) DeleteLoginAttempt(ctx context.Context, arg DeleteLoginAttemptParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteLoginAttempt, arg.Scope, arg.Key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteLoginChallengeByUserID = `-- name: DeleteLoginChallengeByUserID :exec
+DELETE FROM login_challenge
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteLoginChallengeByUserID(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, deleteLoginChallengeByUserID, userID)
+	return err
+}
+
 const deleteMenu = `-- name: DeleteMenu :exec
 DELETE FROM menu
 WHERE id = $1
@@ -374,13 +782,13 @@ func (q *Queries) DeleteMenuByIdList(ctx context.Context, dollar_1 []int32) erro
 	return err
 }
 
-const deleteMenuByMenuIdList = `-- name: DeleteMenuByMenuIdList :exec
-DELETE FROM resource
-WHERE menu_id = ANY($1::int[])
+const deleteRecoveryCodeByUserID = `-- name: DeleteRecoveryCodeByUserID :exec
+DELETE FROM recovery_code
+WHERE user_id = $1
 `
 
-func (q *Queries) DeleteMenuByMenuIdList(ctx context.Context, dollar_1 []int32) error {
-	_, err := q.db.Exec(ctx, deleteMenuByMenuIdList, dollar_1)
+func (q *Queries) DeleteRecoveryCodeByUserID(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, deleteRecoveryCodeByUserID, userID)
 	return err
 }
 
@@ -404,6 +812,16 @@ func (q *Queries) DeleteResourceByMenuID(ctx context.Context, menuID int32) erro
 	return err
 }
 
+const deleteResourceByMenuIDList = `-- name: DeleteResourceByMenuIDList :exec
+DELETE FROM resource
+WHERE menu_id = ANY($1::int[])
+`
+
+func (q *Queries) DeleteResourceByMenuIDList(ctx context.Context, dollar_1 []int32) error {
+	_, err := q.db.Exec(ctx, deleteResourceByMenuIDList, dollar_1)
+	return err
+}
+
 const deleteRole = `-- name: DeleteRole :exec
 DELETE FROM role
 WHERE id = $1
@@ -444,6 +862,39 @@ func (q *Queries) DeleteRoleMenuByRoleID(ctx context.Context, roleID int32) erro
 	return err
 }
 
+const deleteRoleParentByRoleID = `-- name: DeleteRoleParentByRoleID :exec
+DELETE FROM role_parent
+WHERE role_id = $1
+`
+
+func (q *Queries) DeleteRoleParentByRoleID(ctx context.Context, roleID int32) error {
+	_, err := q.db.Exec(ctx, deleteRoleParentByRoleID, roleID)
+	return err
+}
+
+const deleteSession = `-- name: DeleteSession :execrows
+DELETE FROM session
+WHERE id = $1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSession, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSessionByUserID = `-- name: DeleteSessionByUserID :exec
+DELETE FROM session
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteSessionByUserID(ctx context.Context, userID int32) error {
+	_, err := q.db.Exec(ctx, deleteSessionByUserID, userID)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE FROM app_user
 WHERE id = $1
@@ -466,7 +917,7 @@ func (q *Queries) DeleteUserRole(ctx context.Context, id int32) error {
 
 const deleteUserRoleByUserID = `-- name: DeleteUserRoleByUserID :exec
 DELETE FROM user_role
-WHERE user_id = $1
+WHERE user_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) DeleteUserRoleByUserID(ctx context.Context, userID int32) error {
@@ -474,15 +925,50 @@ func (q *Queries) DeleteUserRoleByUserID(ctx context.Context, userID int32) erro
 	return err
 }
 
-const getMenu = `-- name: GetMenu :one
-SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated
+const failLoginAttempt = `-- name: FailLoginAttempt :one
+INSERT INTO login_attempt (scope, key, failed, updated)
+VALUES ($1, $2, 1, $3)
+ON CONFLICT (scope, key) DO UPDATE
+SET failed = CASE WHEN login_attempt.updated < $4 THEN 1
+ELSE login_attempt.failed + 1 END,
+updated = $3
+RETURNING id, scope, key, failed, locked_until, updated
+`
+
+type FailLoginAttemptParams struct {
+	Scope       string
+	Key         string
+	Updated     pgtype.Timestamp
+	WindowStart pgtype.Timestamp
+}
+
+func (q *Queries) FailLoginAttempt(ctx context.Context, arg FailLoginAttemptParams) (LoginAttempt, error) {
+	row := q.db.QueryRow(ctx, failLoginAttempt,
+		arg.Scope,
+		arg.Key,
+		arg.Updated,
+		arg.WindowStart,
+	)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.ID,
+		&i.Scope,
+		&i.Key,
+		&i.Failed,
+		&i.LockedUntil,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getDeletedMenu = `-- name: GetDeletedMenu :one
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
 FROM menu
-WHERE id = $1 LIMIT 1
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
 `
 
-// ------------------------------- Menu --------------------------------
-func (q *Queries) GetMenu(ctx context.Context, id int32) (Menu, error) {
-	row := q.db.QueryRow(ctx, getMenu, id)
+func (q *Queries) GetDeletedMenu(ctx context.Context, id int32) (Menu, error) {
+	row := q.db.QueryRow(ctx, getDeletedMenu, id)
 	var i Menu
 	err := row.Scan(
 		&i.ID,
@@ -498,35 +984,186 @@ func (q *Queries) GetMenu(ctx context.Context, id int32) (Menu, error) {
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const getResource = `-- name: GetResource :one
-SELECT id, menu_id, method, path, created, updated
-FROM resource
-WHERE id = $1 LIMIT 1
+const getDeletedRole = `-- name: GetDeletedRole :one
+SELECT id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
+FROM role
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
 `
 
-// ------------------------------- Resource --------------------------------
-func (q *Queries) GetResource(ctx context.Context, id int32) (Resource, error) {
-	row := q.db.QueryRow(ctx, getResource, id)
-	var i Resource
+func (q *Queries) GetDeletedRole(ctx context.Context, id int32) (Role, error) {
+	row := q.db.QueryRow(ctx, getDeletedRole, id)
+	var i Role
 	err := row.Scan(
 		&i.ID,
-		&i.MenuID,
-		&i.Method,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Sequence,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
+	)
+	return i, err
+}
+
+const getDeletedUser = `-- name: GetDeletedUser :one
+SELECT id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+FROM app_user
+WHERE id = $1 AND deleted_at IS NOT NULL LIMIT 1
+`
+
+func (q *Queries) GetDeletedUser(ctx context.Context, id int32) (AppUser, error) {
+	row := q.db.QueryRow(ctx, getDeletedUser, id)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getLoginAttempt = `-- name: GetLoginAttempt :one
+SELECT id, scope, key, failed, locked_until, updated
+FROM login_attempt
+WHERE scope = $1 AND key = $2 LIMIT 1
+`
+
+type GetLoginAttemptParams struct {
+	Scope string
+	Key   string
+}
+
+// ----------------------------- LoginAttempt -------------------------------
+func (q *Queries) GetLoginAttempt(ctx context.Context, arg GetLoginAttemptParams) (LoginAttempt, error) {
+	row := q.db.QueryRow(ctx, getLoginAttempt, arg.Scope, arg.Key)
+	var i LoginAttempt
+	err := row.Scan(
+		&i.ID,
+		&i.Scope,
+		&i.Key,
+		&i.Failed,
+		&i.LockedUntil,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getMenu = `-- name: GetMenu :one
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+FROM menu
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+// ------------------------------- Menu --------------------------------
+func (q *Queries) GetMenu(ctx context.Context, id int32) (Menu, error) {
+	row := q.db.QueryRow(ctx, getMenu, id)
+	var i Menu
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Sequence,
+		&i.Type,
+		&i.Path,
+		&i.Property,
+		&i.ParentID,
+		&i.ParentPath,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const getMenuVersion = `-- name: GetMenuVersion :one
+SELECT version
+FROM menu
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetMenuVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getMenuVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const getRefreshTokenByToken = `-- name: GetRefreshTokenByToken :one
+SELECT id, user_id, token, expired, revoked, created, updated, session_id
+FROM refresh_token
+WHERE token = $1 LIMIT 1
+`
+
+// ------------------------------- RefreshToken --------------------------------
+func (q *Queries) GetRefreshTokenByToken(ctx context.Context, token string) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, getRefreshTokenByToken, token)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Token,
+		&i.Expired,
+		&i.Revoked,
+		&i.Created,
+		&i.Updated,
+		&i.SessionID,
+	)
+	return i, err
+}
+
+const getResource = `-- name: GetResource :one
+SELECT id, menu_id, method, path, created, updated, deleted_at
+FROM resource
+WHERE id = $1 LIMIT 1
+`
+
+// ------------------------------- Resource --------------------------------
+func (q *Queries) GetResource(ctx context.Context, id int32) (Resource, error) {
+	row := q.db.QueryRow(ctx, getResource, id)
+	var i Resource
+	err := row.Scan(
+		&i.ID,
+		&i.MenuID,
+		&i.Method,
 		&i.Path,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getRole = `-- name: GetRole :one
-SELECT id, code, name, description, sequence, status, created, updated
+SELECT id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
 FROM role
-WHERE id = $1 LIMIT 1
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
 `
 
 // ------------------------------- Role --------------------------------
@@ -542,12 +1179,15 @@ func (q *Queries) GetRole(ctx context.Context, id int32) (Role, error) {
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
 	)
 	return i, err
 }
 
 const getRoleMenu = `-- name: GetRoleMenu :one
-SELECT id, role_id, menu_id, created, updated
+SELECT id, role_id, menu_id, created, updated, deleted_at
 FROM role_menu
 WHERE id = $1 LIMIT 1
 `
@@ -562,16 +1202,82 @@ func (q *Queries) GetRoleMenu(ctx context.Context, id int32) (RoleMenu, error) {
 		&i.MenuID,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const getUser = `-- name: GetUser :one
-SELECT id, username, password, name, email, phone, remark, status, created, updated
+const getRoleVersion = `-- name: GetRoleVersion :one
+SELECT version
+FROM role
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetRoleVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getRoleVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const getRootUser = `-- name: GetRootUser :one
+SELECT id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
 FROM app_user
+WHERE is_root LIMIT 1
+`
+
+func (q *Queries) GetRootUser(ctx context.Context) (AppUser, error) {
+	row := q.db.QueryRow(ctx, getRootUser)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, user_id, user_agent, ip, created, last_seen, expired
+FROM session
 WHERE id = $1 LIMIT 1
 `
 
+func (q *Queries) GetSession(ctx context.Context, id int32) (Session, error) {
+	row := q.db.QueryRow(ctx, getSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.UserAgent,
+		&i.Ip,
+		&i.Created,
+		&i.LastSeen,
+		&i.Expired,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+FROM app_user
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
 // ------------------------------- User --------------------------------
 func (q *Queries) GetUser(ctx context.Context, id int32) (AppUser, error) {
 	row := q.db.QueryRow(ctx, getUser, id)
@@ -587,267 +1293,2020 @@ func (q *Queries) GetUser(ctx context.Context, id int32) (AppUser, error) {
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+FROM app_user
+WHERE username = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (AppUser, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
 
 const getUserRole = `-- name: GetUserRole :one
-SELECT id, user_id, role_id, created, updated
+SELECT id, user_id, role_id, created, updated, deleted_at
 FROM user_role
 WHERE id = $1 LIMIT 1
 `
 
-// ------------------------------- UserRole --------------------------------
-func (q *Queries) GetUserRole(ctx context.Context, id int32) (UserRole, error) {
-	row := q.db.QueryRow(ctx, getUserRole, id)
-	var i UserRole
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.RoleID,
-		&i.Created,
-		&i.Updated,
-	)
-	return i, err
+// ------------------------------- UserRole --------------------------------
+func (q *Queries) GetUserRole(ctx context.Context, id int32) (UserRole, error) {
+	row := q.db.QueryRow(ctx, getUserRole, id)
+	var i UserRole
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RoleID,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserVersion = `-- name: GetUserVersion :one
+SELECT version
+FROM app_user
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getUserVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const listApiResource = `-- name: ListApiResource :many
+SELECT id, method, path, operation_id, tag, description, created, updated
+FROM api_resource
+WHERE ($1::VARCHAR = '' OR tag = $1)
+ORDER BY path, method
+`
+
+// ----------------------------- ApiResource -------------------------------
+func (q *Queries) ListApiResource(ctx context.Context, tag string) ([]ApiResource, error) {
+	rows, err := q.db.Query(ctx, listApiResource, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiResource
+	for rows.Next() {
+		var i ApiResource
+		if err := rows.Scan(
+			&i.ID,
+			&i.Method,
+			&i.Path,
+			&i.OperationID,
+			&i.Tag,
+			&i.Description,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLog = `-- name: ListAuditLog :many
+SELECT id, actor_id, action, entity, entity_id, before, after, ip, request_id, created
+FROM audit_log
+WHERE ($1::INTEGER IS NULL OR actor_id = $1)
+AND ($2::VARCHAR = '' OR entity = $2)
+AND ($3::INTEGER IS NULL OR entity_id = $3)
+AND ($4::TIMESTAMP IS NULL OR created >= $4)
+AND ($5::TIMESTAMP IS NULL OR created < $5)
+ORDER BY id DESC
+OFFSET $6 LIMIT $7
+`
+
+type ListAuditLogParams struct {
+	ActorID  pgtype.Int4
+	Entity   string
+	EntityID pgtype.Int4
+	Start    pgtype.Timestamp
+	End      pgtype.Timestamp
+	Offset   int32
+	Limit    int32
+}
+
+func (q *Queries) ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditLog,
+		arg.ActorID,
+		arg.Entity,
+		arg.EntityID,
+		arg.Start,
+		arg.End,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.Entity,
+			&i.EntityID,
+			&i.Before,
+			&i.After,
+			&i.Ip,
+			&i.RequestID,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChildID = `-- name: ListChildID :many
+SELECT id
+FROM menu
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at IS NULL
+`
+
+func (q *Queries) ListChildID(ctx context.Context, dollar_1 string) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listChildID, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedChildID = `-- name: ListDeletedChildID :many
+SELECT id
+FROM menu
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at = $2
+`
+
+type ListDeletedChildIDParams struct {
+	Column1   string
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) ListDeletedChildID(ctx context.Context, arg ListDeletedChildIDParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listDeletedChildID, arg.Column1, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledMenu = `-- name: ListEnabledMenu :many
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+FROM menu
+WHERE status = 'enabled'
+AND deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY sequence, id
+`
+
+func (q *Queries) ListEnabledMenu(ctx context.Context) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listEnabledMenu)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInheritedRoleID = `-- name: ListInheritedRoleID :many
+WITH RECURSIVE granting AS (
+  SELECT role_parent.parent_id AS id
+  FROM role_parent
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role_parent.role_id = $1
+  AND role.status = 'enabled'
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT id::INTEGER
+FROM granting
+ORDER BY id
+`
+
+func (q *Queries) ListInheritedRoleID(ctx context.Context, roleID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listInheritedRoleID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoginLog = `-- name: ListLoginLog :many
+SELECT id, user_id, username, ip, user_agent, result, reason, created
+FROM login_log
+WHERE ($1::INTEGER IS NULL OR user_id = $1)
+AND ($2::VARCHAR = '' OR ip = $2)
+AND ($3::VARCHAR = '' OR result = $3)
+AND ($4::TIMESTAMP IS NULL OR created >= $4)
+AND ($5::TIMESTAMP IS NULL OR created < $5)
+ORDER BY id DESC
+OFFSET $6 LIMIT $7
+`
+
+type ListLoginLogParams struct {
+	UserID pgtype.Int4
+	Ip     string
+	Result string
+	Start  pgtype.Timestamp
+	End    pgtype.Timestamp
+	Offset int32
+	Limit  int32
+}
+
+func (q *Queries) ListLoginLog(ctx context.Context, arg ListLoginLogParams) ([]LoginLog, error) {
+	rows, err := q.db.Query(ctx, listLoginLog,
+		arg.UserID,
+		arg.Ip,
+		arg.Result,
+		arg.Start,
+		arg.End,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginLog
+	for rows.Next() {
+		var i LoginLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Ip,
+			&i.UserAgent,
+			&i.Result,
+			&i.Reason,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuByIDList = `-- name: ListMenuByIDList :many
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+FROM menu
+WHERE id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ListMenuByIDList(ctx context.Context, dollar_1 []int32) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuByIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuByParentID = `-- name: ListMenuByParentID :many
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+FROM menu
+WHERE parent_id IS NOT DISTINCT FROM $1 AND deleted_at IS NULL
+ORDER BY sequence, id
+FOR UPDATE
+`
+
+func (q *Queries) ListMenuByParentID(ctx context.Context, parentID pgtype.Int4) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuByParentID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuByParentPath = `-- name: ListMenuByParentPath :many
+SELECT id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+FROM menu
+WHERE parent_path LIKE $1::VARCHAR || '%' AND deleted_at IS NULL
+ORDER BY sequence, id
+`
+
+func (q *Queries) ListMenuByParentPath(ctx context.Context, dollar_1 string) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuByParentPath, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuByRoleIDList = `-- name: ListMenuByRoleIDList :many
+SELECT DISTINCT menu.id, menu.code, menu.name, menu.description, menu.sequence, menu.type, menu.path, menu.property, menu.parent_id, menu.parent_path, menu.status, menu.created, menu.updated, menu.deleted_at, menu.version
+FROM menu
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id = ANY($1::int[])
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY menu.sequence, menu.id
+`
+
+func (q *Queries) ListMenuByRoleIDList(ctx context.Context, dollar_1 []int32) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuByRoleIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuByUserID = `-- name: ListMenuByUserID :many
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT DISTINCT menu.id, menu.code, menu.name, menu.description, menu.sequence, menu.type, menu.path, menu.property, menu.parent_id, menu.parent_path, menu.status, menu.created, menu.updated, menu.deleted_at, menu.version
+FROM menu
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id IN (SELECT id FROM granting)
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+ORDER BY menu.sequence, menu.id
+`
+
+func (q *Queries) ListMenuByUserID(ctx context.Context, userID int32) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPasswordHistoryByUserID = `-- name: ListPasswordHistoryByUserID :many
+SELECT password
+FROM password_history
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListPasswordHistoryByUserIDParams struct {
+	UserID int32
+	Limit  int32
+}
+
+// ----------------------------- PasswordHistory -------------------------------
+func (q *Queries) ListPasswordHistoryByUserID(ctx context.Context, arg ListPasswordHistoryByUserIDParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listPasswordHistoryByUserID, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var password string
+		if err := rows.Scan(&password); err != nil {
+			return nil, err
+		}
+		items = append(items, password)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResource = `-- name: ListResource :many
+SELECT resource.id, resource.menu_id, resource.method, resource.path, resource.created, resource.updated, resource.deleted_at
+FROM resource
+JOIN menu ON menu.id = resource.menu_id
+WHERE resource.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+ORDER BY resource.id
+`
+
+func (q *Queries) ListResource(ctx context.Context) ([]Resource, error) {
+	rows, err := q.db.Query(ctx, listResource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Resource
+	for rows.Next() {
+		var i Resource
+		if err := rows.Scan(
+			&i.ID,
+			&i.MenuID,
+			&i.Method,
+			&i.Path,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResourceByMenuIDList = `-- name: ListResourceByMenuIDList :many
+SELECT id, menu_id, method, path, created, updated, deleted_at
+FROM resource
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ListResourceByMenuIDList(ctx context.Context, dollar_1 []int32) ([]Resource, error) {
+	rows, err := q.db.Query(ctx, listResourceByMenuIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Resource
+	for rows.Next() {
+		var i Resource
+		if err := rows.Scan(
+			&i.ID,
+			&i.MenuID,
+			&i.Method,
+			&i.Path,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResourceByUserID = `-- name: ListResourceByUserID :many
+WITH RECURSIVE granting AS (
+  SELECT role.id
+  FROM user_role
+  JOIN role ON role.id = user_role.role_id
+  WHERE user_role.user_id = $1
+  AND role.status = 'enabled'
+  AND user_role.deleted_at IS NULL
+  AND role.deleted_at IS NULL
+  UNION
+  SELECT role.id
+  FROM granting
+  JOIN role_parent ON role_parent.role_id = granting.id
+  JOIN role ON role.id = role_parent.parent_id
+  WHERE role.status = 'enabled'
+  AND role.deleted_at IS NULL
+)
+SELECT DISTINCT resource.id, resource.menu_id, resource.method, resource.path, resource.created, resource.updated, resource.deleted_at
+FROM resource
+JOIN menu ON menu.id = resource.menu_id
+JOIN role_menu ON role_menu.menu_id = menu.id
+WHERE role_menu.role_id IN (SELECT id FROM granting)
+AND menu.status = 'enabled'
+AND role_menu.deleted_at IS NULL
+AND menu.deleted_at IS NULL
+AND resource.deleted_at IS NULL
+AND NOT EXISTS (
+  SELECT 1 FROM menu AS ancestor
+  WHERE ancestor.status <> 'enabled'
+  AND menu.parent_path LIKE ancestor.parent_path || ancestor.id || '.%'
+)
+`
+
+func (q *Queries) ListResourceByUserID(ctx context.Context, userID int32) ([]Resource, error) {
+	rows, err := q.db.Query(ctx, listResourceByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Resource
+	for rows.Next() {
+		var i Resource
+		if err := rows.Scan(
+			&i.ID,
+			&i.MenuID,
+			&i.Method,
+			&i.Path,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleByCodeList = `-- name: ListRoleByCodeList :many
+SELECT id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
+FROM role
+WHERE code = ANY($1::varchar[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ListRoleByCodeList(ctx context.Context, dollar_1 []string) ([]Role, error) {
+	rows, err := q.db.Query(ctx, listRoleByCodeList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Role
+	for rows.Next() {
+		var i Role
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+			&i.Require2fa,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleCodeByUserIDList = `-- name: ListRoleCodeByUserIDList :many
+SELECT user_role.user_id, role.code
+FROM user_role
+JOIN role ON role.id = user_role.role_id
+WHERE user_role.user_id = ANY($1::int[])
+AND user_role.deleted_at IS NULL AND role.deleted_at IS NULL
+ORDER BY role.sequence, role.id
+`
+
+type ListRoleCodeByUserIDListRow struct {
+	UserID int32
+	Code   string
+}
+
+func (q *Queries) ListRoleCodeByUserIDList(ctx context.Context, dollar_1 []int32) ([]ListRoleCodeByUserIDListRow, error) {
+	rows, err := q.db.Query(ctx, listRoleCodeByUserIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoleCodeByUserIDListRow
+	for rows.Next() {
+		var i ListRoleCodeByUserIDListRow
+		if err := rows.Scan(&i.UserID, &i.Code); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleMenuByRoleIDList = `-- name: ListRoleMenuByRoleIDList :many
+SELECT id, role_id, menu_id, created, updated, deleted_at
+FROM role_menu
+WHERE role_id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ListRoleMenuByRoleIDList(ctx context.Context, dollar_1 []int32) ([]RoleMenu, error) {
+	rows, err := q.db.Query(ctx, listRoleMenuByRoleIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleMenu
+	for rows.Next() {
+		var i RoleMenu
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoleID,
+			&i.MenuID,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoleParentByRoleID = `-- name: ListRoleParentByRoleID :many
+SELECT id, role_id, parent_id, created, updated
+FROM role_parent
+WHERE role_id = $1
+ORDER BY parent_id
+`
+
+// ------------------------------- RoleParent --------------------------------
+func (q *Queries) ListRoleParentByRoleID(ctx context.Context, roleID int32) ([]RoleParent, error) {
+	rows, err := q.db.Query(ctx, listRoleParentByRoleID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleParent
+	for rows.Next() {
+		var i RoleParent
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoleID,
+			&i.ParentID,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSession = `-- name: ListSession :many
+SELECT id, user_id, user_agent, ip, created, last_seen, expired
+FROM session
+WHERE ($1::INTEGER IS NULL OR user_id = $1)
+AND expired > $2
+ORDER BY last_seen DESC, id DESC
+OFFSET $3 LIMIT $4
+`
+
+type ListSessionParams struct {
+	UserID pgtype.Int4
+	Now    pgtype.Timestamp
+	Offset int32
+	Limit  int32
+}
+
+func (q *Queries) ListSession(ctx context.Context, arg ListSessionParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSession,
+		arg.UserID,
+		arg.Now,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Session
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.Ip,
+			&i.Created,
+			&i.LastSeen,
+			&i.Expired,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRoleByUserIDList = `-- name: ListUserRoleByUserIDList :many
+SELECT id, user_id, role_id, created, updated, deleted_at
+FROM user_role
+WHERE user_id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) ListUserRoleByUserIDList(ctx context.Context, dollar_1 []int32) ([]UserRole, error) {
+	rows, err := q.db.Query(ctx, listUserRoleByUserIDList, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserRole
+	for rows.Next() {
+		var i UserRole
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RoleID,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockLoginAttempt = `-- name: LockLoginAttempt :exec
+UPDATE login_attempt
+SET locked_until = $2
+WHERE id = $1
+`
+
+type LockLoginAttemptParams struct {
+	ID          int32
+	LockedUntil pgtype.Timestamp
+}
+
+func (q *Queries) LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) error {
+	_, err := q.db.Exec(ctx, lockLoginAttempt, arg.ID, arg.LockedUntil)
+	return err
+}
+
+const lockMenuTree = `-- name: LockMenuTree :exec
+SELECT pg_advisory_xact_lock(hashtext('menu_tree'))
+`
+
+func (q *Queries) LockMenuTree(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockMenuTree)
+	return err
+}
+
+const lockMenuVersion = `-- name: LockMenuVersion :one
+SELECT version
+FROM menu
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) LockMenuVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockMenuVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const lockRoleTree = `-- name: LockRoleTree :exec
+SELECT pg_advisory_xact_lock(hashtext('role_tree'))
+`
+
+func (q *Queries) LockRoleTree(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockRoleTree)
+	return err
+}
+
+const lockRoleVersion = `-- name: LockRoleVersion :one
+SELECT version
+FROM role
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) LockRoleVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockRoleVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const lockUserVersion = `-- name: LockUserVersion :one
+SELECT version
+FROM app_user
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) LockUserVersion(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockUserVersion, id)
+	var version int32
+	err := row.Scan(&version)
+	return version, err
+}
+
+const markRootUser = `-- name: MarkRootUser :exec
+UPDATE app_user
+SET is_root = true
+WHERE id = $1
+`
+
+func (q *Queries) MarkRootUser(ctx context.Context, id int32) error {
+	_, err := q.db.Exec(ctx, markRootUser, id)
+	return err
+}
+
+const moveMenu = `-- name: MoveMenu :many
+UPDATE menu
+SET parent_id = CASE WHEN id = $1::INTEGER THEN $2::INTEGER ELSE parent_id END,
+parent_path = $3::VARCHAR || substr(parent_path, length($4::VARCHAR) + 1),
+updated = $5,
+version = version + 1
+WHERE id = $1::INTEGER
+OR parent_path LIKE $4::VARCHAR || $1::INTEGER || '.%'
+RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+`
+
+type MoveMenuParams struct {
+	ID            int32
+	ParentID      pgtype.Int4
+	ParentPath    string
+	OldParentPath string
+	Updated       pgtype.Timestamp
+}
+
+func (q *Queries) MoveMenu(ctx context.Context, arg MoveMenuParams) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, moveMenu,
+		arg.ID,
+		arg.ParentID,
+		arg.ParentPath,
+		arg.OldParentPath,
+		arg.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const patchMenu = `-- name: PatchMenu :one
+UPDATE menu
+SET code = COALESCE($1, code),
+name = COALESCE($2, name),
+description = COALESCE($3, description),
+sequence = COALESCE($4, sequence),
+type = COALESCE($5, type),
+path = COALESCE($6, path),
+property = COALESCE($7, property),
+status = COALESCE($8, status),
+created = COALESCE($9, created),
+updated = $10,
+version = version + 1
+WHERE id = $11 AND deleted_at IS NULL
+RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
+`
+
+type PatchMenuParams struct {
+	Code        pgtype.Text
+	Name        pgtype.Text
+	Description pgtype.Text
+	Sequence    pgtype.Int2
+	Type        pgtype.Text
+	Path        pgtype.Text
+	Property    pgtype.Text
+	Status      pgtype.Text
+	Created     pgtype.Timestamp
+	Updated     pgtype.Timestamp
+	ID          int32
+}
+
+func (q *Queries) PatchMenu(ctx context.Context, arg PatchMenuParams) (Menu, error) {
+	row := q.db.QueryRow(ctx, patchMenu,
+		arg.Code,
+		arg.Name,
+		arg.Description,
+		arg.Sequence,
+		arg.Type,
+		arg.Path,
+		arg.Property,
+		arg.Status,
+		arg.Created,
+		arg.Updated,
+		arg.ID,
+	)
+	var i Menu
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Sequence,
+		&i.Type,
+		&i.Path,
+		&i.Property,
+		&i.ParentID,
+		&i.ParentPath,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const patchRole = `-- name: PatchRole :one
+UPDATE role
+SET code = COALESCE($1, code),
+name = COALESCE($2, name),
+description = COALESCE($3, description),
+sequence = COALESCE($4, sequence),
+status = COALESCE($5, status),
+require_2fa = COALESCE($6, require_2fa),
+created = COALESCE($7, created),
+updated = $8,
+version = version + 1
+WHERE id = $9 AND deleted_at IS NULL
+RETURNING id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
+`
+
+type PatchRoleParams struct {
+	Code        pgtype.Text
+	Name        pgtype.Text
+	Description pgtype.Text
+	Sequence    pgtype.Int2
+	Status      pgtype.Text
+	Require2fa  pgtype.Bool
+	Created     pgtype.Timestamp
+	Updated     pgtype.Timestamp
+	ID          int32
+}
+
+func (q *Queries) PatchRole(ctx context.Context, arg PatchRoleParams) (Role, error) {
+	row := q.db.QueryRow(ctx, patchRole,
+		arg.Code,
+		arg.Name,
+		arg.Description,
+		arg.Sequence,
+		arg.Status,
+		arg.Require2fa,
+		arg.Created,
+		arg.Updated,
+		arg.ID,
+	)
+	var i Role
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Sequence,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
+	)
+	return i, err
+}
+
+const patchUser = `-- name: PatchUser :one
+UPDATE app_user
+SET username = COALESCE($1, username),
+password = COALESCE($2, password),
+name = COALESCE($3, name),
+email = COALESCE($4, email),
+phone = COALESCE($5, phone),
+remark = COALESCE($6, remark),
+status = COALESCE($7, status),
+created = COALESCE($8, created),
+updated = $9,
+version = version + 1
+WHERE id = $10 AND deleted_at IS NULL
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+`
+
+type PatchUserParams struct {
+	Username pgtype.Text
+	Password pgtype.Text
+	Name     pgtype.Text
+	Email    pgtype.Text
+	Phone    pgtype.Text
+	Remark   pgtype.Text
+	Status   pgtype.Text
+	Created  pgtype.Timestamp
+	Updated  pgtype.Timestamp
+	ID       int32
+}
+
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) (AppUser, error) {
+	row := q.db.QueryRow(ctx, patchUser,
+		arg.Username,
+		arg.Password,
+		arg.Name,
+		arg.Email,
+		arg.Phone,
+		arg.Remark,
+		arg.Status,
+		arg.Created,
+		arg.Updated,
+		arg.ID,
+	)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const purgeMenu = `-- name: PurgeMenu :execrows
+DELETE FROM menu
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeMenu(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeMenu, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeRefreshToken = `-- name: PurgeRefreshToken :execrows
+DELETE FROM refresh_token
+WHERE expired < $1
+`
+
+func (q *Queries) PurgeRefreshToken(ctx context.Context, expired pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeRefreshToken, expired)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeResource = `-- name: PurgeResource :execrows
+DELETE FROM resource
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeResource(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeResource, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeRole = `-- name: PurgeRole :execrows
+DELETE FROM role
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeRole(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeRole, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeRoleMenu = `-- name: PurgeRoleMenu :execrows
+DELETE FROM role_menu
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeRoleMenu(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeRoleMenu, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeSession = `-- name: PurgeSession :execrows
+DELETE FROM session
+WHERE expired < $1
+`
+
+func (q *Queries) PurgeSession(ctx context.Context, expired pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeSession, expired)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeUser = `-- name: PurgeUser :execrows
+DELETE FROM app_user
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeUser(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUser, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeUserRole = `-- name: PurgeUserRole :execrows
+DELETE FROM user_role
+WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeUserRole(ctx context.Context, deletedAt pgtype.Timestamp) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUserRole, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :exec
+UPDATE app_user
+SET password = $2
+WHERE id = $1
+`
+
+type RehashUserPasswordParams struct {
+	ID       int32
+	Password string
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, rehashUserPassword, arg.ID, arg.Password)
+	return err
+}
+
+const renewSession = `-- name: RenewSession :exec
+UPDATE session
+SET last_seen = $2, expired = $3
+WHERE id = $1
+`
+
+type RenewSessionParams struct {
+	ID       int32
+	LastSeen pgtype.Timestamp
+	Expired  pgtype.Timestamp
+}
+
+func (q *Queries) RenewSession(ctx context.Context, arg RenewSessionParams) error {
+	_, err := q.db.Exec(ctx, renewSession, arg.ID, arg.LastSeen, arg.Expired)
+	return err
+}
+
+const reorderMenu = `-- name: ReorderMenu :many
+UPDATE menu
+SET sequence = ordered.position::SMALLINT, updated = $1, version = version + 1
+FROM unnest($3::int[]) WITH ORDINALITY AS ordered(id, position)
+WHERE menu.id = ordered.id
+AND menu.parent_id IS NOT DISTINCT FROM $2::INTEGER
+AND menu.deleted_at IS NULL
+RETURNING menu.id, menu.code, menu.name, menu.description, menu.sequence, menu.type, menu.path, menu.property, menu.parent_id, menu.parent_path, menu.status, menu.created, menu.updated, menu.deleted_at, menu.version
+`
+
+type ReorderMenuParams struct {
+	Updated  pgtype.Timestamp
+	ParentID pgtype.Int4
+	IDList   []int32
+}
+
+func (q *Queries) ReorderMenu(ctx context.Context, arg ReorderMenuParams) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, reorderMenu, arg.Updated, arg.ParentID, arg.IDList)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.Description,
+			&i.Sequence,
+			&i.Type,
+			&i.Path,
+			&i.Property,
+			&i.ParentID,
+			&i.ParentPath,
+			&i.Status,
+			&i.Created,
+			&i.Updated,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreMenuByIDList = `-- name: RestoreMenuByIDList :exec
+UPDATE menu
+SET deleted_at = NULL, updated = $3, version = version + 1
+WHERE id = ANY($1::int[]) AND deleted_at = $2
+`
+
+type RestoreMenuByIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
+	Updated   pgtype.Timestamp
+}
+
+func (q *Queries) RestoreMenuByIDList(ctx context.Context, arg RestoreMenuByIDListParams) error {
+	_, err := q.db.Exec(ctx, restoreMenuByIDList, arg.Column1, arg.DeletedAt, arg.Updated)
+	return err
+}
+
+const restoreResourceByMenuIDList = `-- name: RestoreResourceByMenuIDList :exec
+UPDATE resource
+SET deleted_at = NULL
+WHERE menu_id = ANY($1::int[]) AND deleted_at = $2
+`
+
+type RestoreResourceByMenuIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreResourceByMenuIDList(ctx context.Context, arg RestoreResourceByMenuIDListParams) error {
+	_, err := q.db.Exec(ctx, restoreResourceByMenuIDList, arg.Column1, arg.DeletedAt)
+	return err
+}
+
+const restoreRole = `-- name: RestoreRole :one
+UPDATE role
+SET deleted_at = NULL, updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
+`
+
+type RestoreRoleParams struct {
+	ID      int32
+	Updated pgtype.Timestamp
+}
+
+func (q *Queries) RestoreRole(ctx context.Context, arg RestoreRoleParams) (Role, error) {
+	row := q.db.QueryRow(ctx, restoreRole, arg.ID, arg.Updated)
+	var i Role
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Name,
+		&i.Description,
+		&i.Sequence,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
+	)
+	return i, err
+}
+
+const restoreRoleMenuByMenuIDList = `-- name: RestoreRoleMenuByMenuIDList :exec
+UPDATE role_menu
+SET deleted_at = NULL
+WHERE role_menu.menu_id = ANY($1::int[]) AND role_menu.deleted_at = $2
+AND role_menu.role_id IN (SELECT id FROM role WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM role_menu AS live
+  WHERE live.role_id = role_menu.role_id AND live.menu_id = role_menu.menu_id
+  AND live.deleted_at IS NULL
+)
+`
+
+type RestoreRoleMenuByMenuIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreRoleMenuByMenuIDList(ctx context.Context, arg RestoreRoleMenuByMenuIDListParams) error {
+	_, err := q.db.Exec(ctx, restoreRoleMenuByMenuIDList, arg.Column1, arg.DeletedAt)
+	return err
+}
+
+const restoreRoleMenuByRoleID = `-- name: RestoreRoleMenuByRoleID :exec
+UPDATE role_menu
+SET deleted_at = NULL
+WHERE role_menu.role_id = $1 AND role_menu.deleted_at = $2
+AND role_menu.menu_id IN (SELECT id FROM menu WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM role_menu AS live
+  WHERE live.role_id = role_menu.role_id AND live.menu_id = role_menu.menu_id
+  AND live.deleted_at IS NULL
+)
+`
+
+type RestoreRoleMenuByRoleIDParams struct {
+	RoleID    int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreRoleMenuByRoleID(ctx context.Context, arg RestoreRoleMenuByRoleIDParams) error {
+	_, err := q.db.Exec(ctx, restoreRoleMenuByRoleID, arg.RoleID, arg.DeletedAt)
+	return err
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE app_user
+SET deleted_at = NULL, updated = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+`
+
+type RestoreUserParams struct {
+	ID      int32
+	Updated pgtype.Timestamp
+}
+
+func (q *Queries) RestoreUser(ctx context.Context, arg RestoreUserParams) (AppUser, error) {
+	row := q.db.QueryRow(ctx, restoreUser, arg.ID, arg.Updated)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const restoreUserRoleByRoleID = `-- name: RestoreUserRoleByRoleID :exec
+UPDATE user_role
+SET deleted_at = NULL
+WHERE user_role.role_id = $1 AND user_role.deleted_at = $2
+AND user_role.user_id IN (SELECT id FROM app_user WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM user_role AS live
+  WHERE live.user_id = user_role.user_id AND live.role_id = user_role.role_id
+  AND live.deleted_at IS NULL
+)
+`
+
+type RestoreUserRoleByRoleIDParams struct {
+	RoleID    int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreUserRoleByRoleID(ctx context.Context, arg RestoreUserRoleByRoleIDParams) error {
+	_, err := q.db.Exec(ctx, restoreUserRoleByRoleID, arg.RoleID, arg.DeletedAt)
+	return err
+}
+
+const restoreUserRoleByUserID = `-- name: RestoreUserRoleByUserID :exec
+UPDATE user_role
+SET deleted_at = NULL
+WHERE user_role.user_id = $1 AND user_role.deleted_at = $2
+AND user_role.role_id IN (SELECT id FROM role WHERE deleted_at IS NULL)
+AND NOT EXISTS (
+  SELECT 1 FROM user_role AS live
+  WHERE live.user_id = user_role.user_id AND live.role_id = user_role.role_id
+  AND live.deleted_at IS NULL
+)
+`
+
+type RestoreUserRoleByUserIDParams struct {
+	UserID    int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) RestoreUserRoleByUserID(ctx context.Context, arg RestoreUserRoleByUserIDParams) error {
+	_, err := q.db.Exec(ctx, restoreUserRoleByUserID, arg.UserID, arg.DeletedAt)
+	return err
+}
+
+const revokeRefreshToken = `-- name: RevokeRefreshToken :execrows
+UPDATE refresh_token
+SET revoked = TRUE, updated = $2
+WHERE id = $1 AND revoked = FALSE
+`
+
+type RevokeRefreshTokenParams struct {
+	ID      int32
+	Updated pgtype.Timestamp
+}
+
+func (q *Queries) RevokeRefreshToken(ctx context.Context, arg RevokeRefreshTokenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeRefreshToken, arg.ID, arg.Updated)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setUserPassword = `-- name: SetUserPassword :one
+UPDATE app_user
+SET password = $2, must_change_password = $3, updated = $4, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+`
+
+type SetUserPasswordParams struct {
+	ID                 int32
+	Password           string
+	MustChangePassword bool
+	Updated            pgtype.Timestamp
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) (AppUser, error) {
+	row := q.db.QueryRow(ctx, setUserPassword,
+		arg.ID,
+		arg.Password,
+		arg.MustChangePassword,
+		arg.Updated,
+	)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const setUserTOTP = `-- name: SetUserTOTP :one
+UPDATE app_user
+SET totp_secret = $2, totp_enabled = $3, updated = $4, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
+`
+
+type SetUserTOTPParams struct {
+	ID          int32
+	TotpSecret  string
+	TotpEnabled bool
+	Updated     pgtype.Timestamp
+}
+
+func (q *Queries) SetUserTOTP(ctx context.Context, arg SetUserTOTPParams) (AppUser, error) {
+	row := q.db.QueryRow(ctx, setUserTOTP,
+		arg.ID,
+		arg.TotpSecret,
+		arg.TotpEnabled,
+		arg.Updated,
+	)
+	var i AppUser
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+		&i.Remark,
+		&i.Status,
+		&i.Created,
+		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
+	)
+	return i, err
+}
+
+const shareMenuTree = `-- name: ShareMenuTree :exec
+SELECT pg_advisory_xact_lock_shared(hashtext('menu_tree'))
+`
+
+func (q *Queries) ShareMenuTree(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, shareMenuTree)
+	return err
+}
+
+const softDeleteMenuByIDList = `-- name: SoftDeleteMenuByIDList :exec
+UPDATE menu
+SET deleted_at = $2, version = version + 1
+WHERE id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+type SoftDeleteMenuByIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) SoftDeleteMenuByIDList(ctx context.Context, arg SoftDeleteMenuByIDListParams) error {
+	_, err := q.db.Exec(ctx, softDeleteMenuByIDList, arg.Column1, arg.DeletedAt)
+	return err
+}
+
+const softDeleteResourceByMenuIDList = `-- name: SoftDeleteResourceByMenuIDList :exec
+UPDATE resource
+SET deleted_at = $2
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+type SoftDeleteResourceByMenuIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) SoftDeleteResourceByMenuIDList(ctx context.Context, arg SoftDeleteResourceByMenuIDListParams) error {
+	_, err := q.db.Exec(ctx, softDeleteResourceByMenuIDList, arg.Column1, arg.DeletedAt)
+	return err
+}
+
+const softDeleteRole = `-- name: SoftDeleteRole :exec
+UPDATE role
+SET deleted_at = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteRoleParams struct {
+	ID        int32
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) SoftDeleteRole(ctx context.Context, arg SoftDeleteRoleParams) error {
+	_, err := q.db.Exec(ctx, softDeleteRole, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const softDeleteRoleMenuByMenuIDList = `-- name: SoftDeleteRoleMenuByMenuIDList :exec
+UPDATE role_menu
+SET deleted_at = $2
+WHERE menu_id = ANY($1::int[]) AND deleted_at IS NULL
+`
+
+type SoftDeleteRoleMenuByMenuIDListParams struct {
+	Column1   []int32
+	DeletedAt pgtype.Timestamp
 }
 
-const listChildID = `-- name: ListChildID :many
-SELECT id
-FROM menu
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE $1 || '%')
+func (q *Queries) SoftDeleteRoleMenuByMenuIDList(ctx context.Context, arg SoftDeleteRoleMenuByMenuIDListParams) error {
+	_, err := q.db.Exec(ctx, softDeleteRoleMenuByMenuIDList, arg.Column1, arg.DeletedAt)
+	return err
+}
+
+const softDeleteRoleMenuByRoleID = `-- name: SoftDeleteRoleMenuByRoleID :exec
+UPDATE role_menu
+SET deleted_at = $2
+WHERE role_id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) ListChildID(ctx context.Context, dollar_1 string) ([]int32, error) {
-	rows, err := q.db.Query(ctx, listChildID, dollar_1)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []int32
-	for rows.Next() {
-		var id int32
-		if err := rows.Scan(&id); err != nil {
-			return nil, err
-		}
-		items = append(items, id)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SoftDeleteRoleMenuByRoleIDParams struct {
+	RoleID    int32
+	DeletedAt pgtype.Timestamp
 }
 
-const listResourceByMenuIDList = `-- name: ListResourceByMenuIDList :many
-SELECT id, menu_id, method, path, created, updated
-FROM resource
-WHERE menu_id = ANY($1::int[])
+func (q *Queries) SoftDeleteRoleMenuByRoleID(ctx context.Context, arg SoftDeleteRoleMenuByRoleIDParams) error {
+	_, err := q.db.Exec(ctx, softDeleteRoleMenuByRoleID, arg.RoleID, arg.DeletedAt)
+	return err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :exec
+UPDATE app_user
+SET deleted_at = $2, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) ListResourceByMenuIDList(ctx context.Context, dollar_1 []int32) ([]Resource, error) {
-	rows, err := q.db.Query(ctx, listResourceByMenuIDList, dollar_1)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Resource
-	for rows.Next() {
-		var i Resource
-		if err := rows.Scan(
-			&i.ID,
-			&i.MenuID,
-			&i.Method,
-			&i.Path,
-			&i.Created,
-			&i.Updated,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SoftDeleteUserParams struct {
+	ID        int32
+	DeletedAt pgtype.Timestamp
 }
 
-const listRole = `-- name: ListRole :many
-SELECT id, code, name, description, sequence, status, created, updated
-FROM role
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE '%' || $1 || '%')
-AND ($2::VARCHAR = '' OR $2::VARCHAR = $2)
-AND id > $3
-ORDER BY sequence, created DESC
-LIMIT $4
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) error {
+	_, err := q.db.Exec(ctx, softDeleteUser, arg.ID, arg.DeletedAt)
+	return err
+}
+
+const softDeleteUserRoleByRoleID = `-- name: SoftDeleteUserRoleByRoleID :exec
+UPDATE user_role
+SET deleted_at = $2
+WHERE role_id = $1 AND deleted_at IS NULL
 `
 
-type ListRoleParams struct {
-	Column1 string
-	Column2 string
-	ID      int32
-	Limit   int32
+type SoftDeleteUserRoleByRoleIDParams struct {
+	RoleID    int32
+	DeletedAt pgtype.Timestamp
 }
 
-func (q *Queries) ListRole(ctx context.Context, arg ListRoleParams) ([]Role, error) {
-	rows, err := q.db.Query(ctx, listRole,
-		arg.Column1,
-		arg.Column2,
-		arg.ID,
-		arg.Limit,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Role
-	for rows.Next() {
-		var i Role
-		if err := rows.Scan(
-			&i.ID,
-			&i.Code,
-			&i.Name,
-			&i.Description,
-			&i.Sequence,
-			&i.Status,
-			&i.Created,
-			&i.Updated,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SoftDeleteUserRoleByRoleID(ctx context.Context, arg SoftDeleteUserRoleByRoleIDParams) error {
+	_, err := q.db.Exec(ctx, softDeleteUserRoleByRoleID, arg.RoleID, arg.DeletedAt)
+	return err
 }
 
-const listRoleMenuByRoleIDList = `-- name: ListRoleMenuByRoleIDList :many
-SELECT id, role_id, menu_id, created, updated
-FROM role_menu
-WHERE role_id = ANY($1::int[])
+const softDeleteUserRoleByUserID = `-- name: SoftDeleteUserRoleByUserID :exec
+UPDATE user_role
+SET deleted_at = $2
+WHERE user_id = $1 AND deleted_at IS NULL
 `
 
-func (q *Queries) ListRoleMenuByRoleIDList(ctx context.Context, dollar_1 []int32) ([]RoleMenu, error) {
-	rows, err := q.db.Query(ctx, listRoleMenuByRoleIDList, dollar_1)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []RoleMenu
-	for rows.Next() {
-		var i RoleMenu
-		if err := rows.Scan(
-			&i.ID,
-			&i.RoleID,
-			&i.MenuID,
-			&i.Created,
-			&i.Updated,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type SoftDeleteUserRoleByUserIDParams struct {
+	UserID    int32
+	DeletedAt pgtype.Timestamp
 }
 
-const listUser = `-- name: ListUser :many
-SELECT id, username, password, name, email, phone, remark, status, created, updated
-FROM app_user
-WHERE ($1::VARCHAR = '' OR $1::VARCHAR ILIKE '%' || $1 || '%')
-AND ($2::VARCHAR = '' OR $2::VARCHAR ILIKE '%' || $2 || '%')
-AND ($3::VARCHAR = '' OR $3::VARCHAR = $3)
-AND id > $4
-ORDER BY created DESC
-LIMIT $5
-`
-
-type ListUserParams struct {
-	Column1 string
-	Column2 string
-	Column3 string
-	ID      int32
-	Limit   int32
+func (q *Queries) SoftDeleteUserRoleByUserID(ctx context.Context, arg SoftDeleteUserRoleByUserIDParams) error {
+	_, err := q.db.Exec(ctx, softDeleteUserRoleByUserID, arg.UserID, arg.DeletedAt)
+	return err
 }
 
-func (q *Queries) ListUser(ctx context.Context, arg ListUserParams) ([]AppUser, error) {
-	rows, err := q.db.Query(ctx, listUser,
-		arg.Column1,
-		arg.Column2,
-		arg.Column3,
-		arg.ID,
-		arg.Limit,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []AppUser
-	for rows.Next() {
-		var i AppUser
-		if err := rows.Scan(
-			&i.ID,
-			&i.Username,
-			&i.Password,
-			&i.Name,
-			&i.Email,
-			&i.Phone,
-			&i.Remark,
-			&i.Status,
-			&i.Created,
-			&i.Updated,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+const touchSession = `-- name: TouchSession :exec
+UPDATE session
+SET last_seen = $2
+WHERE id = $1
+`
+
+type TouchSessionParams struct {
+	ID       int32
+	LastSeen pgtype.Timestamp
 }
 
-const listUserRoleByUserIDList = `-- name: ListUserRoleByUserIDList :many
-SELECT id, user_id, role_id, created, updated
-FROM user_role
-WHERE user_id = ANY($1::int[])
+func (q *Queries) TouchSession(ctx context.Context, arg TouchSessionParams) error {
+	_, err := q.db.Exec(ctx, touchSession, arg.ID, arg.LastSeen)
+	return err
+}
+
+const trimPasswordHistory = `-- name: TrimPasswordHistory :exec
+DELETE FROM password_history
+WHERE password_history.user_id = $1 AND id NOT IN (
+  SELECT kept.id FROM password_history AS kept
+  WHERE kept.user_id = $1 ORDER BY kept.id DESC LIMIT $2
+)
 `
 
-func (q *Queries) ListUserRoleByUserIDList(ctx context.Context, dollar_1 []int32) ([]UserRole, error) {
-	rows, err := q.db.Query(ctx, listUserRoleByUserIDList, dollar_1)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []UserRole
-	for rows.Next() {
-		var i UserRole
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.RoleID,
-			&i.Created,
-			&i.Updated,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type TrimPasswordHistoryParams struct {
+	UserID int32
+	Limit  int32
+}
+
+func (q *Queries) TrimPasswordHistory(ctx context.Context, arg TrimPasswordHistoryParams) error {
+	_, err := q.db.Exec(ctx, trimPasswordHistory, arg.UserID, arg.Limit)
+	return err
 }
 
 const updateMenu = `-- name: UpdateMenu :one
 UPDATE menu
 SET code = $2, name = $3, description = $4, sequence = $5, type = $6,
 path = $7, property = $8, parent_id = $9, parent_path = $10, status = $11,
-created = $12, updated = $13
-WHERE id = $1
-RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated
+created = $12, updated = $13, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, code, name, description, sequence, type, path, property, parent_id, parent_path, status, created, updated, deleted_at, version
 `
 
 type UpdateMenuParams struct {
@@ -859,7 +3318,7 @@ type UpdateMenuParams struct {
 	Type        string
 	Path        string
 	Property    string
-	ParentID    int32
+	ParentID    pgtype.Int4
 	ParentPath  string
 	Status      string
 	Created     pgtype.Timestamp
@@ -897,6 +3356,8 @@ func (q *Queries) UpdateMenu(ctx context.Context, arg UpdateMenuParams) (Menu, e
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -905,7 +3366,7 @@ const updateResource = `-- name: UpdateResource :one
 UPDATE resource
 SET menu_id = $2, method = $3, path = $4, created = $5, updated = $6
 WHERE id = $1
-RETURNING id, menu_id, method, path, created, updated
+RETURNING id, menu_id, method, path, created, updated, deleted_at
 `
 
 type UpdateResourceParams struct {
@@ -934,6 +3395,7 @@ func (q *Queries) UpdateResource(ctx context.Context, arg UpdateResourceParams)
 		&i.Path,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -941,9 +3403,9 @@ func (q *Queries) UpdateResource(ctx context.Context, arg UpdateResourceParams)
 const updateRole = `-- name: UpdateRole :one
 UPDATE role
 SET code = $2, name = $3, description = $4, sequence = $5, status = $6,
-created = $7, updated = $8
-WHERE id = $1
-RETURNING id, code, name, description, sequence, status, created, updated
+require_2fa = $7, created = $8, updated = $9, version = version + 1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, code, name, description, sequence, status, created, updated, deleted_at, version, require_2fa
 `
 
 type UpdateRoleParams struct {
@@ -953,6 +3415,7 @@ type UpdateRoleParams struct {
 	Description string
 	Sequence    int16
 	Status      string
+	Require2fa  bool
 	Created     pgtype.Timestamp
 	Updated     pgtype.Timestamp
 }
@@ -965,6 +3428,7 @@ func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, e
 		arg.Description,
 		arg.Sequence,
 		arg.Status,
+		arg.Require2fa,
 		arg.Created,
 		arg.Updated,
 	)
@@ -978,6 +3442,9 @@ func (q *Queries) UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, e
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.Require2fa,
 	)
 	return i, err
 }
@@ -986,7 +3453,7 @@ const updateRoleMenu = `-- name: UpdateRoleMenu :one
 UPDATE role_menu
 SET role_id = $2, menu_id = $3, created = $4, updated = $5
 WHERE id = $1
-RETURNING id, role_id, menu_id, created, updated
+RETURNING id, role_id, menu_id, created, updated, deleted_at
 `
 
 type UpdateRoleMenuParams struct {
@@ -1012,22 +3479,26 @@ func (q *Queries) UpdateRoleMenu(ctx context.Context, arg UpdateRoleMenuParams)
 		&i.MenuID,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE app_user
-SET username = $2, password = $3, name = $4, email = $5, phone = $6,
-remark = $7, status = $8, created = $9, updated = $10
-WHERE id = $1
-RETURNING id, username, password, name, email, phone, remark, status, created, updated
+SET username = $1,
+password = COALESCE($2, password),
+name = $3, email = $4, phone = $5,
+remark = $6, status = $7,
+created = $8, updated = $9,
+version = version + 1
+WHERE id = $10 AND deleted_at IS NULL
+RETURNING id, username, password, name, email, phone, remark, status, created, updated, deleted_at, version, must_change_password, totp_secret, totp_enabled, is_root, totp_last_step
 `
 
 type UpdateUserParams struct {
-	ID       int32
 	Username string
-	Password string
+	Password pgtype.Text
 	Name     string
 	Email    string
 	Phone    string
@@ -1035,11 +3506,11 @@ type UpdateUserParams struct {
 	Status   string
 	Created  pgtype.Timestamp
 	Updated  pgtype.Timestamp
+	ID       int32
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (AppUser, error) {
 	row := q.db.QueryRow(ctx, updateUser,
-		arg.ID,
 		arg.Username,
 		arg.Password,
 		arg.Name,
@@ -1049,6 +3520,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (AppUser
 		arg.Status,
 		arg.Created,
 		arg.Updated,
+		arg.ID,
 	)
 	var i AppUser
 	err := row.Scan(
@@ -1062,6 +3534,13 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (AppUser
 		&i.Status,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+		&i.Version,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabled,
+		&i.IsRoot,
+		&i.TotpLastStep,
 	)
 	return i, err
 }
@@ -1070,7 +3549,7 @@ const updateUserRole = `-- name: UpdateUserRole :one
 UPDATE user_role
 SET user_id = $2, role_id = $3, created = $4, updated = $5
 WHERE id = $1
-RETURNING id, user_id, role_id, created, updated
+RETURNING id, user_id, role_id, created, updated, deleted_at
 `
 
 type UpdateUserRoleParams struct {
@@ -1096,6 +3575,108 @@ func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams)
 		&i.RoleID,
 		&i.Created,
 		&i.Updated,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const upsertApiResource = `-- name: UpsertApiResource :one
+INSERT INTO api_resource (method, path, operation_id, tag, description, created, updated)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+ON CONFLICT (method, path) DO UPDATE
+SET operation_id = EXCLUDED.operation_id,
+tag = EXCLUDED.tag,
+description = EXCLUDED.description,
+updated = EXCLUDED.updated
+RETURNING id, method, path, operation_id, tag, description, created, updated
+`
+
+type UpsertApiResourceParams struct {
+	Method      string
+	Path        string
+	OperationID string
+	Tag         string
+	Description string
+	Created     pgtype.Timestamp
+}
+
+func (q *Queries) UpsertApiResource(ctx context.Context, arg UpsertApiResourceParams) (ApiResource, error) {
+	row := q.db.QueryRow(ctx, upsertApiResource,
+		arg.Method,
+		arg.Path,
+		arg.OperationID,
+		arg.Tag,
+		arg.Description,
+		arg.Created,
+	)
+	var i ApiResource
+	err := row.Scan(
+		&i.ID,
+		&i.Method,
+		&i.Path,
+		&i.OperationID,
+		&i.Tag,
+		&i.Description,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const useLoginChallenge = `-- name: UseLoginChallenge :one
+DELETE FROM login_challenge
+WHERE token = $1
+RETURNING id, user_id, token, expired, created
+`
+
+func (q *Queries) UseLoginChallenge(ctx context.Context, token string) (LoginChallenge, error) {
+	row := q.db.QueryRow(ctx, useLoginChallenge, token)
+	var i LoginChallenge
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Token,
+		&i.Expired,
+		&i.Created,
 	)
 	return i, err
 }
+
+const useRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE recovery_code
+SET used_at = $3
+WHERE user_id = $1 AND code = $2 AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UserID int32
+	Code   string
+	UsedAt pgtype.Timestamp
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useRecoveryCode, arg.UserID, arg.Code, arg.UsedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const useUserTOTPStep = `-- name: UseUserTOTPStep :execrows
+UPDATE app_user
+SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2
+`
+
+type UseUserTOTPStepParams struct {
+	ID           int32
+	TotpLastStep int64
+}
+
+func (q *Queries) UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, useUserTOTPStep, arg.ID, arg.TotpLastStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
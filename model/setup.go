@@ -5,12 +5,26 @@ import (
 	"log"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Setup(ctx context.Context, DSN string) *pgx.Conn {
-	conn, err := pgx.Connect(ctx, DSN)
+// DB is implemented by *pgxpool.Pool, it runs queries and begins
+// transactions on connections taken from the pool.
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Setup opens a connection pool, pool settings such as pool_max_conns
+// and pool_health_check_period are read from the DSN.
+func Setup(ctx context.Context, DSN string) *pgxpool.Pool {
+	pool, err := pgxpool.New(ctx, DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = pool.Ping(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
-	return conn
+	return pool
 }
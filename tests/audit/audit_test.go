@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	userJSON = `{
+"username": "username1",
+"password": "Secret-1-key",
+"name": "name1",
+"email": "example1@gmail.com",
+"phone": "+14155552671",
+"remark": "remark1",
+"status": "activated",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"role": []
+}`
+)
+
+func TestGetApiV1AuditLogs(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(userJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "create-user")
+	controller.RequestID(http.HandlerFunc(api.PostApiV1Users)).ServeHTTP(httptest.NewRecorder(), req)
+
+	// without a password the current one is kept
+	reqJSON := strings.Replace(userJSON, `"name1"`, `"name2"`, 1)
+	reqJSON = strings.Replace(reqJSON, `"password": "Secret-1-key",`, "", 1)
+	req = httptest.NewRequest(http.MethodPut, tests.BaseURL+"api/v1/users/1", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	req.Header.Set("X-Request-ID", "update-user")
+	controller.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.PutApiV1UsersId(w, r, 1)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/audit-logs", nil)
+	params := controller.GetApiV1AuditLogsParams{
+		Entity:   "user",
+		EntityId: 1,
+		Current:  0,
+		PageSize: 10,
+	}
+	r := httptest.NewRecorder()
+	api.GetApiV1AuditLogs(r, req, params)
+
+	var actual []controller.AuditLog
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, actual, 2)
+
+	// newest first
+	assert.Equal(t, controller.Update, actual[0].Action)
+	assert.Equal(t, "update-user", actual[0].RequestId)
+	assert.Equal(t, "192.0.2.1", actual[0].Ip)
+	assert.Equal(t, map[string]interface{}{"name": "name1"}, actual[0].Before)
+	assert.Equal(t, map[string]interface{}{"name": "name2"}, actual[0].After)
+
+	assert.Equal(t, controller.Create, actual[1].Action)
+	assert.Equal(t, "create-user", actual[1].RequestId)
+	assert.Nil(t, actual[1].Before)
+	assert.Equal(t, "username1", actual[1].After["username"])
+	assert.NotContains(t, actual[1].After, "password")
+}
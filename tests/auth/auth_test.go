@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	userJSON = `{
+"username": "username1",
+"password": "Secret-1-key",
+"name": "name1",
+"email": "example1@gmail.com",
+"phone": "+14155552671",
+"remark": "remark1",
+"status": "activated",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"role": []
+}`
+
+	loginJSON = `{
+"username": "username1",
+"password": "Secret-1-key"
+}`
+
+	wrongPasswordJSON = `{
+"username": "username1",
+"password": "Secret-2-key"
+}`
+)
+
+func setup(t *testing.T) *controller.API {
+	_ = config.Raw.Set("JWT_SECRET", "secret")
+	_ = config.Raw.Set("ACCESS_TOKEN_EXPIRE", 900)
+	_ = config.Raw.Set("REFRESH_TOKEN_EXPIRE", 3600)
+
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+	createUser(api, userJSON)
+	return api
+}
+
+func createUser(api *controller.API, reqJSON string) {
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1Users(r, req)
+}
+
+func login(api *controller.API, reqJSON string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/auth/login", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1AuthLogin(r, req)
+	return r
+}
+
+func refresh(api *controller.API, refreshToken string) *httptest.ResponseRecorder {
+	reqJSON := fmt.Sprintf(`{"refresh_token": %q}`, refreshToken)
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/auth/refresh", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1AuthRefresh(r, req)
+	return r
+}
+
+func TestPostApiV1AuthLogin(t *testing.T) {
+	api := setup(t)
+
+	r := login(api, loginJSON)
+	var actual controller.Token
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.NotEmpty(t, actual.AccessToken)
+	assert.NotEmpty(t, actual.RefreshToken)
+	assert.Equal(t, "Bearer", actual.TokenType)
+	assert.Equal(t, int32(900), actual.ExpiresIn)
+}
+
+func TestPostApiV1AuthLoginWrongPassword(t *testing.T) {
+	api := setup(t)
+
+	r := login(api, wrongPasswordJSON)
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, errcode.UsernameOrPasswordIncorrect, actual.Code)
+}
+
+func TestPostApiV1AuthLoginLockout(t *testing.T) {
+	api := setup(t)
+	_ = config.Raw.Set("LOGIN_MAX_FAILURE", 3)
+	defer config.Raw.Delete("LOGIN_MAX_FAILURE")
+
+	for i := 0; i < 3; i++ {
+		r := login(api, wrongPasswordJSON)
+		assert.Equal(t, http.StatusUnauthorized, r.Code)
+	}
+
+	// even the right password is refused until the lockout ends
+	r := login(api, loginJSON)
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusTooManyRequests, r.Code)
+	assert.Equal(t, errcode.LoginLocked, actual.Code)
+	assert.Equal(t, "60", r.Header().Get("Retry-After"))
+
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users/1/unlock", nil)
+	r = httptest.NewRecorder()
+	api.PostApiV1UsersIdUnlock(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	r = login(api, loginJSON)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/login-logs", nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1LoginLogs(r, req, controller.GetApiV1LoginLogsParams{UserId: 1, PageSize: 10})
+	var loginLogList []controller.LoginLog
+	_ = json.NewDecoder(r.Body).Decode(&loginLogList)
+	assert.Len(t, loginLogList, 5)
+	assert.Equal(t, controller.LoginSuccess, loginLogList[0].Result)
+	assert.Equal(t, "user_locked", loginLogList[1].Reason)
+	assert.Equal(t, "password_incorrect", loginLogList[2].Reason)
+}
+
+func TestPostApiV1AuthRefresh(t *testing.T) {
+	api := setup(t)
+
+	var token controller.Token
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&token)
+
+	var rotated controller.Token
+	_ = json.NewDecoder(refresh(api, token.RefreshToken).Body).Decode(&rotated)
+	assert.NotEmpty(t, rotated.AccessToken)
+	assert.NotEqual(t, token.RefreshToken, rotated.RefreshToken)
+
+	// replaying the rotated token revokes the whole chain
+	var replay controller.Error
+	_ = json.NewDecoder(refresh(api, token.RefreshToken).Body).Decode(&replay)
+	assert.Equal(t, errcode.RefreshTokenInvalid, replay.Code)
+
+	var revoked controller.Error
+	_ = json.NewDecoder(refresh(api, rotated.RefreshToken).Body).Decode(&revoked)
+	assert.Equal(t, errcode.RefreshTokenInvalid, revoked.Code)
+}
+
+func TestPostApiV1AuthLogout(t *testing.T) {
+	api := setup(t)
+
+	var token controller.Token
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&token)
+
+	reqJSON := fmt.Sprintf(`{"refresh_token": %q}`, token.RefreshToken)
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/auth/logout", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1AuthLogout(r, req)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	var actual controller.Error
+	_ = json.NewDecoder(refresh(api, token.RefreshToken).Body).Decode(&actual)
+	assert.Equal(t, errcode.RefreshTokenInvalid, actual.Code)
+}
+
+func TestSessions(t *testing.T) {
+	api := setup(t)
+	mux := http.NewServeMux()
+	controller.HandlerWithOptions(api, controller.StdHTTPServerOptions{BaseRouter: mux})
+	handler := api.Authorize(mux)
+
+	var first, second controller.Token
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&first)
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&second)
+
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+first.AccessToken)
+	params := controller.GetApiV1SessionsParams{
+		UserId:   1,
+		Current:  0,
+		PageSize: 10,
+	}
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+
+	r = httptest.NewRecorder()
+	api.GetApiV1Sessions(r, req, params)
+	var sessionList []controller.Session
+	_ = json.NewDecoder(r.Body).Decode(&sessionList)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, sessionList, 2)
+	assert.Equal(t, "192.0.2.1", sessionList[0].Ip)
+
+	// last seen first, so the second login leads
+	req = httptest.NewRequest(http.MethodDelete, tests.BaseURL, nil)
+	r = httptest.NewRecorder()
+	api.DeleteApiV1SessionsId(r, req, sessionList[0].Id)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	r = httptest.NewRecorder()
+	api.DeleteApiV1SessionsId(r, req, sessionList[0].Id)
+	assert.Equal(t, http.StatusNotFound, r.Code)
+
+	var actual controller.Error
+	_ = json.NewDecoder(refresh(api, second.RefreshToken).Body).Decode(&actual)
+	assert.Equal(t, errcode.RefreshTokenInvalid, actual.Code)
+	_ = json.NewDecoder(refresh(api, first.RefreshToken).Body).Decode(&first)
+	assert.NotEmpty(t, first.AccessToken)
+
+	menus := func(accessToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/me/menus", nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		r := httptest.NewRecorder()
+		handler.ServeHTTP(r, req)
+		return r
+	}
+	assert.Equal(t, http.StatusOK, menus(first.AccessToken).Code)
+
+	// the access token is refused before it expires
+	r = httptest.NewRecorder()
+	api.DeleteApiV1UsersIdSessions(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, http.StatusUnauthorized, menus(first.AccessToken).Code)
+
+	// so is that of a user being frozen
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&first)
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"status": "frozen"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	_ = json.NewDecoder(refresh(api, first.RefreshToken).Body).Decode(&actual)
+	assert.Equal(t, errcode.RefreshTokenInvalid, actual.Code)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	api := setup(t)
+
+	var token controller.Token
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&token)
+	assert.Equal(t, http.StatusOK, refresh(api, token.RefreshToken).Code)
+
+	rows, err := api.PurgeExpired(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	// the rotated token, the one it was rotated into and their session
+	rows, err = api.PurgeExpired(context.Background(), time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rows)
+}
+
+func TestPostApiV1IpsIpUnlock(t *testing.T) {
+	api := setup(t)
+	_ = config.Raw.Set("LOGIN_MAX_IP_FAILURE", 3)
+	defer config.Raw.Delete("LOGIN_MAX_IP_FAILURE")
+
+	for i := 0; i < 3; i++ {
+		r := login(api, wrongPasswordJSON)
+		assert.Equal(t, http.StatusUnauthorized, r.Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, login(api, loginJSON).Code)
+
+	// unlocking a user leaves the address it failed from alone
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users/1/unlock", nil)
+	r := httptest.NewRecorder()
+	api.PostApiV1UsersIdUnlock(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, http.StatusTooManyRequests, login(api, loginJSON).Code)
+
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/ips/not-an-ip/unlock", nil)
+	r = httptest.NewRecorder()
+	api.PostApiV1IpsIpUnlock(r, req, "not-an-ip")
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	// httptest requests come from 192.0.2.1
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/ips/192.0.2.1/unlock", nil)
+	r = httptest.NewRecorder()
+	api.PostApiV1IpsIpUnlock(r, req, "192.0.2.1")
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, http.StatusOK, login(api, loginJSON).Code)
+}
+
+func TestSessionExpired(t *testing.T) {
+	api := setup(t)
+	mux := http.NewServeMux()
+	controller.HandlerWithOptions(api, controller.StdHTTPServerOptions{BaseRouter: mux})
+	handler := api.Authorize(mux)
+
+	var token controller.Token
+	_ = json.NewDecoder(login(api, loginJSON).Body).Decode(&token)
+
+	menus := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/me/menus", nil)
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		r := httptest.NewRecorder()
+		handler.ServeHTTP(r, req)
+		return r
+	}
+	assert.Equal(t, http.StatusOK, menus().Code)
+
+	// the access token is still good, its session is not
+	_, err := api.DB.Exec(context.Background(), `UPDATE session SET expired = now() - interval '1 second'`)
+	assert.NoError(t, err)
+
+	r := menus()
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusUnauthorized, r.Code)
+	assert.Equal(t, errcode.TokenInvalid, actual.Code)
+}
@@ -0,0 +1,434 @@
+package authorize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linehk/go-admin/cache"
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	menuJSON = `{
+"code": "user",
+"name": "user",
+"description": "user management",
+"sequence": 1,
+"type": "page",
+"path": "/user",
+"property": "",
+"parent_id": 0,
+"parent_path": "",
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"resource": [
+    {
+        "menu_id": 0,
+        "method": "GET",
+        "path": "/api/v1/users/{id}",
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+	roleJSON = `{
+"code": "viewer",
+"name": "viewer",
+"description": "viewer",
+"sequence": 1,
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"menu": [
+    {
+        "menu_id": 1,
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+	viewerJSON = `{
+"username": "viewer",
+"password": "Secret-1-key",
+"name": "viewer",
+"email": "example1@gmail.com",
+"phone": "+14155552671",
+"remark": "",
+"status": "activated",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"role": [
+    {
+        "role_id": 1,
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+	guestJSON = `{
+"username": "guest",
+"password": "Secret-2-key",
+"name": "guest",
+"email": "example2@gmail.com",
+"phone": "+442071838750",
+"remark": "",
+"status": "activated",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"role": []
+}`
+)
+
+func setup(t *testing.T) (*controller.API, http.Handler) {
+	_ = config.Raw.Set("JWT_SECRET", "secret")
+	_ = config.Raw.Set("ACCESS_TOKEN_EXPIRE", 900)
+	_ = config.Raw.Set("REFRESH_TOKEN_EXPIRE", 3600)
+
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db, Cache: cache.NewMemory()}
+
+	post := func(handler http.HandlerFunc, reqJSON string) {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		handler(httptest.NewRecorder(), req)
+	}
+	post(api.PostApiV1Menus, menuJSON)
+	post(api.PostApiV1Roles, roleJSON)
+	post(api.PostApiV1Users, viewerJSON)
+	post(api.PostApiV1Users, guestJSON)
+
+	mux := http.NewServeMux()
+	controller.HandlerWithOptions(api, controller.StdHTTPServerOptions{BaseRouter: mux})
+	return api, api.Authorize(mux)
+}
+
+func login(t *testing.T, handler http.Handler, username, password string) string {
+	reqJSON := `{"username": "` + username + `", "password": "` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/auth/login", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+
+	var token controller.Token
+	err := json.NewDecoder(r.Body).Decode(&token)
+	assert.NoError(t, err)
+	return token.AccessToken
+}
+
+func get(handler http.Handler, path, accessToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+path, nil)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+	return r
+}
+
+func TestAuthorizeWithoutToken(t *testing.T) {
+	_, handler := setup(t)
+
+	r := get(handler, "api/v1/users/1", "")
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusUnauthorized, r.Code)
+	assert.Equal(t, errcode.TokenInvalid, actual.Code)
+}
+
+func TestAuthorizeGranted(t *testing.T) {
+	_, handler := setup(t)
+	accessToken := login(t, handler, "viewer", "Secret-1-key")
+
+	r := get(handler, "api/v1/users/2", accessToken)
+
+	assert.Equal(t, http.StatusOK, r.Code)
+}
+
+func TestAuthorizeDenied(t *testing.T) {
+	_, handler := setup(t)
+
+	// resource does not match the method and path
+	viewerToken := login(t, handler, "viewer", "Secret-1-key")
+	r := get(handler, "api/v1/roles/1", viewerToken)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+
+	// user without role
+	guestToken := login(t, handler, "guest", "Secret-2-key")
+	r = get(handler, "api/v1/users/2", guestToken)
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusForbidden, r.Code)
+	assert.Equal(t, errcode.PermissionDenied, actual.Code)
+}
+
+func TestAuthorizeCacheInvalidated(t *testing.T) {
+	api, handler := setup(t)
+	accessToken := login(t, handler, "viewer", "Secret-1-key")
+
+	// the second request is answered from the cache
+	assert.Equal(t, http.StatusOK, get(handler, "api/v1/users/2", accessToken).Code)
+	assert.Equal(t, http.StatusOK, get(handler, "api/v1/users/2", accessToken).Code)
+
+	req := httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"status": "disabled"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.PatchApiV1MenusId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	assert.Equal(t, http.StatusForbidden, get(handler, "api/v1/users/2", accessToken).Code)
+
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"status": "enabled"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+	api.PatchApiV1MenusId(httptest.NewRecorder(), req, 1)
+	assert.Equal(t, http.StatusOK, get(handler, "api/v1/users/2", accessToken).Code)
+
+	// dropping the role of the user reaches only its own entry
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"role": []}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	assert.Equal(t, http.StatusForbidden, get(handler, "api/v1/users/2", accessToken).Code)
+}
+
+func TestAuthorizeInherited(t *testing.T) {
+	api, handler := setup(t)
+
+	heirRoleJSON := `{
+"code": "heir",
+"name": "heir",
+"description": "heir",
+"sequence": 1,
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"menu": []
+}`
+	heirJSON := strings.NewReplacer(
+		`"viewer"`, `"heir"`,
+		"example1@gmail.com", "example3@gmail.com",
+		"+14155552671", "+4915112345678",
+		`"role_id": 1`, `"role_id": 2`,
+	).Replace(viewerJSON)
+	for _, test := range []struct {
+		handler http.HandlerFunc
+		reqJSON string
+	}{
+		{api.PostApiV1Roles, heirRoleJSON},
+		{api.PostApiV1Users, heirJSON},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(test.reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		r := httptest.NewRecorder()
+		test.handler(r, req)
+		assert.Equal(t, http.StatusOK, r.Code)
+	}
+
+	putParents := func(id int32, reqJSON, version string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, tests.BaseURL, strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", version)
+		r := httptest.NewRecorder()
+		api.PutApiV1RolesIdParents(r, req, id)
+		return r
+	}
+
+	accessToken := login(t, handler, "heir", "Secret-1-key")
+	assert.Equal(t, http.StatusForbidden, get(handler, "api/v1/users/2", accessToken).Code)
+
+	r := putParents(2, `{"parent_id": [1]}`, `"1"`)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, `"2"`, r.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, get(handler, "api/v1/users/2", accessToken).Code)
+
+	// the parent would inherit from its own child
+	r = putParents(1, `{"parent_id": [2]}`, `"1"`)
+	var actualErr controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actualErr)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.RoleCycle, actualErr.Code)
+
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL, nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1RolesIdPermissions(r, req, 2)
+	var actual controller.RolePermission
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, []int32{1}, actual.AncestorId)
+	assert.Len(t, actual.Menu, 1)
+	assert.Len(t, actual.Menu[0].Resource, 1)
+
+	// an ancestor requiring two factor authentication requires it of the heir
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"require_2fa": true}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1RolesId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+	r = get(handler, "api/v1/users/2", accessToken)
+	_ = json.NewDecoder(r.Body).Decode(&actualErr)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+	assert.Equal(t, errcode.TwoFactorRequired, actualErr.Code)
+
+	// a disabled ancestor grants nothing
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"status": "disabled", "require_2fa": false}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1RolesId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+	r = get(handler, "api/v1/users/2", accessToken)
+	_ = json.NewDecoder(r.Body).Decode(&actualErr)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+	assert.Equal(t, errcode.PermissionDenied, actualErr.Code)
+}
+
+func TestAuthorizeBatchOperation(t *testing.T) {
+	api, handler := setup(t)
+
+	batcherMenuJSON := strings.NewReplacer(
+		`"code": "user"`, `"code": "batch"`,
+		`"method": "GET"`, `"method": "POST"`,
+		"/api/v1/users/{id}", "/api/v1/users:batch",
+	).Replace(menuJSON)
+	batcherRoleJSON := strings.NewReplacer(
+		`"viewer"`, `"batcher"`,
+		`"menu_id": 1`, `"menu_id": 2`,
+	).Replace(roleJSON)
+	batcherJSON := strings.NewReplacer(
+		`"viewer"`, `"batcher"`,
+		"example1@gmail.com", "example3@gmail.com",
+		"+14155552671", "+4915112345678",
+		`"role_id": 1`, `"role_id": 2`,
+	).Replace(viewerJSON)
+	for _, test := range []struct {
+		handler http.HandlerFunc
+		reqJSON string
+	}{
+		{api.PostApiV1Menus, batcherMenuJSON},
+		{api.PostApiV1Roles, batcherRoleJSON},
+		{api.PostApiV1Users, batcherJSON},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(test.reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		r := httptest.NewRecorder()
+		test.handler(r, req)
+		assert.Equal(t, http.StatusOK, r.Code)
+	}
+
+	// the batch route is granted, deleting a user is not
+	accessToken := login(t, handler, "batcher", "Secret-1-key")
+	reqJSON := `{"mode": "best_effort", "operations": [{"op": "delete", "id": 2, "if_match": "\"1\""}]}`
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users:batch", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+
+	var actual controller.BatchResponse
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, errcode.PermissionDenied, actual.Results[0].Code)
+	assert.Equal(t, int32(http.StatusForbidden), actual.Results[0].Status)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL, nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1UsersId(r, req, 2)
+	assert.Equal(t, http.StatusOK, r.Code)
+}
+
+func TestAuthorizeDisabledAncestor(t *testing.T) {
+	api, handler := setup(t)
+	accessToken := login(t, handler, "viewer", "Secret-1-key")
+
+	parentJSON := `{
+"code": "system",
+"name": "system",
+"description": "system management",
+"sequence": 1,
+"type": "page",
+"path": "/system",
+"property": "",
+"parent_id": 0,
+"parent_path": "",
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"resource": []
+}`
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(parentJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1Menus(r, req)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(`{"parent_id": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PostApiV1MenusIdMove(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, http.StatusOK, get(handler, "api/v1/users/2", accessToken).Code)
+
+	// the menu granting the resource is enabled, its parent is not
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"status": "disabled"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1MenusId(r, req, 2)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, http.StatusForbidden, get(handler, "api/v1/users/2", accessToken).Code)
+}
+
+func TestAuthorizePasswordChangeAndTwoFactorRequired(t *testing.T) {
+	api, handler := setup(t)
+	_, err := api.DB.Exec(context.Background(), `UPDATE role SET require_2fa = true WHERE id = 1`)
+	assert.NoError(t, err)
+	_, err = api.DB.Exec(context.Background(), `UPDATE app_user SET must_change_password = true WHERE id = 1`)
+	assert.NoError(t, err)
+	accessToken := login(t, handler, "viewer", "Secret-1-key")
+
+	post := func(path, reqJSON string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+path, strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		r := httptest.NewRecorder()
+		handler.ServeHTTP(r, req)
+		return r
+	}
+
+	// the password goes first
+	r := post("api/v1/me/2fa/enroll", "")
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, errcode.PasswordChangeRequired, actual.Code)
+
+	r = post("api/v1/me/password", `{"old_password": "Secret-1-key", "new_password": "Changed-2-key"}`)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	// then 2fa, which is all that is left open
+	r = get(handler, "api/v1/users/2", accessToken)
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, errcode.TwoFactorRequired, actual.Code)
+
+	r = post("api/v1/me/2fa/enroll", "")
+	assert.Equal(t, http.StatusOK, r.Code)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linehk/go-admin/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemory()
+
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), time.Minute)
+	_ = c.Set(ctx, "c", []byte("3"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	value, ok, err := c.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	// expired
+	_, ok, _ = c.Get(ctx, "c")
+	assert.False(t, ok)
+
+	_ = c.Delete(ctx, "a")
+	_, ok, _ = c.Get(ctx, "a")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "b")
+	assert.True(t, ok)
+
+	_ = c.Clear(ctx)
+	_, ok, _ = c.Get(ctx, "b")
+	assert.False(t, ok)
+}
@@ -0,0 +1,340 @@
+package me
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linehk/go-admin/config"
+	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pageJSON = `{
+"code": "user",
+"name": "user",
+"description": "user management",
+"sequence": 1,
+"type": "page",
+"path": "/user",
+"property": "",
+"parent_id": 0,
+"parent_path": "",
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"resource": []
+}`
+
+	buttonJSON = `{
+"code": "delete",
+"name": "delete",
+"description": "delete user",
+"sequence": 1,
+"type": "button",
+"path": "",
+"property": "",
+"parent_id": 1,
+"parent_path": "",
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"resource": [
+    {
+        "menu_id": 0,
+        "method": "DELETE",
+        "path": "/api/v1/users/{id}",
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+	roleJSON = `{
+"code": "operator",
+"name": "operator",
+"description": "operator",
+"sequence": 1,
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"menu": [
+    {
+        "menu_id": 1,
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    },
+    {
+        "menu_id": 2,
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+	userJSON = `{
+"username": "operator",
+"password": "Secret-1-key",
+"name": "operator",
+"email": "example1@gmail.com",
+"phone": "+14155552671",
+"remark": "",
+"status": "activated",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"role": [
+    {
+        "role_id": 1,
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+)
+
+// setup creates the operator user with its role and menus and returns the
+// routes behind Authorize.
+func setup(t *testing.T) (*controller.API, http.Handler) {
+	_ = config.Raw.Set("JWT_SECRET", "secret")
+	_ = config.Raw.Set("ACCESS_TOKEN_EXPIRE", 900)
+	_ = config.Raw.Set("REFRESH_TOKEN_EXPIRE", 3600)
+
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	post := func(handler http.HandlerFunc, reqJSON string) {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		handler(httptest.NewRecorder(), req)
+	}
+	post(api.PostApiV1Menus, pageJSON)
+	post(api.PostApiV1Menus, buttonJSON)
+	post(api.PostApiV1Roles, roleJSON)
+	post(api.PostApiV1Users, userJSON)
+
+	mux := http.NewServeMux()
+	controller.HandlerWithOptions(api, controller.StdHTTPServerOptions{BaseRouter: mux})
+	return api, api.Authorize(mux)
+}
+
+// serve runs the request through handler, with accessToken when it is set.
+func serve(handler http.Handler, method, path, accessToken, reqJSON string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, tests.BaseURL+path, strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+	return r
+}
+
+func login(handler http.Handler, password string) controller.Token {
+	r := serve(handler, http.MethodPost, "api/v1/auth/login", "", `{"username": "operator", "password": "`+password+`"}`)
+	var token controller.Token
+	_ = json.NewDecoder(r.Body).Decode(&token)
+	return token
+}
+
+func TestGetApiV1MeMenus(t *testing.T) {
+	_, handler := setup(t)
+
+	loginJSON := `{"username": "operator", "password": "Secret-1-key"}`
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/auth/login", strings.NewReader(loginJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+	var token controller.Token
+	_ = json.NewDecoder(r.Body).Decode(&token)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/me/menus", nil)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	r = httptest.NewRecorder()
+	handler.ServeHTTP(r, req)
+
+	var actual []controller.Menu
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, "user", actual[0].Code)
+	assert.Len(t, actual[0].Children, 1)
+	assert.Equal(t, "delete", actual[0].Children[0].Code)
+	assert.Equal(t, controller.Button, actual[0].Children[0].Type)
+	assert.Len(t, actual[0].Children[0].Resource, 1)
+	assert.Equal(t, "/api/v1/users/{id}", actual[0].Children[0].Resource[0].Path)
+}
+
+func TestPostApiV1MePassword(t *testing.T) {
+	api, handler := setup(t)
+
+	// an admin reset has to go through the policy and the history
+	resetJSON := `{"password": "%s"}`
+	for password, tag := range map[string]string{
+		"Short-1":      "password_length",
+		"lowercase-ok": "password_class",
+		"P@ssw0rd":     "password_banned",
+		"Secret-1-key": "password_history",
+	} {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(fmt.Sprintf(resetJSON, password)))
+		req.Header.Set("Content-Type", "application/json")
+		r := httptest.NewRecorder()
+		api.PostApiV1UsersIdPasswordReset(r, req, 1)
+		var actual controller.Error
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, http.StatusBadRequest, r.Code, password)
+		assert.Equal(t, tag, actual.Details[0].Tag, password)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL, strings.NewReader(fmt.Sprintf(resetJSON, "Temporary-2")))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1UsersIdPasswordReset(r, req, 1)
+	var user controller.User
+	_ = json.NewDecoder(r.Body).Decode(&user)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.True(t, user.MustChangePassword)
+
+	// only the password change is open until it is done
+	token := login(handler, "Temporary-2")
+	assert.True(t, token.MustChangePassword)
+	r = serve(handler, http.MethodGet, "api/v1/me/menus", token.AccessToken, "")
+	assert.Equal(t, http.StatusForbidden, r.Code)
+
+	r = serve(handler, http.MethodPost, "api/v1/me/password", token.AccessToken, `{"old_password": "wrong", "new_password": "Changed-3-key"}`)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	r = serve(handler, http.MethodPost, "api/v1/me/password", token.AccessToken, `{"old_password": "Temporary-2", "new_password": "Secret-1-key"}`)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	r = serve(handler, http.MethodPost, "api/v1/me/password", token.AccessToken, `{"old_password": "Temporary-2", "new_password": "Changed-3-key"}`)
+	assert.Equal(t, http.StatusOK, r.Code)
+	_ = json.NewDecoder(r.Body).Decode(&token)
+	assert.False(t, token.MustChangePassword)
+
+	r = serve(handler, http.MethodGet, "api/v1/me/menus", token.AccessToken, "")
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	// the old password no longer logs in
+	assert.Empty(t, login(handler, "Temporary-2").AccessToken)
+	assert.NotEmpty(t, login(handler, "Changed-3-key").AccessToken)
+}
+
+// totp computes the current code of secret as an authenticator app does.
+func totp(secret string, now time.Time) string {
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(now.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+func TestPostApiV1Me2fa(t *testing.T) {
+	api, handler := setup(t)
+	token := login(handler, "Secret-1-key")
+
+	r := serve(handler, http.MethodPost, "api/v1/me/2fa/enroll", token.AccessToken, "")
+	var enrollment controller.TOTPEnrollment
+	_ = json.NewDecoder(r.Body).Decode(&enrollment)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Contains(t, enrollment.OtpauthUri, "otpauth://totp/go-admin:operator?")
+	assert.Contains(t, enrollment.OtpauthUri, "secret="+enrollment.Secret)
+
+	codeJSON := `{"code": "%s"}`
+	r = serve(handler, http.MethodPost, "api/v1/me/2fa/verify", token.AccessToken, fmt.Sprintf(codeJSON, totp(enrollment.Secret, time.Now())))
+	var recoveryCodes controller.RecoveryCodes
+	_ = json.NewDecoder(r.Body).Decode(&recoveryCodes)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, recoveryCodes.RecoveryCodes, 10)
+
+	// the password alone only gets a challenge
+	token = login(handler, "Secret-1-key")
+	assert.Empty(t, token.AccessToken)
+	assert.NotEmpty(t, token.Challenge)
+
+	twoFactorJSON := `{"challenge": "%s", "code": "%s"}`
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, token.Challenge, recoveryCodes.RecoveryCodes[0]))
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	// a challenge and a recovery code are good for one login each
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, token.Challenge, recoveryCodes.RecoveryCodes[1]))
+	assert.Equal(t, http.StatusUnauthorized, r.Code)
+
+	token = login(handler, "Secret-1-key")
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, token.Challenge, recoveryCodes.RecoveryCodes[0]))
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	// the code of this period was used by verify, the next one is taken too
+	code := totp(enrollment.Secret, time.Now().Add(30*time.Second))
+	token = login(handler, "Secret-1-key")
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, token.Challenge, totp(enrollment.Secret, time.Now())))
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	token = login(handler, "Secret-1-key")
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, token.Challenge, code))
+	_ = json.NewDecoder(r.Body).Decode(&token)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.NotEmpty(t, token.AccessToken)
+
+	// a code is good for one login
+	challenge := login(handler, "Secret-1-key").Challenge
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, challenge, code))
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+
+	// members of a role requiring 2fa cannot turn it off
+	req := httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"require_2fa": true}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1RolesId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	disableJSON := `{"password": "Secret-1-key", "code": "%s"}`
+	r = serve(handler, http.MethodPost, "api/v1/me/2fa/disable", token.AccessToken, fmt.Sprintf(disableJSON, recoveryCodes.RecoveryCodes[2]))
+	assert.Equal(t, http.StatusForbidden, r.Code)
+
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"require_2fa": false}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1RolesId(r, req, 1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	challenge = login(handler, "Secret-1-key").Challenge
+	r = serve(handler, http.MethodPost, "api/v1/me/2fa/disable", token.AccessToken, fmt.Sprintf(disableJSON, recoveryCodes.RecoveryCodes[2]))
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.NotEmpty(t, login(handler, "Secret-1-key").AccessToken)
+
+	// a challenge issued before cannot be answered against the cleared secret
+	r = serve(handler, http.MethodPost, "api/v1/auth/2fa", "", fmt.Sprintf(twoFactorJSON, challenge, totp("", time.Now())))
+	assert.Equal(t, http.StatusUnauthorized, r.Code)
+
+	// until then they are held to the enrollment
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"require_2fa": true}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+	api.PatchApiV1RolesId(httptest.NewRecorder(), req, 1)
+
+	token = login(handler, "Secret-1-key")
+	r = serve(handler, http.MethodGet, "api/v1/me/menus", token.AccessToken, "")
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, errcode.TwoFactorRequired, actual.Code)
+	r = serve(handler, http.MethodPost, "api/v1/me/2fa/enroll", token.AccessToken, "")
+	assert.Equal(t, http.StatusOK, r.Code)
+}
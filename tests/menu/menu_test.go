@@ -0,0 +1,351 @@
+package menu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+var menuJSON = `{
+"code": "user%d",
+"name": "user",
+"description": "user management",
+"sequence": 1,
+"type": "page",
+"path": "/user",
+"property": "",
+"parent_id": 0,
+"parent_path": "",
+"status": "enabled",
+"created": "2024-04-04 13:56:35.671521",
+"updated": "2024-04-05 13:56:35.671521",
+"resource": [
+    {
+        "menu_id": 0,
+        "method": "%s",
+        "path": "%s",
+        "created": "2024-04-04 13:56:35.671521",
+        "updated": "2024-04-05 13:56:35.671521"
+    }
+]
+}`
+
+func TestPostApiV1MenusResource(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	for i, test := range []struct {
+		method string
+		path   string
+		field  string
+		tag    string
+	}{
+		{"GET", "/api/v1/users/{id}", "", ""},
+		{"GET, DELETE", "/api/v1/users/*", "", ""},
+		{"ANY", "/api/v1/**", "", ""},
+		{"FETCH", "/api/v1/users", "method", "resource_method"},
+		{"GET", "api/v1/users", "path", "resource_path"},
+		{"GET", "/api/{rest...}/users", "path", "resource_path"},
+		{"GET", "/api/v1/nothing", "path", "resource_route"},
+		// registered, but not for the method
+		{"DELETE", "/api/v1/login-logs", "path", "resource_route"},
+	} {
+		reqJSON := fmt.Sprintf(menuJSON, i, test.method, test.path)
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/menus", strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		r := httptest.NewRecorder()
+		api.PostApiV1Menus(r, req)
+
+		if test.tag == "" {
+			assert.Equal(t, http.StatusOK, r.Code, test.path)
+			continue
+		}
+
+		var actual controller.Error
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, http.StatusBadRequest, r.Code, test.path)
+		assert.Equal(t, errcode.Validate, actual.Code)
+		assert.Contains(t, actual.Details, controller.ErrorDetail{Field: "resource[0]." + test.field, Tag: test.tag})
+	}
+}
+
+func TestPostApiV1ApiResourcesSync(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	reqJSON := fmt.Sprintf(menuJSON, 1, "GET", "/api/v1/users/{id}")
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/menus", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	api.PostApiV1Menus(httptest.NewRecorder(), req)
+
+	// stored before resources were validated
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	_, err := model.New(db).CreateResource(context.Background(), model.CreateResourceParams{
+		MenuID:  1,
+		Method:  "GET",
+		Path:    "/api/v1/nothing",
+		Created: now,
+		Updated: now,
+	})
+	assert.NoError(t, err)
+
+	sync := func() controller.ApiResourceSync {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/api-resources/sync", nil)
+		r := httptest.NewRecorder()
+		api.PostApiV1ApiResourcesSync(r, req)
+		assert.Equal(t, http.StatusOK, r.Code)
+
+		var actual controller.ApiResourceSync
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		return actual
+	}
+
+	actual := sync()
+	assert.Positive(t, actual.Added)
+	assert.Zero(t, actual.Removed)
+	assert.Len(t, actual.Ungranted, int(actual.Added)-1)
+	for _, apiResource := range actual.Ungranted {
+		assert.NotEqual(t, "GetApiV1UsersId", apiResource.OperationId)
+	}
+	assert.Len(t, actual.Stale, 1)
+	assert.Equal(t, "/api/v1/nothing", actual.Stale[0].Path)
+
+	// a second sync finds the catalog up to date
+	again := sync()
+	assert.Zero(t, again.Added)
+	assert.Zero(t, again.Removed)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/api-resources", nil)
+	r := httptest.NewRecorder()
+	api.GetApiV1ApiResources(r, req, controller.GetApiV1ApiResourcesParams{Tag: "sessions"})
+	var apiResourceList []controller.ApiResource
+	_ = json.NewDecoder(r.Body).Decode(&apiResourceList)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, apiResourceList, 2)
+	assert.Equal(t, "GetApiV1Sessions", apiResourceList[0].OperationId)
+	assert.Equal(t, "DeleteApiV1SessionsId", apiResourceList[1].OperationId)
+	assert.Equal(t, "/api/v1/sessions/{id}", apiResourceList[1].Path)
+}
+
+func TestPostApiV1MenusIdMove(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	// 1 > 2 > 3 and 4
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	for i, parentPath := range []string{"", "1.", "1.2.", ""} {
+		params := model.CreateMenuParams{
+			Code:       fmt.Sprintf("menu%d", i+1),
+			Sequence:   1,
+			Type:       "page",
+			ParentPath: parentPath,
+			Status:     "enabled",
+			Created:    now,
+			Updated:    now,
+		}
+		if parentPath != "" {
+			params.ParentID = pgtype.Int4{Int32: int32(i), Valid: true}
+		}
+		_, err := model.New(db).CreateMenu(context.Background(), params)
+		assert.NoError(t, err)
+	}
+
+	move := func(id, parentID int32) *httptest.ResponseRecorder {
+		reqJSON := fmt.Sprintf(`{"parent_id": %d}`, parentID)
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/menus/"+fmt.Sprint(id)+"/move", strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
+		r := httptest.NewRecorder()
+		api.PostApiV1MenusIdMove(r, req, id)
+		return r
+	}
+
+	// under its own grandchild
+	r := move(1, 3)
+	var actualErr controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actualErr)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.MenuCycle, actualErr.Code)
+
+	r = move(2, 4)
+	var actual controller.Menu
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, int32(4), actual.ParentId)
+	assert.Equal(t, "4.", actual.ParentPath)
+
+	// the subtree moves along
+	child, err := model.New(db).GetMenu(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "4.2.", child.ParentPath)
+
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/menus/tree", nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1MenusTree(r, req, controller.GetApiV1MenusTreeParams{})
+	var tree []controller.Menu
+	_ = json.NewDecoder(r.Body).Decode(&tree)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, tree, 2)
+	assert.Empty(t, tree[0].Children)
+	assert.Equal(t, int32(4), *tree[1].Id)
+	assert.Equal(t, int32(2), *tree[1].Children[0].Id)
+	assert.Equal(t, int32(3), *tree[1].Children[0].Children[0].Id)
+
+	reorder := func(reqJSON string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/menus:reorder", strings.NewReader(reqJSON))
+		req.Header.Set("Content-Type", "application/json")
+		r := httptest.NewRecorder()
+		api.PostApiV1MenusReorder(r, req)
+		return r
+	}
+
+	r = reorder(`{"parent_id": 0, "id": [4]}`)
+	_ = json.NewDecoder(r.Body).Decode(&actualErr)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.MenuNotSibling, actualErr.Code)
+
+	r = reorder(`{"parent_id": 0, "id": [4, 1]}`)
+	var siblingList []controller.Menu
+	_ = json.NewDecoder(r.Body).Decode(&siblingList)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, siblingList, 2)
+	assert.Equal(t, int32(4), *siblingList[0].Id)
+	assert.Equal(t, int16(1), siblingList[0].Sequence)
+	assert.Equal(t, int32(1), *siblingList[1].Id)
+	assert.Equal(t, int16(2), siblingList[1].Sequence)
+
+	// a menu without children
+	r = reorder(`{"parent_id": 3, "id": []}`)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.JSONEq(t, `[]`, r.Body.String())
+}
+
+func TestGetApiV1Menus(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	for i, status := range []string{"enabled", "disabled", "enabled"} {
+		params := model.CreateMenuParams{
+			Code:     fmt.Sprintf("menu%d", i+1),
+			Sequence: int16(i + 1),
+			Type:     "page",
+			Status:   status,
+			Created:  now,
+			Updated:  now,
+		}
+		_, err := model.New(db).CreateMenu(context.Background(), params)
+		assert.NoError(t, err)
+	}
+
+	// the status filter and paging of the list
+	params := controller.GetApiV1MenusParams{
+		Filter:   controller.Filter{"status:eq:enabled"},
+		PageSize: 1,
+		Total:    true,
+	}
+	var codeList []string
+	for {
+		req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/menus", nil)
+		r := httptest.NewRecorder()
+		api.GetApiV1Menus(r, req, params)
+
+		var actual controller.MenuPage
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, http.StatusOK, r.Code)
+		assert.Equal(t, int64(2), *actual.Total)
+		assert.Len(t, actual.Items, 1)
+		codeList = append(codeList, actual.Items[0].Code)
+
+		if actual.NextCursor == "" {
+			break
+		}
+		params.Cursor = actual.NextCursor
+	}
+	assert.Equal(t, []string{"menu1", "menu3"}, codeList)
+}
+
+func TestPutApiV1MenusIdParent(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	for i := range 2 {
+		params := model.CreateMenuParams{
+			Code:     fmt.Sprintf("user%d", i+1),
+			Sequence: 1,
+			Type:     "page",
+			Status:   "enabled",
+			Created:  now,
+			Updated:  now,
+		}
+		_, err := model.New(db).CreateMenu(context.Background(), params)
+		assert.NoError(t, err)
+	}
+
+	// a menu is moved with its subtree, not by a write of parent_id
+	reqJSON := strings.Replace(fmt.Sprintf(menuJSON, 2, http.MethodGet, "/api/v1/users"), `"parent_id": 0`, `"parent_id": 1`, 1)
+	req := httptest.NewRequest(http.MethodPut, tests.BaseURL, strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.PutApiV1MenusId(r, req, 2)
+
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.Validate, actual.Code)
+	assert.Equal(t, []controller.ErrorDetail{{Field: "parent_id", Tag: "move", Param: "/api/v1/menus/{id}/move"}}, actual.Details)
+
+	menu, err := model.New(db).GetMenu(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.False(t, menu.ParentID.Valid)
+}
+
+func TestPatchApiV1MenusIdParent(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+	for i := range 2 {
+		params := model.CreateMenuParams{
+			Code:     fmt.Sprintf("user%d", i+1),
+			Sequence: 1,
+			Type:     "page",
+			Status:   "enabled",
+			Created:  now,
+			Updated:  now,
+		}
+		_, err := model.New(db).CreateMenu(context.Background(), params)
+		assert.NoError(t, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(`{"name": "moved", "parent_id": 1}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.PatchApiV1MenusId(r, req, 2)
+
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.Validate, actual.Code)
+	assert.Equal(t, []controller.ErrorDetail{{Field: "parent_id", Tag: "move", Param: "/api/v1/menus/{id}/move"}}, actual.Details)
+
+	menu, err := model.New(db).GetMenu(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.False(t, menu.ParentID.Valid)
+	assert.Empty(t, menu.Name)
+}
@@ -0,0 +1,39 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linehk/go-admin/model"
+	"github.com/linehk/go-admin/tests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := tests.ContainerDB(t)
+
+	// applying again is a no-op
+	err := model.MigrateUp(ctx, db)
+	assert.NoError(t, err)
+
+	var count int
+	err = db.QueryRow(ctx, "SELECT count(*) FROM schema_migrations").Scan(&count)
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+
+	err = model.MigrateDown(ctx, db, count)
+	assert.NoError(t, err)
+
+	var exist bool
+	err = db.QueryRow(ctx, "SELECT to_regclass('app_user') IS NOT NULL").Scan(&exist)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	err = model.MigrateUp(ctx, db)
+	assert.NoError(t, err)
+
+	err = db.QueryRow(ctx, "SELECT to_regclass('app_user') IS NOT NULL").Scan(&exist)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+}
@@ -8,8 +8,8 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/model"
 	"github.com/linehk/go-admin/tests"
 	"github.com/stretchr/testify/assert"
 )
@@ -122,8 +122,9 @@ var (
 	}
 )
 
-func createRole(db *pgx.Conn, reqJSON string) controller.Role {
+func createRole(db model.DB, reqJSON string) controller.Role {
 	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/roles", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
 	r := httptest.NewRecorder()
 	api := &controller.API{DB: db}
 	api.PostApiV1Roles(r, req)
@@ -134,6 +135,7 @@ func createRole(db *pgx.Conn, reqJSON string) controller.Role {
 
 func TestPostApiV1Roles(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
 	actual := createRole(db, role1JSON)
 
 	assert.Equal(t, role1, actual)
@@ -141,6 +143,7 @@ func TestPostApiV1Roles(t *testing.T) {
 
 func TestGetApiV1RolesId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
 	_ = createRole(db, role1JSON)
 
 	var id int32 = 1
@@ -157,10 +160,12 @@ func TestGetApiV1RolesId(t *testing.T) {
 
 func TestDeleteApiV1RolesId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
 	_ = createRole(db, role1JSON)
 
 	var id int32 = 1
 	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+fmt.Sprintf("api/v1/roles/%d", id), nil)
+	req.Header.Set("If-Match", `"1"`)
 	r := httptest.NewRecorder()
 
 	api := &controller.API{DB: db}
@@ -171,10 +176,13 @@ func TestDeleteApiV1RolesId(t *testing.T) {
 
 func TestPutApiV1RolesId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
 	_ = createRole(db, role1JSON)
 
 	var id int32 = 1
 	req := httptest.NewRequest(http.MethodPut, tests.BaseURL+fmt.Sprintf("api/v1/roles/%d", id), strings.NewReader(role2JSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
 	r := httptest.NewRecorder()
 
 	api := &controller.API{DB: db}
@@ -191,27 +199,75 @@ func TestPutApiV1RolesId(t *testing.T) {
 	assert.Equal(t, role2, actual)
 }
 
+func TestPutApiV1RolesIdIfMatch(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
+	_ = createRole(db, role1JSON)
+	api := &controller.API{DB: db}
+
+	var id int32 = 1
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+fmt.Sprintf("api/v1/roles/%d", id), nil)
+	r := httptest.NewRecorder()
+	api.GetApiV1RolesId(r, req, id)
+
+	etag := r.Header().Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+fmt.Sprintf("api/v1/roles/%d", id), nil)
+	req.Header.Set("If-None-Match", etag)
+	r = httptest.NewRecorder()
+	api.GetApiV1RolesId(r, req, id)
+
+	assert.Equal(t, http.StatusNotModified, r.Code)
+	assert.Empty(t, r.Body.String())
+
+	for _, c := range []struct {
+		ifMatch string
+		code    int
+	}{
+		{"", http.StatusPreconditionRequired},
+		{`"2"`, http.StatusPreconditionFailed},
+		{etag, http.StatusOK},
+		// the first write moved the version on
+		{etag, http.StatusPreconditionFailed},
+	} {
+		req = httptest.NewRequest(http.MethodPut, tests.BaseURL+fmt.Sprintf("api/v1/roles/%d", id), strings.NewReader(role2JSON))
+		req.Header.Set("Content-Type", "application/json")
+		if c.ifMatch != "" {
+			req.Header.Set("If-Match", c.ifMatch)
+		}
+		r = httptest.NewRecorder()
+		api.PutApiV1RolesId(r, req, id)
+
+		assert.Equal(t, c.code, r.Code, c.ifMatch)
+		if c.code == http.StatusOK {
+			assert.Equal(t, `"2"`, r.Header().Get("ETag"))
+		}
+	}
+}
+
 func TestGetApiV1Roles(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedMenu(t, db, 4)
 	_ = createRole(db, role1JSON)
 	_ = createRole(db, role2JSON)
 
 	api := &controller.API{DB: db}
 	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/roles", nil)
 	params := controller.GetApiV1RolesParams{
-		Name:     "",
-		Status:   string(controller.RoleStatusEnabled),
-		Current:  0,
+		Filter:   controller.Filter{"status:eq:enabled"},
 		PageSize: 10,
 	}
 	r := httptest.NewRecorder()
 	api.GetApiV1Roles(r, req, params)
 
-	var actual []controller.Role
+	var actual controller.RolePage
 	_ = json.NewDecoder(r.Body).Decode(&actual)
-	expected := []controller.Role{
-		role1,
-		role2,
+	expected := controller.RolePage{
+		Items: []controller.Role{
+			role1,
+			role2,
+		},
 	}
 
 	assert.Equal(t, expected, actual)
@@ -2,11 +2,10 @@ package tests
 
 import (
 	"context"
-	"path/filepath"
 	"testing"
 	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/linehk/go-admin/model"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -15,11 +14,10 @@ import (
 
 const BaseURL = "http://localhost:8080/"
 
-func ContainerDB(t *testing.T) *pgx.Conn {
+func ContainerDB(t *testing.T) *pgxpool.Pool {
 	ctx := context.Background()
 	pg, err := postgres.RunContainer(ctx,
 		testcontainers.WithImage("postgres:16.2"),
-		postgres.WithInitScripts(filepath.Join("..", "..", "model", "schema.sql")),
 		postgres.WithDatabase("go_admin"),
 		postgres.WithUsername("dev"),
 		postgres.WithPassword("dev"),
@@ -33,5 +31,29 @@ func ContainerDB(t *testing.T) *pgx.Conn {
 	if err != nil {
 		t.Error(err)
 	}
-	return model.Setup(ctx, dsn)
+	pool := model.Setup(ctx, dsn)
+	err = model.MigrateUp(ctx, pool)
+	if err != nil {
+		t.Error(err)
+	}
+	return pool
+}
+
+// SeedRole inserts n enabled roles with ids 1 to n, for tests referencing roles.
+func SeedRole(t *testing.T, db model.DB, n int) {
+	_, err := db.Exec(context.Background(), `INSERT INTO role (code, name, description, sequence, status, created, updated)
+SELECT 'seed' || i, 'seed' || i, '', i, 'enabled', now(), now() FROM generate_series(1, $1) AS i`, n)
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// SeedMenu inserts n enabled top level menus with ids 1 to n, for tests referencing menus.
+func SeedMenu(t *testing.T, db model.DB, n int) {
+	_, err := db.Exec(context.Background(), `INSERT INTO menu (code, name, description, sequence, type, path, property,
+parent_id, parent_path, status, created, updated)
+SELECT 'seed' || i, 'seed' || i, '', i, 'page', '', '', NULL, '', 'enabled', now(), now() FROM generate_series(1, $1) AS i`, n)
+	if err != nil {
+		t.Error(err)
+	}
 }
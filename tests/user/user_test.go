@@ -1,23 +1,27 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/linehk/go-admin/controller"
+	"github.com/linehk/go-admin/errcode"
+	"github.com/linehk/go-admin/model"
 	"github.com/linehk/go-admin/tests"
 	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
 )
 
 var (
 	user1JSON = `{
 "username": "username1",
-"password": "password1",
+"password": "Secret-1-key",
 "name": "name1",
 "email": "example1@gmail.com",
 "phone": "+14155552671",
@@ -74,7 +78,7 @@ var (
 var (
 	user2JSON = `{
 "username": "username2",
-"password": "password2",
+"password": "Secret-2-key",
 "name": "name2",
 "email": "example2@gmail.com",
 "phone": "+442071838750",
@@ -128,8 +132,9 @@ var (
 	}
 )
 
-func createUser(db *pgx.Conn, reqJSON string) controller.User {
+func createUser(db model.DB, reqJSON string) controller.User {
 	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
 	r := httptest.NewRecorder()
 	api := &controller.API{DB: db}
 	api.PostApiV1Users(r, req)
@@ -140,6 +145,7 @@ func createUser(db *pgx.Conn, reqJSON string) controller.User {
 
 func TestPostApiV1Users(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
 	actual := createUser(db, user1JSON)
 
 	assert.Equal(t, user1, actual)
@@ -147,6 +153,7 @@ func TestPostApiV1Users(t *testing.T) {
 
 func TestGetApiV1UsersId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
 	_ = createUser(db, user1JSON)
 
 	var id int32 = 1
@@ -163,10 +170,12 @@ func TestGetApiV1UsersId(t *testing.T) {
 
 func TestDeleteApiV1UsersId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
 	_ = createUser(db, user1JSON)
 
 	var id int32 = 1
 	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+fmt.Sprintf("api/v1/users/%d", id), nil)
+	req.Header.Set("If-Match", `"1"`)
 	r := httptest.NewRecorder()
 
 	api := &controller.API{DB: db}
@@ -175,12 +184,82 @@ func TestDeleteApiV1UsersId(t *testing.T) {
 	assert.Equal(t, http.StatusOK, r.Code)
 }
 
+func TestPostApiV1UsersIdRestore(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+
+	var id int32 = 1
+	api := &controller.API{DB: db}
+	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+fmt.Sprintf("api/v1/users/%d", id), nil)
+	req.Header.Set("If-Match", `"1"`)
+	api.DeleteApiV1UsersId(httptest.NewRecorder(), req, id)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+fmt.Sprintf("api/v1/users/%d", id), nil)
+	r := httptest.NewRecorder()
+	api.GetApiV1UsersId(r, req, id)
+	assert.Equal(t, http.StatusNotFound, r.Code)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users", nil)
+	params := controller.GetApiV1UsersParams{
+		PageSize:       10,
+		IncludeDeleted: true,
+	}
+	r = httptest.NewRecorder()
+	api.GetApiV1Users(r, req, params)
+	var deletedPage controller.UserPage
+	_ = json.NewDecoder(r.Body).Decode(&deletedPage)
+	assert.Len(t, deletedPage.Items, 1)
+	assert.NotEmpty(t, deletedPage.Items[0].DeletedAt)
+
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL+fmt.Sprintf("api/v1/users/%d/restore", id), nil)
+	r = httptest.NewRecorder()
+	api.PostApiV1UsersIdRestore(r, req, id)
+
+	var actual controller.User
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, user1.Username, actual.Username)
+	assert.Empty(t, actual.DeletedAt)
+	// the roles are restored with the user
+	assert.Equal(t, user1.Role, actual.Role)
+}
+
+func TestPurge(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+
+	var id int32 = 1
+	api := &controller.API{DB: db}
+	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+fmt.Sprintf("api/v1/users/%d", id), nil)
+	req.Header.Set("If-Match", `"1"`)
+	api.DeleteApiV1UsersId(httptest.NewRecorder(), req, id)
+
+	// nothing was deleted an hour ago
+	rows, err := api.Purge(context.Background(), time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), rows)
+
+	rows, err = api.Purge(context.Background(), time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1+len(user1.Role)), rows)
+
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL+fmt.Sprintf("api/v1/users/%d/restore", id), nil)
+	r := httptest.NewRecorder()
+	api.PostApiV1UsersIdRestore(r, req, id)
+	assert.Equal(t, http.StatusNotFound, r.Code)
+}
+
 func TestPutApiV1UsersId(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
 	_ = createUser(db, user1JSON)
 
 	var id int32 = 1
 	req := httptest.NewRequest(http.MethodPut, tests.BaseURL+fmt.Sprintf("api/v1/users/%d", id), strings.NewReader(user2JSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
 	r := httptest.NewRecorder()
 
 	api := &controller.API{DB: db}
@@ -197,29 +276,429 @@ func TestPutApiV1UsersId(t *testing.T) {
 	assert.Equal(t, user2, actual)
 }
 
+func TestPatchApiV1UsersId(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+	api := &controller.API{DB: db}
+	query := model.New(db)
+
+	userByCreate, err := query.GetUser(context.Background(), id1)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPatch, tests.BaseURL+"api/v1/users/1", strings.NewReader(`{"status": "frozen", "remark": null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+
+	var actual controller.User
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, controller.Frozen, actual.Status)
+	assert.Equal(t, "", actual.Remark)
+	assert.Equal(t, user1.Name, actual.Name)
+	assert.Equal(t, user1.Created, actual.Created)
+	assert.Equal(t, user1.Role, actual.Role)
+
+	// the password is left alone when the patch has none
+	userByPatch, err := query.GetUser(context.Background(), id1)
+	assert.NoError(t, err)
+	assert.Equal(t, userByCreate.Password, userByPatch.Password)
+
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL+"api/v1/users/1", strings.NewReader(`{"password": "Secret-3-key", "role": [{"role_id": 3}]}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"2"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+
+	actual = controller.User{}
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Len(t, actual.Role, 1)
+	assert.Equal(t, int32(3), actual.Role[0].RoleId)
+
+	userByPatch, err = query.GetUser(context.Background(), id1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, userByCreate.Password, userByPatch.Password)
+
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL+"api/v1/users/1", strings.NewReader(`{"status": "unknown"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+
+	assert.Equal(t, errcode.Status(errcode.Validate), r.Code)
+}
+
+func batchUsers(api *controller.API, reqJSON string) controller.BatchResponse {
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users:batch", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api.PostApiV1UsersBatch(r, req)
+	var actual controller.BatchResponse
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	return actual
+}
+
+func TestPostApiV1UsersBatch(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	api := &controller.API{DB: db}
+
+	// the creates go in at once
+	actual := batchUsers(api, fmt.Sprintf(`{"operations": [
+{"op": "create", "body": %s},
+{"op": "create", "body": %s}
+]}`, user1JSON, user2JSON))
+
+	assert.True(t, actual.Committed)
+	assert.Equal(t, []controller.BatchResult{
+		{Status: http.StatusOK, Id: 1, Etag: `"1"`},
+		{Status: http.StatusOK, Id: 2, Etag: `"1"`},
+	}, actual.Results)
+
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users/1", nil)
+	r := httptest.NewRecorder()
+	api.GetApiV1UsersId(r, req, id1)
+	var user controller.User
+	_ = json.NewDecoder(r.Body).Decode(&user)
+	assert.Equal(t, user1, user)
+
+	// a failed operation is skipped in best effort mode
+	actual = batchUsers(api, fmt.Sprintf(`{"mode": "best_effort", "operations": [
+{"op": "create", "body": %s},
+{"op": "patch", "id": 1, "if_match": "\"1\"", "body": {"status": "frozen"}},
+{"op": "delete", "id": 2, "if_match": "\"9\""}
+]}`, user1JSON))
+
+	assert.True(t, actual.Committed)
+	assert.Equal(t, errcode.UsernameOccupy, actual.Results[0].Code)
+	assert.Equal(t, int32(0), actual.Results[1].Code)
+	assert.Equal(t, `"2"`, actual.Results[1].Etag)
+	assert.Equal(t, errcode.PreconditionFailed, actual.Results[2].Code)
+
+	// and rolls everything back in atomic mode
+	actual = batchUsers(api, `{"operations": [
+{"op": "delete", "id": 2, "if_match": "\"1\""},
+{"op": "delete", "id": 3, "if_match": "\"1\""}
+]}`)
+
+	assert.False(t, actual.Committed)
+	assert.Equal(t, errcode.BatchAborted, actual.Results[0].Code)
+	assert.Equal(t, errcode.UserNotExist, actual.Results[1].Code)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users/2", nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1UsersId(r, req, id2)
+	assert.Equal(t, http.StatusOK, r.Code)
+}
+
+func importUsers(api *controller.API, csv string, dryRun bool) controller.ImportResult {
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users/import", strings.NewReader(csv))
+	req.Header.Set("Content-Type", "text/csv")
+	r := httptest.NewRecorder()
+	api.PostApiV1UsersImport(r, req, controller.PostApiV1UsersImportParams{DryRun: dryRun})
+	var actual controller.ImportResult
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	return actual
+}
+
+func TestPostApiV1UsersImport(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 2)
+	api := &controller.API{DB: db}
+
+	csv := `username,password,name,email,phone,role
+username1,Secret-1-key,name1,example1@gmail.com,+14155552671,seed1;seed2
+username2,Secret-2-key,name2,example2@gmail.com,+14155552672,seed2
+`
+
+	// a dry run checks every row and writes nothing
+	actual := importUsers(api, csv, true)
+	assert.Equal(t, controller.ImportResult{Committed: false, Created: 2}, actual)
+
+	// a bad row fails the import and is reported by its line
+	actual = importUsers(api, csv+"username3,Secret-3-key,name3,example3,+14155552673,seed9\n", false)
+	assert.False(t, actual.Committed)
+	assert.Len(t, actual.Errors, 1)
+	assert.Equal(t, int32(4), actual.Errors[0].Row)
+	assert.Equal(t, errcode.Validate, actual.Errors[0].Code)
+
+	actual = importUsers(api, csv, false)
+	assert.Equal(t, controller.ImportResult{Committed: true, Created: 2}, actual)
+
+	// existing users are updated by username
+	actual = importUsers(api, "username,name\nusername1,name9\n", false)
+	assert.Equal(t, controller.ImportResult{Committed: true, Updated: 1}, actual)
+
+	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users/export", nil)
+	r := httptest.NewRecorder()
+	api.GetApiV1UsersExport(r, req, controller.GetApiV1UsersExportParams{})
+	assert.Equal(t, http.StatusOK, r.Code)
+	assert.Equal(t, "text/csv", r.Header().Get("Content-Type"))
+	assert.Contains(t, r.Body.String(), "username1,name9,example1@gmail.com")
+	// a spreadsheet does not run the phone number as a formula
+	assert.Contains(t, r.Body.String(), "'+14155552671")
+	assert.Contains(t, r.Body.String(), "seed1;seed2")
+
+	// XLSX cells are strings already, they are written as they are
+	r = httptest.NewRecorder()
+	api.GetApiV1UsersExport(r, req, controller.GetApiV1UsersExportParams{Format: controller.Xlsx})
+	assert.Equal(t, http.StatusOK, r.Code)
+	f, err := excelize.OpenReader(r.Body)
+	assert.NoError(t, err)
+	rowList, err := f.GetRows(f.GetSheetName(0))
+	assert.NoError(t, err)
+	var phoneList []string
+	for _, row := range rowList[1:] {
+		phoneList = append(phoneList, row[4])
+	}
+	assert.ElementsMatch(t, []string{"+14155552671", "+14155552672"}, phoneList)
+}
+
 func TestGetApiV1Users(t *testing.T) {
 	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
 	_ = createUser(db, user1JSON)
 	_ = createUser(db, user2JSON)
 
 	api := &controller.API{DB: db}
 	req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users", nil)
 	params := controller.GetApiV1UsersParams{
-		Username: "",
-		Name:     "",
-		Status:   string(controller.Activated),
-		Current:  0,
+		Filter:   controller.Filter{"status:eq:activated"},
 		PageSize: 10,
 	}
 	r := httptest.NewRecorder()
 	api.GetApiV1Users(r, req, params)
 
-	var actual []controller.User
+	var actual controller.UserPage
 	_ = json.NewDecoder(r.Body).Decode(&actual)
-	expected := []controller.User{
-		user1,
-		user2,
+	expected := controller.UserPage{
+		Items: []controller.User{
+			user1,
+			user2,
+		},
 	}
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestGetApiV1UsersCursor(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+	_ = createUser(db, user2JSON)
+
+	api := &controller.API{DB: db}
+	params := controller.GetApiV1UsersParams{
+		PageSize: 1,
+		Total:    true,
+	}
+
+	var usernameList []string
+	for {
+		req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users", nil)
+		r := httptest.NewRecorder()
+		api.GetApiV1Users(r, req, params)
+
+		var actual controller.UserPage
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, http.StatusOK, r.Code)
+		assert.Equal(t, int64(2), *actual.Total)
+		assert.Len(t, actual.Items, 1)
+		usernameList = append(usernameList, actual.Items[0].Username)
+
+		if actual.NextCursor == "" {
+			break
+		}
+		params.Cursor = actual.NextCursor
+	}
+
+	assert.Equal(t, []string{user1.Username, user2.Username}, usernameList)
+}
+
+func TestGetApiV1UsersFilterSort(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+	_ = createUser(db, user2JSON)
+
+	api := &controller.API{DB: db}
+	list := func(filter controller.Filter, sort controller.Sort) (*httptest.ResponseRecorder, []string) {
+		req := httptest.NewRequest(http.MethodGet, tests.BaseURL+"api/v1/users", nil)
+		params := controller.GetApiV1UsersParams{Filter: filter, Sort: sort, PageSize: 10}
+		r := httptest.NewRecorder()
+		api.GetApiV1Users(r, req, params)
+
+		var actual controller.UserPage
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		var usernameList []string
+		for _, user := range actual.Items {
+			usernameList = append(usernameList, user.Username)
+		}
+		return r, usernameList
+	}
+
+	_, actual := list(nil, "username")
+	assert.Equal(t, []string{user1.Username, user2.Username}, actual)
+
+	_, actual = list(nil, "-username")
+	assert.Equal(t, []string{user2.Username, user1.Username}, actual)
+
+	_, actual = list(controller.Filter{"username:in:" + user2.Username + ",nobody"}, "")
+	assert.Equal(t, []string{user2.Username}, actual)
+
+	_, actual = list(controller.Filter{"username:like:NAME1", "created:gte:2024-01-01 00:00:00"}, "")
+	assert.Equal(t, []string{user1.Username}, actual)
+
+	// not whitelisted, wrong operator for the type, bad value
+	for _, filter := range []string{"password:eq:x", "created:like:2024", "created:gte:yesterday"} {
+		r, _ := list(controller.Filter{filter}, "")
+		var actual controller.Error
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, http.StatusBadRequest, r.Code)
+		assert.Equal(t, errcode.FilterInvalid, actual.Code)
+	}
+
+	r, _ := list(nil, "password")
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+}
+
+func TestPostApiV1UsersUsernameOccupy(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(user1JSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api := &controller.API{DB: db}
+	api.PostApiV1Users(r, req)
+
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusConflict, r.Code)
+	assert.Equal(t, errcode.UsernameOccupy, actual.Code)
+}
+
+func TestPostApiV1UsersValidate(t *testing.T) {
+	db := tests.ContainerDB(t)
+
+	reqJSON := strings.Replace(user1JSON, "example1@gmail.com", "example1", 1)
+	req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(reqJSON))
+	req.Header.Set("Content-Type", "application/json")
+	r := httptest.NewRecorder()
+	api := &controller.API{DB: db}
+	controller.RequestID(http.HandlerFunc(api.PostApiV1Users)).ServeHTTP(r, req)
+
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusBadRequest, r.Code)
+	assert.Equal(t, errcode.Validate, actual.Code)
+	assert.Equal(t, r.Header().Get("X-Request-ID"), actual.RequestId)
+	assert.Equal(t, []controller.ErrorDetail{{Field: "email", Tag: "email", Param: ""}}, actual.Details)
+}
+
+func TestPostApiV1UsersBind(t *testing.T) {
+	db := tests.ContainerDB(t)
+	api := &controller.API{DB: db}
+
+	// a bad body must stop the handler before it touches the database
+	cases := []struct {
+		contentType string
+		body        string
+		status      int
+		code        int32
+	}{
+		{"text/plain", user1JSON, http.StatusUnsupportedMediaType, errcode.UnsupportedMediaType},
+		{"application/json", `{"username": `, http.StatusBadRequest, errcode.Parse},
+		{"application/json", user1JSON + user1JSON, http.StatusBadRequest, errcode.Parse},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/users", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		r := httptest.NewRecorder()
+		api.PostApiV1Users(r, req)
+
+		var actual controller.Error
+		_ = json.NewDecoder(r.Body).Decode(&actual)
+		assert.Equal(t, c.status, r.Code)
+		assert.Equal(t, c.code, actual.Code)
+	}
+}
+
+func TestRootUserProtected(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+	api := &controller.API{DB: db}
+	err := model.New(db).MarkRootUser(context.Background(), id1)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+"api/v1/users/1", nil)
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.DeleteApiV1UsersId(r, req, id1)
+	var actual controller.Error
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+	assert.Equal(t, errcode.RootUserProtected, actual.Code)
+
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL+"api/v1/users/1", strings.NewReader(`{"username": "username9"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	assert.Equal(t, http.StatusForbidden, r.Code)
+	assert.Equal(t, errcode.RootUserProtected, actual.Code)
+
+	// other fields of root can change
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL+"api/v1/users/1", strings.NewReader(`{"remark": "root"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+	assert.Equal(t, http.StatusOK, r.Code)
+}
+
+func TestPatchApiV1UsersIdKeepDeletedRole(t *testing.T) {
+	db := tests.ContainerDB(t)
+	tests.SeedRole(t, db, 4)
+	_ = createUser(db, user1JSON)
+
+	api := &controller.API{DB: db}
+	req := httptest.NewRequest(http.MethodDelete, tests.BaseURL+"api/v1/roles/2", nil)
+	req.Header.Set("If-Match", `"1"`)
+	r := httptest.NewRecorder()
+	api.DeleteApiV1RolesId(r, req, 2)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	// replacing the roles leaves the link to the deleted role alone
+	patchJSON := `{"role": [{"role_id": 3, "created": "2024-04-04 13:56:35.671521", "updated": "2024-04-05 13:56:35.671521"}]}`
+	req = httptest.NewRequest(http.MethodPatch, tests.BaseURL, strings.NewReader(patchJSON))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"1"`)
+	r = httptest.NewRecorder()
+	api.PatchApiV1UsersId(r, req, id1)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	req = httptest.NewRequest(http.MethodPost, tests.BaseURL+"api/v1/roles/2/restore", nil)
+	r = httptest.NewRecorder()
+	api.PostApiV1RolesIdRestore(r, req, 2)
+	assert.Equal(t, http.StatusOK, r.Code)
+
+	req = httptest.NewRequest(http.MethodGet, tests.BaseURL, nil)
+	r = httptest.NewRecorder()
+	api.GetApiV1UsersId(r, req, id1)
+	var actual controller.User
+	_ = json.NewDecoder(r.Body).Decode(&actual)
+	var roleIDList []int32
+	for _, role := range actual.Role {
+		roleIDList = append(roleIDList, role.RoleId)
+	}
+	assert.ElementsMatch(t, []int32{2, 3}, roleIDList)
+}